@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/schollz/progressbar/v3"
+	"github.com/tendermint/tendermint/libs/log"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+
+	"github.com/kava-labs/go-tools/auction-audit/types"
+	auctiontypes "github.com/kava-labs/kava/x/auction/types"
+)
+
+// BidEvent is a single successful bid from the auction_bid event of a tx
+type BidEvent struct {
+	AuctionID uint64
+	Height    int64
+	Bidder    string
+	// Amount is the bid for forward bids and the lot for reverse bids
+	Amount  sdk.Coin
+	Reverse bool
+	// EndTime is the end time of the auction after the bid
+	EndTime time.Time
+
+	// Time is the block time of the bid, set by GetBidEventData
+	Time time.Time
+	// LotDenom is the denom of the auction lot, set by GetBidEventData
+	LotDenom string
+	// Margin is the margin of the bid at pricefeed prices at the height of
+	// the bid, set by GetBidEventData if the lot and bid could be valued
+	Margin *sdk.Dec
+}
+
+// ParseAuctionBidEvents returns the bids in successful txs up to the end
+// height, oldest first
+func ParseAuctionBidEvents(txs []*coretypes.ResultTx, end int64) ([]BidEvent, error) {
+	var bids []BidEvent
+
+	for _, txRes := range txs {
+		// Query may include blocks after end height
+		if txRes.Height > end {
+			break
+		}
+
+		// Skip failed txs
+		if txRes.TxResult.Code != 0 {
+			continue
+		}
+
+		// Events are not flattened, a tx with several bids has an event for each
+		for _, event := range txRes.TxResult.Events {
+			if event.Type != auctiontypes.EventTypeAuctionBid {
+				continue
+			}
+
+			bid, err := parseBidEvent(sdk.StringifyEvent(event))
+			if err != nil {
+				return nil, fmt.Errorf("invalid bid event at height %d: %w", txRes.Height, err)
+			}
+			bid.Height = txRes.Height
+
+			bids = append(bids, bid)
+		}
+	}
+
+	return bids, nil
+}
+
+func parseBidEvent(event sdk.StringEvent) (BidEvent, error) {
+	var bid BidEvent
+
+	for _, attr := range event.Attributes {
+		var err error
+
+		switch attr.Key {
+		case auctiontypes.AttributeKeyAuctionID:
+			bid.AuctionID, err = strconv.ParseUint(attr.Value, 10, 64)
+		case auctiontypes.AttributeKeyBidder:
+			bid.Bidder = attr.Value
+		case auctiontypes.AttributeKeyBid:
+			bid.Amount, err = sdk.ParseCoinNormalized(attr.Value)
+		case auctiontypes.AttributeKeyLot:
+			bid.Amount, err = sdk.ParseCoinNormalized(attr.Value)
+			bid.Reverse = true
+		case auctiontypes.AttributeKeyEndTime:
+			var unix int64
+			unix, err = strconv.ParseInt(attr.Value, 10, 64)
+			bid.EndTime = time.Unix(unix, 0).UTC()
+		}
+
+		if err != nil {
+			return BidEvent{}, fmt.Errorf("invalid %s attribute: %w", attr.Key, err)
+		}
+	}
+
+	if bid.Bidder == "" || bid.Amount.Denom == "" {
+		return BidEvent{}, fmt.Errorf("missing bidder or amount")
+	}
+
+	return bid, nil
+}
+
+// GetBidEventData sets the block time, lot denom and margin of each bid.
+//
+// The margin compares the lot and bid after the bid at spot prices at the bid
+// height. Bids with assets that cannot be valued are logged and left without a
+// margin.
+func GetBidEventData(
+	logger log.Logger,
+	client GrpcClient,
+	bids []BidEvent,
+) error {
+	blockTimes := make(map[int64]time.Time)
+
+	bar := progressbar.Default(int64(len(bids)))
+	for i := range bids {
+		bid := &bids[i]
+
+		bar.Add(1)
+		bar.Describe(fmt.Sprintf("Valuing bid on auction %d", bid.AuctionID))
+
+		blockTime, found := blockTimes[bid.Height]
+		if !found {
+			res, err := client.Tm.GetBlockByHeight(
+				context.Background(),
+				&tmservice.GetBlockByHeightRequest{Height: bid.Height},
+			)
+			if err != nil {
+				return fmt.Errorf("failed to fetch block %d: %w", bid.Height, err)
+			}
+
+			blockTime = res.Block.Header.Time
+			blockTimes[bid.Height] = blockTime
+		}
+		bid.Time = blockTime
+
+		res, err := client.Auction.Auction(ctxAtHeight(bid.Height), &auctiontypes.QueryAuctionRequest{
+			AuctionId: bid.AuctionID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to fetch auction %d at height %d: %w", bid.AuctionID, bid.Height, err)
+		}
+
+		var auction auctiontypes.Auction
+		if err := client.cdc.UnpackAny(res.Auction, &auction); err != nil {
+			return fmt.Errorf("failed to unpack auction %d: %w", bid.AuctionID, err)
+		}
+
+		// later bids in the same block may have changed the side that was bid,
+		// so it is taken from the event
+		lot, paid := auction.GetLot(), bid.Amount
+		if bid.Reverse {
+			lot, paid = bid.Amount, auction.GetBid()
+		}
+		bid.LotDenom = lot.Denom
+
+		lotUsdValue, err := GetTotalCoinsUsdValueAtHeight(client, bid.Height, sdk.NewCoins(lot), types.PriceType_Spot)
+		if err != nil {
+			logger.Error("Failed to value lot, skipping margin", "auction ID", bid.AuctionID, "height", bid.Height, "err", err)
+			continue
+		}
+
+		paidUsdValue, err := GetTotalCoinsUsdValueAtHeight(client, bid.Height, sdk.NewCoins(paid), types.PriceType_Spot)
+		if err != nil {
+			logger.Error("Failed to value bid, skipping margin", "auction ID", bid.AuctionID, "height", bid.Height, "err", err)
+			continue
+		}
+
+		if lotUsdValue.IsPositive() {
+			margin := sdk.OneDec().Sub(paidUsdValue.Quo(lotUsdValue))
+			bid.Margin = &margin
+		}
+	}
+	bar.Finish()
+
+	return nil
+}
+
+// GetBidderStats summarizes bids by bidder, ordered by number of bids.
+//
+// An auction is closed if the end time after its last bid is not after the
+// end time of the range, and won by its last bidder. Reactions are counted
+// for bids that directly follow a bid by ourBidder on the same auction, they
+// are not counted if ourBidder is empty.
+func GetBidderStats(bids []BidEvent, ourBidder string, endTime time.Time) types.BidderStatsList {
+	type bidderData struct {
+		stats     types.BidderStats
+		auctions  map[uint64]bool
+		margins   []sdk.Dec
+		reactions []float64
+		assets    map[string]int
+	}
+
+	bidders := make(map[string]*bidderData)
+	lastBids := make(map[uint64]BidEvent)
+
+	for _, bid := range bids {
+		data, found := bidders[bid.Bidder]
+		if !found {
+			data = &bidderData{
+				stats:    types.BidderStats{Bidder: bid.Bidder},
+				auctions: make(map[uint64]bool),
+				assets:   make(map[string]int),
+			}
+			bidders[bid.Bidder] = data
+		}
+
+		data.stats.Bids++
+		data.auctions[bid.AuctionID] = true
+		if bid.Margin != nil {
+			data.margins = append(data.margins, *bid.Margin)
+		}
+		if bid.LotDenom != "" {
+			data.assets[bid.LotDenom]++
+		}
+
+		previous, found := lastBids[bid.AuctionID]
+		if found && ourBidder != "" && previous.Bidder == ourBidder && bid.Bidder != ourBidder {
+			data.reactions = append(data.reactions, bid.Time.Sub(previous.Time).Seconds())
+		}
+		lastBids[bid.AuctionID] = bid
+	}
+
+	var list types.BidderStatsList
+	for _, data := range bidders {
+		stats := data.stats
+		stats.AuctionsBid = len(data.auctions)
+
+		for auctionID := range data.auctions {
+			last := lastBids[auctionID]
+			if last.EndTime.After(endTime) {
+				continue
+			}
+
+			stats.AuctionsClosed++
+			if last.Bidder == stats.Bidder {
+				stats.AuctionsWon++
+			}
+		}
+
+		if stats.AuctionsClosed > 0 {
+			winRate := sdk.NewDec(int64(stats.AuctionsWon)).QuoInt64(int64(stats.AuctionsClosed))
+			stats.WinRate = &winRate
+		}
+
+		if len(data.margins) > 0 {
+			margin := medianDec(data.margins)
+			stats.MedianMargin = &margin
+		}
+
+		stats.Reactions = len(data.reactions)
+		if len(data.reactions) > 0 {
+			reaction := medianFloat(data.reactions)
+			stats.MedianReactionSeconds = &reaction
+		}
+
+		stats.PreferredAssets = []types.AssetBids{}
+		for denom, count := range data.assets {
+			stats.PreferredAssets = append(stats.PreferredAssets, types.AssetBids{Denom: denom, Bids: count})
+		}
+		types.SortPreferredAssets(stats.PreferredAssets)
+
+		list = append(list, stats)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Bids != list[j].Bids {
+			return list[i].Bids > list[j].Bids
+		}
+		return list[i].Bidder < list[j].Bidder
+	})
+
+	return list
+}
+
+func medianDec(values []sdk.Dec) sdk.Dec {
+	sorted := append([]sdk.Dec(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].LT(sorted[j])
+	})
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+
+	return sorted[mid-1].Add(sorted[mid]).QuoInt64(2)
+}
+
+func medianFloat(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+
+	return (sorted[mid-1] + sorted[mid]) / 2
+}
@@ -0,0 +1,137 @@
+package main_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	main "github.com/kava-labs/go-tools/auction-audit"
+	"github.com/kava-labs/go-tools/auction-audit/types"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+func bidEvent(auctionID uint64, bidder, amountKey, amount string, endTime int64) abci.Event {
+	return abci.Event{
+		Type: "auction_bid",
+		Attributes: []abci.EventAttribute{
+			{Key: []byte("auction_id"), Value: []byte(fmt.Sprintf("%d", auctionID))},
+			{Key: []byte("bidder"), Value: []byte(bidder)},
+			{Key: []byte(amountKey), Value: []byte(amount)},
+			{Key: []byte("end_time"), Value: []byte(fmt.Sprintf("%d", endTime))},
+		},
+	}
+}
+
+func TestParseAuctionBidEvents(t *testing.T) {
+	txs := []*coretypes.ResultTx{
+		{
+			Height: 100,
+			TxResult: abci.ResponseDeliverTx{
+				Events: []abci.Event{
+					{Type: "message"},
+					bidEvent(1, "kava1a", "bid", "1000usdx", 1000),
+					bidEvent(2, "kava1a", "lot", "50bnb", 2000),
+				},
+			},
+		},
+		{
+			// failed txs are skipped
+			Height:   101,
+			TxResult: abci.ResponseDeliverTx{Code: 5, Events: []abci.Event{bidEvent(1, "kava1b", "bid", "1100usdx", 1000)}},
+		},
+		{
+			// after the end height
+			Height:   201,
+			TxResult: abci.ResponseDeliverTx{Events: []abci.Event{bidEvent(1, "kava1b", "bid", "1200usdx", 1000)}},
+		},
+	}
+
+	bids, err := main.ParseAuctionBidEvents(txs, 200)
+	require.NoError(t, err)
+	require.Equal(t, []main.BidEvent{
+		{
+			AuctionID: 1,
+			Height:    100,
+			Bidder:    "kava1a",
+			Amount:    sdk.NewInt64Coin("usdx", 1000),
+			EndTime:   time.Unix(1000, 0).UTC(),
+		},
+		{
+			AuctionID: 2,
+			Height:    100,
+			Bidder:    "kava1a",
+			Amount:    sdk.NewInt64Coin("bnb", 50),
+			Reverse:   true,
+			EndTime:   time.Unix(2000, 0).UTC(),
+		},
+	}, bids)
+
+	_, err = main.ParseAuctionBidEvents([]*coretypes.ResultTx{{
+		Height:   100,
+		TxResult: abci.ResponseDeliverTx{Events: []abci.Event{bidEvent(1, "kava1a", "bid", "invalid", 1000)}},
+	}}, 200)
+	require.ErrorContains(t, err, "invalid bid attribute")
+}
+
+func TestGetBidderStats(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	dec := sdk.MustNewDecFromStr
+
+	bid := func(auctionID uint64, bidder string, at time.Duration, denom string, margin string) main.BidEvent {
+		m := dec(margin)
+		return main.BidEvent{
+			AuctionID: auctionID,
+			Bidder:    bidder,
+			Time:      start.Add(at),
+			EndTime:   start.Add(at + time.Hour),
+			LotDenom:  denom,
+			Margin:    &m,
+		}
+	}
+
+	bids := []main.BidEvent{
+		bid(1, "us", 0, "bnb", "0.2"),
+		bid(1, "fast", 12*time.Second, "bnb", "0.1"),
+		bid(1, "us", time.Minute, "bnb", "0.08"),
+		bid(1, "fast", time.Minute+30*time.Second, "bnb", "0.04"),
+		bid(2, "fast", time.Hour, "ukava", "0.12"),
+		bid(2, "slow", 2*time.Hour, "ukava", "0.1"),
+		// closes after the end of the range
+		bid(3, "fast", 23*time.Hour+30*time.Minute, "bnb", "0.3"),
+	}
+
+	stats := main.GetBidderStats(bids, "us", end)
+	require.Len(t, stats, 3)
+
+	fast := stats[0]
+	require.Equal(t, "fast", fast.Bidder)
+	require.Equal(t, 4, fast.Bids)
+	require.Equal(t, 3, fast.AuctionsBid)
+	require.Equal(t, 2, fast.AuctionsClosed)
+	require.Equal(t, 1, fast.AuctionsWon)
+	require.Equal(t, dec("0.5"), *fast.WinRate)
+	require.Equal(t, dec("0.11"), *fast.MedianMargin)
+	require.Equal(t, 2, fast.Reactions)
+	require.Equal(t, 21.0, *fast.MedianReactionSeconds)
+	require.Equal(t, []types.AssetBids{{Denom: "bnb", Bids: 3}, {Denom: "ukava", Bids: 1}}, fast.PreferredAssets)
+
+	us := stats[1]
+	require.Equal(t, "us", us.Bidder)
+	require.Equal(t, 0, us.AuctionsWon)
+	require.Equal(t, dec("0"), *us.WinRate)
+	require.Equal(t, 0, us.Reactions)
+	require.Nil(t, us.MedianReactionSeconds)
+
+	slow := stats[2]
+	require.Equal(t, "slow", slow.Bidder)
+	require.Equal(t, dec("1"), *slow.WinRate)
+	// bid after a competitor rather than us
+	require.Equal(t, 0, slow.Reactions)
+
+	records := stats.ToRecords()
+	require.Equal(t, []string{"fast", "4", "3", "2", "1", "0.500000000000000000", "0.110000000000000000", "2", "21", "BNB:3 KAVA:1"}, records[0])
+}
@@ -13,6 +13,13 @@ const (
 	rpcUrlEnvKey  = "RPC_URL"
 	startEnvKey   = "START_HEIGHT"
 	endEnvKey     = "END_HEIGHT"
+	bidderEnvKey  = "BIDDER_ADDRESS"
+	formatEnvKey  = "OUTPUT_FORMAT"
+)
+
+const (
+	OutputFormatCsv  = "csv"
+	OutputFormatJson = "json"
 )
 
 // ConfigLoader provides an interface for
@@ -35,6 +42,17 @@ type Config struct {
 
 	StartHeight int64
 	EndHeight   int64
+
+	// BidderAddress is our bidder, competitor reaction times are measured
+	// from its bids
+	BidderAddress string
+	// OutputFormat is csv or json, json is only supported for bidder stats
+	OutputFormat string
+}
+
+// JsonOutput returns true if output should be written as json
+func (c Config) JsonOutput() bool {
+	return c.OutputFormat == OutputFormatJson
 }
 
 func LoadConfig(loader ConfigLoader) (Config, error) {
@@ -65,10 +83,21 @@ func LoadConfig(loader ConfigLoader) (Config, error) {
 		return Config{}, err
 	}
 
+	outputFormat := loader.Get(formatEnvKey)
+	switch outputFormat {
+	case "":
+		outputFormat = OutputFormatCsv
+	case OutputFormatCsv, OutputFormatJson:
+	default:
+		return Config{}, fmt.Errorf("%s must be %s or %s", formatEnvKey, OutputFormatCsv, OutputFormatJson)
+	}
+
 	return Config{
-		GrpcURL:     grpcURL,
-		RpcURL:      rpcURL,
-		StartHeight: startHeight,
-		EndHeight:   endHeight,
+		GrpcURL:       grpcURL,
+		RpcURL:        rpcURL,
+		StartHeight:   startHeight,
+		EndHeight:     endHeight,
+		BidderAddress: loader.Get(bidderEnvKey),
+		OutputFormat:  outputFormat,
 	}, nil
 }
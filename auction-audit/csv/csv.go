@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/kava-labs/go-tools/auction-audit/config"
 )
@@ -16,6 +17,13 @@ func GetFileName(prefix string, config config.Config) string {
 	)
 }
 
+// GetJsonFileOutput creates a file named like GetFileName with a json
+// extension
+func GetJsonFileOutput(prefix string, config config.Config) (*os.File, error) {
+	fileName := strings.TrimSuffix(GetFileName(prefix, config), ".csv") + ".json"
+	return os.Create(fileName)
+}
+
 func GetFileOutput(prefix string, config config.Config) (*os.File, error) {
 	fileName := GetFileName(prefix, config)
 	return os.Create(fileName)
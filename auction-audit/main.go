@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 
@@ -13,7 +14,9 @@ import (
 	"github.com/kava-labs/kava/app"
 )
 
-func tryMain(logger log.Logger) error {
+// connect loads config and creates a grpc client that has been checked to be
+// responding
+func connect(logger log.Logger) (config.Config, GrpcClient, error) {
 
 	//
 	// bootstrap kava chain config
@@ -31,7 +34,7 @@ func tryMain(logger log.Logger) error {
 	//
 	config, err := config.LoadConfig(&config.EnvLoader{})
 	if err != nil {
-		return err
+		return config, GrpcClient{}, err
 	}
 
 	logger.With(
@@ -55,17 +58,27 @@ func tryMain(logger log.Logger) error {
 		encodingConfig.TxConfig,
 	)
 	if err != nil {
-		return err
+		return config, GrpcClient{}, err
 	}
 
-	defer grpcClient.GrpcClientConn.Close()
 	nodeInfoResponse, err := grpcClient.Tm.GetNodeInfo(context.Background(), &tmservice.GetNodeInfoRequest{})
 	if err != nil {
-		return fmt.Errorf("failed to fetch chain id: %w", err)
+		grpcClient.GrpcClientConn.Close()
+		return config, GrpcClient{}, fmt.Errorf("failed to fetch chain id: %w", err)
 	}
 
 	logger.Info(fmt.Sprintf("chain id: %s", nodeInfoResponse.DefaultNodeInfo.Network))
 
+	return config, grpcClient, nil
+}
+
+func tryMain(logger log.Logger) error {
+	config, grpcClient, err := connect(logger)
+	if err != nil {
+		return err
+	}
+	defer grpcClient.GrpcClientConn.Close()
+
 	// Crawl blocks to find auctions and inbound transfers
 	logger.Info("Fetching auction end data... this may take a while")
 	auctionIdToHeightMap, err := GetAuctionEndData(
@@ -146,11 +159,102 @@ func tryMain(logger log.Logger) error {
 	return nil
 }
 
+// tryBidders writes statistics for every account that bid in the height range
+func tryBidders(logger log.Logger) error {
+	config, grpcClient, err := connect(logger)
+	if err != nil {
+		return err
+	}
+	defer grpcClient.GrpcClientConn.Close()
+
+	logger.Info("Fetching auction bids... this may take a while")
+	txs, err := GetAuctionBidEvents(logger, grpcClient, config.StartHeight, config.EndHeight)
+	if err != nil {
+		return fmt.Errorf("failed to fetch auction bids: %w", err)
+	}
+
+	bids, err := ParseAuctionBidEvents(txs, config.EndHeight)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Found bids", "count", len(bids))
+
+	if len(bids) == 0 {
+		logger.Info("No bids found, stopping.")
+		return nil
+	}
+
+	logger.Info("Fetching bid time and USD value data...")
+	if err := GetBidEventData(logger, grpcClient, bids); err != nil {
+		return fmt.Errorf("failed to fetch bid data: %w", err)
+	}
+
+	endBlock, err := grpcClient.Tm.GetBlockByHeight(
+		context.Background(),
+		&tmservice.GetBlockByHeightRequest{Height: config.EndHeight},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to fetch end block: %w", err)
+	}
+
+	stats := GetBidderStats(bids, config.BidderAddress, endBlock.Block.Header.Time)
+
+	if config.JsonOutput() {
+		outputFile, err := csv.GetJsonFileOutput("bidder_stats", config)
+		if err != nil {
+			return fmt.Errorf("failed to get file output: %w", err)
+		}
+		defer outputFile.Close()
+
+		logger.Info("Writing output data to json", "fileName", outputFile.Name())
+
+		encoder := json.NewEncoder(outputFile)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+
+	outputFile, err := csv.GetFileOutput("bidder_stats", config)
+	if err != nil {
+		return fmt.Errorf("failed to get file output: %w", err)
+	}
+	defer outputFile.Close()
+
+	logger.Info("Writing output data to csv", "fileName", outputFile.Name())
+
+	err = csv.WriteCsv(
+		outputFile,
+		[]string{
+			"Bidder Account",
+			"Bids",
+			"Auctions Bid",
+			"Auctions Closed",
+			"Auctions Won",
+			"Win Rate",
+			"Median Margin",
+			"Reactions To Our Bids",
+			"Median Reaction Seconds",
+			"Preferred Assets",
+		},
+		stats,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	return nil
+}
+
 func main() {
 	// create base logger
 	logger := log.NewTMLogger(log.NewSyncWriter(os.Stdout))
 
-	if err := tryMain(logger); err != nil {
+	run := tryMain
+	if len(os.Args) > 1 && os.Args[1] == "bidders" {
+		run = tryBidders
+	}
+
+	if err := run(logger); err != nil {
 		logger.Error(err.Error())
 		os.Exit(1)
 	}
@@ -0,0 +1,99 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// AssetBids is the number of bids a bidder placed on auctions of a lot denom
+type AssetBids struct {
+	Denom string `json:"denom"`
+	Bids  int    `json:"bids"`
+}
+
+// BidderStats summarizes the bids of a single account over a height range
+type BidderStats struct {
+	Bidder string `json:"bidder"`
+	Bids   int    `json:"bids"`
+	// AuctionsBid is the number of auctions the bidder bid on
+	AuctionsBid int `json:"auctions_bid"`
+	// AuctionsClosed is the number of auctions bid on that closed before
+	// the end of the range, the only auctions with a known winner
+	AuctionsClosed int `json:"auctions_closed"`
+	AuctionsWon    int `json:"auctions_won"`
+	// WinRate is the fraction of closed auctions that were won, nil when
+	// none closed
+	WinRate *sdk.Dec `json:"win_rate,omitempty"`
+	// MedianMargin is the median margin of bids valued at pricefeed prices
+	// at the height of the bid, nil when no bid could be valued
+	MedianMargin *sdk.Dec `json:"median_margin,omitempty"`
+	// Reactions is the number of bids placed directly after one of ours
+	Reactions int `json:"reactions"`
+	// MedianReactionSeconds is the median time between one of our bids and
+	// the bidder's next bid on the same auction, nil without reactions
+	MedianReactionSeconds *float64 `json:"median_reaction_seconds,omitempty"`
+	// PreferredAssets are lot denoms by number of bids, most bid on first
+	PreferredAssets []AssetBids `json:"preferred_assets"`
+}
+
+// BidderStatsList is a list of bidder statistics, ordered by number of bids
+type BidderStatsList []BidderStats
+
+// ToRecords converts BidderStatsList to a 2D string array
+func (list BidderStatsList) ToRecords() [][]string {
+	var records [][]string
+
+	for _, stats := range list {
+		winRate := ""
+		if stats.WinRate != nil {
+			winRate = stats.WinRate.String()
+		}
+
+		medianMargin := ""
+		if stats.MedianMargin != nil {
+			medianMargin = stats.MedianMargin.String()
+		}
+
+		medianReaction := ""
+		if stats.MedianReactionSeconds != nil {
+			medianReaction = fmt.Sprintf("%.0f", *stats.MedianReactionSeconds)
+		}
+
+		var assets []string
+		for _, asset := range stats.PreferredAssets {
+			name, found := DenomMap[asset.Denom]
+			if !found {
+				name = asset.Denom
+			}
+			assets = append(assets, fmt.Sprintf("%s:%d", name, asset.Bids))
+		}
+
+		records = append(records, []string{
+			stats.Bidder,
+			fmt.Sprintf("%d", stats.Bids),
+			fmt.Sprintf("%d", stats.AuctionsBid),
+			fmt.Sprintf("%d", stats.AuctionsClosed),
+			fmt.Sprintf("%d", stats.AuctionsWon),
+			winRate,
+			medianMargin,
+			fmt.Sprintf("%d", stats.Reactions),
+			medianReaction,
+			strings.Join(assets, " "),
+		})
+	}
+
+	return records
+}
+
+// SortPreferredAssets orders assets by number of bids, then denom
+func SortPreferredAssets(assets []AssetBids) {
+	sort.Slice(assets, func(i, j int) bool {
+		if assets[i].Bids != assets[j].Bids {
+			return assets[i].Bids > assets[j].Bids
+		}
+		return assets[i].Denom < assets[j].Denom
+	})
+}
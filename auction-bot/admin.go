@@ -0,0 +1,167 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+)
+
+// max size of admin request bodies
+const maxAdminBodyBytes = 1 << 20
+
+// AdminStatus summarizes the runtime settings of the bot
+type AdminStatus struct {
+	Paused         bool               `json:"paused"`
+	Policy         BidPolicy          `json:"policy"`
+	PriceOverrides map[string]sdk.Dec `json:"price_overrides"`
+	Inflight       int                `json:"inflight"`
+}
+
+type priceOverrideRequest struct {
+	Price sdk.Dec `json:"price"`
+}
+
+type adminError struct {
+	Error string `json:"error"`
+}
+
+// NewAdminRouter creates the admin api for controlling the bot at runtime,
+// every request must send the token as a bearer token
+func NewAdminRouter(logger zerolog.Logger, state *BotState, token string) http.Handler {
+	r := chi.NewRouter()
+	r.Use(requireBearerToken(token))
+
+	r.Get("/status", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, AdminStatus{
+			Paused:         state.Paused(),
+			Policy:         state.Policy(),
+			PriceOverrides: state.PriceOverrides(),
+			Inflight:       len(state.Inflight()),
+		})
+	})
+
+	r.Post("/pause", func(w http.ResponseWriter, r *http.Request) {
+		state.SetPaused(true)
+		logger.Warn().Msg("bidding paused by admin")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	r.Post("/resume", func(w http.ResponseWriter, r *http.Request) {
+		state.SetPaused(false)
+		logger.Warn().Msg("bidding resumed by admin")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	r.Get("/policy", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, state.Policy())
+	})
+
+	// fields missing from the body keep their current values
+	r.Patch("/policy", func(w http.ResponseWriter, r *http.Request) {
+		bz, err := io.ReadAll(io.LimitReader(r.Body, maxAdminBodyBytes))
+		if err != nil {
+			writeAdminError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		policy, err := ParseBidPolicy(bz, state.Policy())
+		if err != nil {
+			writeAdminError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := state.SetPolicy(policy); err != nil {
+			writeAdminError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		logger.Warn().Interface("policy", policy).Msg("bid policy changed by admin")
+		writeAdminJSON(w, http.StatusOK, policy)
+	})
+
+	r.Get("/prices", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, state.PriceOverrides())
+	})
+
+	r.Put("/prices/{denom}", func(w http.ResponseWriter, r *http.Request) {
+		denom := chi.URLParam(r, "denom")
+
+		var req priceOverrideRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxAdminBodyBytes)).Decode(&req); err != nil {
+			writeAdminError(w, http.StatusBadRequest, fmt.Errorf("invalid price json: %w", err))
+			return
+		}
+		if err := state.SetPriceOverride(denom, req.Price); err != nil {
+			writeAdminError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		logger.Warn().Str("denom", denom).Str("price", req.Price.String()).Msg("price override set by admin")
+		writeAdminJSON(w, http.StatusOK, state.PriceOverrides())
+	})
+
+	r.Delete("/prices/{denom}", func(w http.ResponseWriter, r *http.Request) {
+		denom := chi.URLParam(r, "denom")
+		state.ClearPriceOverride(denom)
+
+		logger.Warn().Str("denom", denom).Msg("price override cleared by admin")
+		writeAdminJSON(w, http.StatusOK, state.PriceOverrides())
+	})
+
+	// candidate bids of the last cycle, including bids held until closer to
+	// the auction end
+	r.Get("/bids", func(w http.ResponseWriter, r *http.Request) {
+		candidates := BidDecisions{}
+		for _, decision := range state.Decisions() {
+			if decision.Proposed != nil {
+				candidates = append(candidates, decision)
+			}
+		}
+
+		writeAdminJSON(w, http.StatusOK, candidates)
+	})
+
+	r.Get("/requests", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, state.Inflight())
+	})
+
+	r.Post("/cycle", func(w http.ResponseWriter, r *http.Request) {
+		state.ForceCycle()
+		logger.Info().Msg("bid cycle forced by admin")
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	return r
+}
+
+// requireBearerToken rejects requests without the token in the authorization
+// header
+func requireBearerToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if token == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				writeAdminError(w, http.StatusUnauthorized, fmt.Errorf("unauthorized"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeAdminError(w http.ResponseWriter, status int, err error) {
+	writeAdminJSON(w, status, adminError{Error: err.Error()})
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminRouter(t *testing.T) {
+	state := NewBotState(NewBidPolicy(d("0.05")), map[string]sdk.Dec{"usdx": d("1.00")})
+	router := NewAdminRouter(zerolog.Nop(), state, "secret")
+
+	do := func(method, path, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	require.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/status", "", "").Code)
+	require.Equal(t, http.StatusUnauthorized, do(http.MethodPost, "/pause", "", "wrong").Code)
+	require.False(t, state.Paused())
+
+	require.Equal(t, http.StatusNoContent, do(http.MethodPost, "/pause", "", "secret").Code)
+	require.True(t, state.Paused())
+	require.Equal(t, http.StatusNoContent, do(http.MethodPost, "/resume", "", "secret").Code)
+	require.False(t, state.Paused())
+
+	// policy changes keep fields that are not sent
+	rec := do(http.MethodPatch, "/policy", `{"margin": "0.08", "lot_denom_margins": {"bnb": "0.1"}}`, "secret")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, d("0.08"), state.Policy().Margin)
+	require.Equal(t, d("0.1"), state.Policy().MarginFor("bnb", "usdx"))
+	require.Equal(t, []string{USTDenom}, state.Policy().DeniedDenoms)
+
+	rec = do(http.MethodPatch, "/policy", `{"margin": "1.5"}`, "secret")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, d("0.08"), state.Policy().Margin)
+
+	require.Equal(t, http.StatusOK, do(http.MethodPut, "/prices/bnb", `{"price": "210.5"}`, "secret").Code)
+	require.Equal(t, http.StatusBadRequest, do(http.MethodPut, "/prices/bnb", `{"price": "0"}`, "secret").Code)
+	require.Equal(t, map[string]sdk.Dec{"usdx": d("1.00"), "bnb": d("210.5")}, state.PriceOverrides())
+	require.Equal(t, http.StatusOK, do(http.MethodDelete, "/prices/usdx", "", "secret").Code)
+	require.Equal(t, map[string]sdk.Dec{"bnb": d("210.5")}, state.PriceOverrides())
+
+	bid := c("usdx", 100)
+	state.SetDecisions(BidDecisions{
+		{AuctionID: 1, Proposed: &bid},
+		{AuctionID: 2, SkipReason: errNoProfitableBid.Error()},
+	})
+	rec = do(http.MethodGet, "/bids", "", "secret")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var candidates []BidDecision
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &candidates))
+	require.Len(t, candidates, 1)
+	require.Equal(t, uint64(1), candidates[0].AuctionID)
+
+	id := state.AddInflight(InflightRequest{AuctionIDs: []uint64{1}})
+	rec = do(http.MethodGet, "/requests", "", "secret")
+	var inflight []InflightRequest
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &inflight))
+	require.Len(t, inflight, 1)
+	require.Equal(t, id, inflight[0].ID)
+	state.RemoveInflight(id)
+	require.Empty(t, state.Inflight())
+
+	require.Equal(t, http.StatusAccepted, do(http.MethodPost, "/cycle", "", "secret").Code)
+	require.Equal(t, http.StatusAccepted, do(http.MethodPost, "/cycle", "", "secret").Code)
+	<-state.Forced()
+	select {
+	case <-state.Forced():
+		t.Fatal("repeated forces should run a single cycle")
+	default:
+	}
+}
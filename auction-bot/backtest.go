@@ -0,0 +1,433 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/kava-labs/kava/app"
+	auctiontypes "github.com/kava-labs/kava/x/auction/types"
+	"github.com/rs/zerolog"
+)
+
+const (
+	hourSeconds        = 3600
+	approxBlockSeconds = 6
+	// outcomes are valued at prices this many hours after the auction closes
+	outcomeValuationHours = 4
+)
+
+// BacktestStrategy determines how the bot's proposals are placed against competing bids
+type BacktestStrategy string
+
+const (
+	// ProposalStrategy places the proposal from GetBids as is, matching the live bot
+	ProposalStrategy BacktestStrategy = "proposal"
+	// MinIncrementStrategy outbids the final competing bid by the smallest valid
+	// increment, as long as that does not go past the proposal from GetBids
+	MinIncrementStrategy BacktestStrategy = "min-increment"
+)
+
+// Validate returns an error if the strategy is unknown
+func (s BacktestStrategy) Validate() error {
+	switch s {
+	case ProposalStrategy, MinIncrementStrategy:
+		return nil
+	default:
+		return fmt.Errorf("unknown strategy %s", s)
+	}
+}
+
+// bidPosition orders bids within an auction, reverse bids always beat forward
+// bids, forward bids are ordered by bid amount and reverse bids by lot amount
+type bidPosition struct {
+	Reverse bool
+	Amount  sdk.Int
+}
+
+func (p bidPosition) Beats(other bidPosition) bool {
+	if p.Reverse != other.Reverse {
+		return p.Reverse
+	}
+	if p.Reverse {
+		return p.Amount.LT(other.Amount)
+	}
+	return p.Amount.GT(other.Amount)
+}
+
+// backtestAuction tracks a single auction across sampled heights
+type backtestAuction struct {
+	ID          uint64
+	Type        string
+	Lot         sdk.Coin
+	MaxBid      sdk.Coin
+	LastSeen    int64
+	CloseHeight int64
+
+	// the final on chain bid, updated from sampled state and bid history
+	Final       bidPosition
+	FinalBidder string
+
+	// the best proposal from GetBids over all samples, if any
+	Proposal *bidPosition
+}
+
+// BacktestResult is the simulated outcome of a single auction
+type BacktestResult struct {
+	AuctionID        uint64
+	AuctionType      string
+	CloseHeight      int64
+	FinalBidder      string
+	FinalBid         sdk.Coin
+	Proposal         *sdk.Coin
+	Won              bool
+	Paid             sdk.Coin
+	Received         sdk.Coin
+	PaidUSDValue     sdk.Dec
+	ReceivedUSDValue sdk.Dec
+	Profit           sdk.Dec
+}
+
+// RunBacktest replays historical auctions through GetBids and reports which
+// auctions would have been won and their profit at prices after close
+func RunBacktest(logger zerolog.Logger) error {
+	config, err := LoadBacktestConfig(&EnvLoader{})
+	if err != nil {
+		return err
+	}
+
+	logger.Info().
+		Int64("startHeight", config.StartHeight).
+		Int64("endHeight", config.EndHeight).
+		Int64("sampleInterval", config.SampleInterval).
+		Str("profitMargin", config.BidPolicy.Margin.String()).
+		Str("strategy", string(config.Strategy)).
+		Msg("backtest config loaded")
+
+	encodingConfig := app.MakeEncodingConfig()
+
+	var source BacktestSource
+	if config.FixturesDir != "" {
+		source = NewFixtureBacktestSource(encodingConfig.Marshaler, config.FixturesDir)
+	} else {
+		grpcClient := NewGrpcClient(config.KavaGrpcUrl, encodingConfig.Marshaler)
+		defer grpcClient.GrpcClientConn.Close()
+
+		source = NewGrpcBacktestSource(grpcClient)
+	}
+
+	if config.RecordDir != "" {
+		source, err = NewRecordingBacktestSource(source, encodingConfig.Marshaler, config.RecordDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	results, err := Backtest(logger, source, config)
+	if err != nil {
+		return err
+	}
+
+	won := 0
+	totalProfit := sdk.ZeroDec()
+	for _, result := range results {
+		if result.Won {
+			won++
+			totalProfit = totalProfit.Add(result.Profit)
+		}
+	}
+
+	logger.Info().
+		Int("auctions", len(results)).
+		Int("won", won).
+		Str("profit", totalProfit.String()).
+		Str("report", config.ReportPath).
+		Msg("backtest complete")
+
+	return WriteBacktestReport(config.ReportPath, results)
+}
+
+// Backtest samples auction state over a height range, running GetBids at each
+// sample, and simulates the proposals against the real bid history
+//
+// Only auctions that close within the range are reported. Competitors are
+// assumed to bid as they did historically, so the simulation does not account
+// for them reacting to our bids.
+func Backtest(logger zerolog.Logger, source BacktestSource, config BacktestConfig) ([]BacktestResult, error) {
+	auctions := make(map[uint64]*backtestAuction)
+
+	for height := config.StartHeight; height <= config.EndHeight; height += config.SampleInterval {
+		data, err := source.AuctionDataAtHeight(height)
+		if err != nil {
+			return nil, err
+		}
+
+		logger.Debug().
+			Int64("height", height).
+			Int("auctions", len(data.Auctions)).
+			Msg("sampled auctions")
+
+		open := make(map[uint64]bool)
+		for _, auction := range data.Auctions {
+			// GetBids never bids on surplus auctions
+			if auction.GetType() != auctiontypes.CollateralAuctionType && auction.GetType() != auctiontypes.DebtAuctionType {
+				continue
+			}
+			open[auction.GetID()] = true
+
+			tracked, found := auctions[auction.GetID()]
+			if !found {
+				tracked = &backtestAuction{
+					ID:   auction.GetID(),
+					Type: auction.GetType(),
+				}
+				auctions[auction.GetID()] = tracked
+			}
+			tracked.observe(auction, height)
+		}
+
+		// auctions missing from this sample closed since the previous one
+		for _, tracked := range auctions {
+			if tracked.CloseHeight == 0 && !open[tracked.ID] {
+				tracked.CloseHeight = height
+			}
+		}
+
+		for _, bid := range GetBids(zerolog.Nop(), data, sdk.AccAddress{}, config.BidPolicy) {
+			auctions[bid.ID].propose(bid.Amount)
+		}
+	}
+
+	bids, err := source.BidHistory(config.StartHeight, config.EndHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	// bids after the last sample an auction was seen in are not reflected in sampled state
+	for _, bid := range bids {
+		tracked, found := auctions[bid.AuctionID]
+		if !found || bid.Height <= tracked.LastSeen {
+			continue
+		}
+		if tracked.CloseHeight != 0 && bid.Height >= tracked.CloseHeight {
+			continue
+		}
+
+		tracked.Final = tracked.position(bid.Amount, bid.Reverse)
+		tracked.FinalBidder = bid.Bidder
+	}
+
+	ids := make([]uint64, 0, len(auctions))
+	for id, tracked := range auctions {
+		if tracked.CloseHeight != 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	blocksAfterClose := int64(outcomeValuationHours * hourSeconds / approxBlockSeconds)
+	// price data is reused for auctions valued at the same height
+	valuations := make(map[int64]*AuctionData)
+
+	results := make([]BacktestResult, 0, len(ids))
+	for _, id := range ids {
+		tracked := auctions[id]
+
+		valuationHeight := tracked.CloseHeight + blocksAfterClose
+		valuation, found := valuations[valuationHeight]
+		if !found {
+			valuation, err = source.AuctionDataAtHeight(valuationHeight)
+			if err != nil {
+				return nil, err
+			}
+			valuations[valuationHeight] = valuation
+		}
+
+		results = append(results, tracked.simulate(config.Strategy, valuation))
+	}
+
+	return results, nil
+}
+
+// observe updates the tracked auction with its state at a sampled height
+func (a *backtestAuction) observe(auction auctiontypes.Auction, height int64) {
+	a.LastSeen = height
+
+	switch auction := auction.(type) {
+	case *auctiontypes.CollateralAuction:
+		a.MaxBid = auction.MaxBid
+		// the lot only decreases in the reverse phase, keep the full lot for forward bids
+		if !auction.IsReversePhase() || a.Lot.Amount.IsNil() {
+			a.Lot = auction.Lot
+		}
+	case *auctiontypes.DebtAuction:
+		a.MaxBid = auction.Bid
+		a.Lot = auction.Lot
+	}
+
+	if auction.GetPhase() == auctiontypes.ReverseAuctionPhase {
+		a.Final = a.position(auction.GetLot(), true)
+	} else {
+		a.Final = a.position(auction.GetBid(), false)
+	}
+	a.FinalBidder = auction.GetBidder().String()
+}
+
+// position converts a bid or lot amount to a position, a forward bid that
+// reaches the max bid moves the auction to the reverse phase with the full lot
+func (a *backtestAuction) position(amount sdk.Coin, reverse bool) bidPosition {
+	if !reverse && !a.MaxBid.Amount.IsNil() && amount.Amount.GTE(a.MaxBid.Amount) {
+		return bidPosition{Reverse: true, Amount: a.Lot.Amount}
+	}
+
+	return bidPosition{Reverse: reverse, Amount: amount.Amount}
+}
+
+// coin returns the amount of a position in the denom it is bid in
+func (a *backtestAuction) coin(p bidPosition) sdk.Coin {
+	if p.Reverse {
+		return sdk.NewCoin(a.Lot.Denom, p.Amount)
+	}
+	return sdk.NewCoin(a.MaxBid.Denom, p.Amount)
+}
+
+// propose keeps the strongest proposal seen, since a placed bid can not be withdrawn
+func (a *backtestAuction) propose(amount sdk.Coin) {
+	proposal := a.position(amount, amount.Denom == a.Lot.Denom)
+	if a.Proposal == nil || proposal.Beats(*a.Proposal) {
+		a.Proposal = &proposal
+	}
+}
+
+// outbid returns the smallest valid bid that beats the final bid, or false if
+// the lot can not be reduced any further
+func (a *backtestAuction) outbid(increment sdk.Dec) (bidPosition, bool) {
+	if !a.Final.Reverse {
+		return a.position(sdk.NewCoin(a.MaxBid.Denom, minNewBid(a.Final.Amount, increment)), false), true
+	}
+
+	decrement := sdk.MaxInt(sdk.OneInt(), sdk.NewDecFromInt(a.Final.Amount).Mul(increment).RoundInt())
+	lot := a.Final.Amount.Sub(decrement)
+	if !lot.IsPositive() {
+		return bidPosition{}, false
+	}
+
+	return bidPosition{Reverse: true, Amount: lot}, true
+}
+
+// simulate determines if the auction would have been won with the given
+// strategy and values the outcome with asset prices from valuation
+func (a *backtestAuction) simulate(strategy BacktestStrategy, valuation *AuctionData) BacktestResult {
+	result := BacktestResult{
+		AuctionID:        a.ID,
+		AuctionType:      a.Type,
+		CloseHeight:      a.CloseHeight,
+		FinalBidder:      a.FinalBidder,
+		FinalBid:         a.coin(a.Final),
+		PaidUSDValue:     sdk.ZeroDec(),
+		ReceivedUSDValue: sdk.ZeroDec(),
+		Profit:           sdk.ZeroDec(),
+	}
+
+	if a.Proposal == nil {
+		return result
+	}
+
+	proposal := a.coin(*a.Proposal)
+	result.Proposal = &proposal
+
+	placed := *a.Proposal
+	if strategy == MinIncrementStrategy {
+		var ok bool
+		placed, ok = a.outbid(valuation.BidIncrement)
+		// the proposal is the most we are willing to bid
+		if !ok || placed.Beats(*a.Proposal) {
+			return result
+		}
+	}
+
+	if !placed.Beats(a.Final) {
+		return result
+	}
+
+	result.Won = true
+	if placed.Reverse {
+		result.Paid = a.MaxBid
+		result.Received = a.coin(placed)
+	} else {
+		result.Paid = a.coin(placed)
+		result.Received = a.Lot
+	}
+
+	paidAsset, paidFound := valuation.Assets[result.Paid.Denom]
+	receivedAsset, receivedFound := valuation.Assets[result.Received.Denom]
+	if paidFound && receivedFound {
+		result.PaidUSDValue = calculateUSDValue(result.Paid, paidAsset)
+		result.ReceivedUSDValue = calculateUSDValue(result.Received, receivedAsset)
+		result.Profit = result.ReceivedUSDValue.Sub(result.PaidUSDValue)
+	}
+
+	return result
+}
+
+// WriteBacktestReport writes one csv row per simulated auction
+func WriteBacktestReport(path string, results []BacktestResult) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	if err := w.Write([]string{
+		"Auction ID",
+		"Auction Type",
+		"Close Height",
+		"Final Bidder",
+		"Final Bid",
+		"Proposal",
+		"Won",
+		"Paid",
+		"Received",
+		"Paid USD Value",
+		"Received USD Value",
+		"Profit",
+	}); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		proposal := ""
+		if result.Proposal != nil {
+			proposal = result.Proposal.String()
+		}
+		paid, received := "", ""
+		if result.Won {
+			paid = result.Paid.String()
+			received = result.Received.String()
+		}
+
+		if err := w.Write([]string{
+			strconv.FormatUint(result.AuctionID, 10),
+			result.AuctionType,
+			strconv.FormatInt(result.CloseHeight, 10),
+			result.FinalBidder,
+			result.FinalBid.String(),
+			proposal,
+			strconv.FormatBool(result.Won),
+			paid,
+			received,
+			result.PaidUSDValue.String(),
+			result.ReceivedUSDValue.String(),
+			result.Profit.String(),
+		}); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
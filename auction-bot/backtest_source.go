@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	auctiontypes "github.com/kava-labs/kava/x/auction/types"
+)
+
+const bidFixtureFile = "bids.json"
+
+// HistoricalBid is a bid placed on chain, taken from auction_bid tx events
+//
+// Amount is in the bid denom for forward bids and in the lot denom for reverse bids.
+type HistoricalBid struct {
+	Height    int64    `json:"height"`
+	AuctionID uint64   `json:"auction_id"`
+	Bidder    string   `json:"bidder"`
+	Amount    sdk.Coin `json:"amount"`
+	Reverse   bool     `json:"reverse"`
+}
+
+// BacktestSource provides historical auction state and bids for a backtest
+type BacktestSource interface {
+	AuctionDataAtHeight(height int64) (*AuctionData, error)
+	BidHistory(start, end int64) ([]HistoricalBid, error)
+}
+
+// GrpcBacktestSource reads historical state from an archive node
+type GrpcBacktestSource struct {
+	client GrpcClient
+}
+
+var _ BacktestSource = (*GrpcBacktestSource)(nil)
+
+func NewGrpcBacktestSource(client GrpcClient) *GrpcBacktestSource {
+	return &GrpcBacktestSource{client: client}
+}
+
+func (s *GrpcBacktestSource) AuctionDataAtHeight(height int64) (*AuctionData, error) {
+	return GetAuctionDataAtHeight(s.client, height)
+}
+
+func (s *GrpcBacktestSource) BidHistory(start, end int64) ([]HistoricalBid, error) {
+	var bids []HistoricalBid
+
+	for page := uint64(1); ; page++ {
+		res, err := s.client.Tx.GetTxsEvent(context.Background(), &txtypes.GetTxsEventRequest{
+			Events: []string{
+				"message.action='/kava.auction.v1beta1.MsgPlaceBid'",
+				fmt.Sprintf("tx.height>=%d", start),
+				fmt.Sprintf("tx.height<=%d", end),
+			},
+			OrderBy: txtypes.OrderBy_ORDER_BY_ASC,
+			Page:    page,
+			Limit:   PageLimit,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch bid txs: %w", err)
+		}
+
+		for _, txRes := range res.TxResponses {
+			// failed txs do not change auction state
+			if txRes.Code != 0 {
+				continue
+			}
+
+			txBids, err := parseBidEvents(txRes)
+			if err != nil {
+				return nil, err
+			}
+			bids = append(bids, txBids...)
+		}
+
+		if page*PageLimit >= res.Total {
+			return bids, nil
+		}
+	}
+}
+
+// parseBidEvents returns a bid for each auction_bid event in a tx
+func parseBidEvents(txRes *sdk.TxResponse) ([]HistoricalBid, error) {
+	var bids []HistoricalBid
+
+	for _, event := range txRes.Events {
+		if event.Type != auctiontypes.EventTypeAuctionBid {
+			continue
+		}
+
+		bid := HistoricalBid{Height: txRes.Height}
+		for _, attr := range event.Attributes {
+			switch attr.Key {
+			case auctiontypes.AttributeKeyAuctionID:
+				id, err := strconv.ParseUint(attr.Value, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid auction id in tx %s: %w", txRes.TxHash, err)
+				}
+				bid.AuctionID = id
+			case auctiontypes.AttributeKeyBidder:
+				bid.Bidder = attr.Value
+			// forward bids emit the new bid, reverse bids emit the new lot
+			case auctiontypes.AttributeKeyBid, auctiontypes.AttributeKeyLot:
+				amount, err := sdk.ParseCoinNormalized(attr.Value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid bid amount in tx %s: %w", txRes.TxHash, err)
+				}
+				bid.Amount = amount
+				bid.Reverse = attr.Key == auctiontypes.AttributeKeyLot
+			}
+		}
+
+		bids = append(bids, bid)
+	}
+
+	return bids, nil
+}
+
+// auctionDataFixture is the on disk format of AuctionData, auctions are
+// stored as a proto json encoded query response to preserve their types
+type auctionDataFixture struct {
+	Height       int64                `json:"height"`
+	BlockTime    time.Time            `json:"block_time"`
+	Assets       map[string]AssetInfo `json:"assets"`
+	BidIncrement sdk.Dec              `json:"bid_increment"`
+	Auctions     json.RawMessage      `json:"auctions"`
+}
+
+func auctionDataFixturePath(dir string, height int64) string {
+	return filepath.Join(dir, fmt.Sprintf("auction-data-%d.json", height))
+}
+
+// FixtureBacktestSource reads historical state previously recorded to a directory
+type FixtureBacktestSource struct {
+	cdc codec.Codec
+	dir string
+}
+
+var _ BacktestSource = (*FixtureBacktestSource)(nil)
+
+func NewFixtureBacktestSource(cdc codec.Codec, dir string) *FixtureBacktestSource {
+	return &FixtureBacktestSource{cdc: cdc, dir: dir}
+}
+
+func (s *FixtureBacktestSource) AuctionDataAtHeight(height int64) (*AuctionData, error) {
+	bz, err := os.ReadFile(auctionDataFixturePath(s.dir, height))
+	if err != nil {
+		return nil, fmt.Errorf("no fixture for height %d: %w", height, err)
+	}
+
+	var fixture auctionDataFixture
+	if err := json.Unmarshal(bz, &fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture for height %d: %w", height, err)
+	}
+
+	var res auctiontypes.QueryAuctionsResponse
+	if err := s.cdc.UnmarshalJSON(fixture.Auctions, &res); err != nil {
+		return nil, fmt.Errorf("invalid auctions in fixture for height %d: %w", height, err)
+	}
+
+	auctions := make([]auctiontypes.Auction, 0, len(res.Auctions))
+	for _, anyAuction := range res.Auctions {
+		var auction auctiontypes.Auction
+		if err := s.cdc.UnpackAny(anyAuction, &auction); err != nil {
+			return nil, fmt.Errorf("failed to unpack auction: %w", err)
+		}
+		auctions = append(auctions, auction)
+	}
+
+	return &AuctionData{
+		Height:       fixture.Height,
+		BlockTime:    fixture.BlockTime,
+		Assets:       fixture.Assets,
+		Auctions:     auctions,
+		BidIncrement: fixture.BidIncrement,
+	}, nil
+}
+
+func (s *FixtureBacktestSource) BidHistory(start, end int64) ([]HistoricalBid, error) {
+	bz, err := os.ReadFile(filepath.Join(s.dir, bidFixtureFile))
+	if err != nil {
+		return nil, fmt.Errorf("no bid history fixture: %w", err)
+	}
+
+	var recorded []HistoricalBid
+	if err := json.Unmarshal(bz, &recorded); err != nil {
+		return nil, fmt.Errorf("invalid bid history fixture: %w", err)
+	}
+
+	var bids []HistoricalBid
+	for _, bid := range recorded {
+		if bid.Height >= start && bid.Height <= end {
+			bids = append(bids, bid)
+		}
+	}
+
+	return bids, nil
+}
+
+// RecordingBacktestSource writes everything read from the wrapped source to
+// a directory, so later runs can use a FixtureBacktestSource instead
+type RecordingBacktestSource struct {
+	source BacktestSource
+	cdc    codec.Codec
+	dir    string
+}
+
+var _ BacktestSource = (*RecordingBacktestSource)(nil)
+
+func NewRecordingBacktestSource(source BacktestSource, cdc codec.Codec, dir string) (*RecordingBacktestSource, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create fixture dir: %w", err)
+	}
+
+	return &RecordingBacktestSource{source: source, cdc: cdc, dir: dir}, nil
+}
+
+func (s *RecordingBacktestSource) AuctionDataAtHeight(height int64) (*AuctionData, error) {
+	data, err := s.source.AuctionDataAtHeight(height)
+	if err != nil {
+		return nil, err
+	}
+
+	anyAuctions := make([]*codectypes.Any, 0, len(data.Auctions))
+	for _, auction := range data.Auctions {
+		anyAuction, err := codectypes.NewAnyWithValue(auction)
+		if err != nil {
+			return nil, fmt.Errorf("failed to pack auction %d: %w", auction.GetID(), err)
+		}
+		anyAuctions = append(anyAuctions, anyAuction)
+	}
+
+	auctionsBz, err := s.cdc.MarshalJSON(&auctiontypes.QueryAuctionsResponse{Auctions: anyAuctions})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode auctions: %w", err)
+	}
+
+	err = writeJSONFile(auctionDataFixturePath(s.dir, height), auctionDataFixture{
+		Height:       data.Height,
+		BlockTime:    data.BlockTime,
+		Assets:       data.Assets,
+		BidIncrement: data.BidIncrement,
+		Auctions:     auctionsBz,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (s *RecordingBacktestSource) BidHistory(start, end int64) ([]HistoricalBid, error) {
+	bids, err := s.source.BidHistory(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeJSONFile(filepath.Join(s.dir, bidFixtureFile), bids); err != nil {
+		return nil, err
+	}
+
+	return bids, nil
+}
+
+func writeJSONFile(path string, v interface{}) error {
+	bz, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+
+	if err := os.WriteFile(path, bz, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
+}
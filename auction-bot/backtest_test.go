@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	auctiontypes "github.com/kava-labs/kava/x/auction/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+type mockBacktestSource struct {
+	data map[int64]*AuctionData
+	bids []HistoricalBid
+}
+
+func (s mockBacktestSource) AuctionDataAtHeight(height int64) (*AuctionData, error) {
+	data, found := s.data[height]
+	if !found {
+		return nil, fmt.Errorf("no data at height %d", height)
+	}
+	return data, nil
+}
+
+func (s mockBacktestSource) BidHistory(start, end int64) ([]HistoricalBid, error) {
+	return s.bids, nil
+}
+
+func TestBacktest(t *testing.T) {
+	assets := func(bnbPrice string) map[string]AssetInfo {
+		return map[string]AssetInfo{
+			"usdx": {Price: d("1.00"), ConversionFactor: sdk.NewInt(1e6)},
+			"bnb":  {Price: d(bnbPrice), ConversionFactor: sdk.NewInt(1e8)},
+		}
+	}
+	auction := &auctiontypes.CollateralAuction{
+		BaseAuction: auctiontypes.BaseAuction{
+			ID:  1,
+			Lot: c("bnb", 1000e8),
+			Bid: c("usdx", 0),
+		},
+		MaxBid:            c("usdx", 220_000e6),
+		CorrespondingDebt: c("debt", 200_000e6),
+	}
+	// surplus auctions are never bid on and are left out of the results
+	surplus := auctiontypes.NewSurplusAuction("liquidator", c("usdx", 10_000e6), "hard", time.Unix(0, 0))
+	surplus.ID = 2
+	blocksAfterClose := int64(outcomeValuationHours * hourSeconds / approxBlockSeconds)
+
+	source := mockBacktestSource{
+		data: map[int64]*AuctionData{
+			100: {Height: 100, Assets: assets("200"), Auctions: []auctiontypes.Auction{auction, &surplus}, BidIncrement: d("0.01")},
+			200: {Height: 200, Assets: assets("200"), BidIncrement: d("0.01")},
+			// valued 4 hours after close at a lower price
+			200 + blocksAfterClose: {Height: 200 + blocksAfterClose, Assets: assets("190"), BidIncrement: d("0.01")},
+		},
+		bids: []HistoricalBid{
+			{Height: 150, AuctionID: 1, Bidder: "competitor", Amount: c("usdx", 150_000e6)},
+			{Height: 160, AuctionID: 2, Bidder: "competitor", Amount: c("hard", 500e6)},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		strategy       BacktestStrategy
+		expectedPaid   sdk.Coin
+		expectedProfit sdk.Dec
+	}{
+		{
+			name:           "proposal is paid as is",
+			strategy:       ProposalStrategy,
+			expectedPaid:   c("usdx", 190_000e6),
+			expectedProfit: d("0"),
+		},
+		{
+			name:           "min increment outbids the final bid",
+			strategy:       MinIncrementStrategy,
+			expectedPaid:   c("usdx", 151_500e6),
+			expectedProfit: d("38500"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			results, err := Backtest(zerolog.Nop(), source, BacktestConfig{
+				StartHeight:    100,
+				EndHeight:      200,
+				SampleInterval: 100,
+				BidPolicy:      NewBidPolicy(d("0.05")),
+				Strategy:       tc.strategy,
+			})
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+
+			result := results[0]
+			require.Equal(t, uint64(1), result.AuctionID)
+			require.Equal(t, int64(200), result.CloseHeight)
+			require.Equal(t, "competitor", result.FinalBidder)
+			require.Equal(t, c("usdx", 150_000e6), result.FinalBid)
+			require.True(t, result.Won)
+			require.Equal(t, tc.expectedPaid, result.Paid)
+			require.Equal(t, c("bnb", 1000e8), result.Received)
+			require.Equal(t, tc.expectedProfit.String(), result.Profit.String())
+		})
+	}
+
+	t.Run("outbid above proposal is lost", func(t *testing.T) {
+		source.bids = []HistoricalBid{
+			{Height: 150, AuctionID: 1, Bidder: "competitor", Amount: c("usdx", 195_000e6)},
+		}
+
+		results, err := Backtest(zerolog.Nop(), source, BacktestConfig{
+			StartHeight:    100,
+			EndHeight:      200,
+			SampleInterval: 100,
+			BidPolicy:      NewBidPolicy(d("0.05")),
+			Strategy:       ProposalStrategy,
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		require.False(t, results[0].Won)
+		require.Equal(t, c("usdx", 190_000e6), *results[0].Proposal)
+	})
+}
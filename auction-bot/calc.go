@@ -1,6 +1,9 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	auctiontypes "github.com/kava-labs/kava/x/auction/types"
 	"github.com/rs/zerolog"
@@ -26,18 +29,55 @@ type AuctionInfo struct {
 
 type AuctionInfos []AuctionInfo
 
+// reasons an auction is not bid on, recorded in bid decisions
+var (
+	errDenomNotAllowed = errors.New("denom not allowed")
+	errUnsafePrice     = errors.New("unsafe price")
+	errLotTooLarge     = errors.New("lot usd value above max")
+	errInvalidPhase    = errors.New("invalid collateral auction phase")
+	errUnsupportedType = errors.New("unsupported auction type")
+	errLotAssetMissing = errors.New("lot asset info missing")
+	errBidAssetMissing = errors.New("bid asset info missing")
+	errNoProfitableBid = errors.New("no profitable bid")
+	errZeroProposal    = errors.New("proposed amount is zero")
+)
+
 func GetBids(
 	logger zerolog.Logger,
 	data *AuctionData,
 	keeper sdk.AccAddress,
-	margin sdk.Dec,
+	policy BidPolicy,
 ) AuctionInfos {
-	var auctionBidInfos AuctionInfos
+	return GetBidDecisions(logger, data, keeper, policy).Bids()
+}
+
+// GetBidDecisions evaluates every auction and returns a decision for each,
+// including the proposed bid for auctions that should be bid on and the
+// reason for those that are skipped.
+func GetBidDecisions(
+	logger zerolog.Logger,
+	data *AuctionData,
+	keeper sdk.AccAddress,
+	policy BidPolicy,
+) BidDecisions {
+	var decisions BidDecisions
 	var auctions int
 	debt := sdk.NewCoin("debt", sdk.ZeroInt())
 	for _, auction := range data.Auctions {
-		// Only non-UST auctions
-		if auction.GetLot().Denom == USTDenom {
+		decision := NewBidDecision(data, auction)
+
+		lotDenom, bidDenom := auction.GetLot().Denom, auction.GetBid().Denom
+		if !policy.Allows(lotDenom) || !policy.Allows(bidDenom) {
+			decisions = append(decisions, decision.Skip(errDenomNotAllowed))
+			continue
+		}
+		if err := unsafePrice(data, lotDenom, bidDenom); err != nil {
+			logger.Warn().
+				Err(err).
+				Uint64("auctionId", auction.GetID()).
+				Msg("skipping auction")
+
+			decisions = append(decisions, decision.Skip(err))
 			continue
 		}
 		auctions++
@@ -46,57 +86,77 @@ func GetBids(
 			debt = debt.Add(da.CorrespondingDebt)
 		}
 
+		if policy.MaxLotUSDValue != nil && decision.LotUSDValue.GT(*policy.MaxLotUSDValue) {
+			decisions = append(decisions, decision.Skip(errLotTooLarge))
+			continue
+		}
+
+		margin := policy.MarginFor(lotDenom, bidDenom)
+
+		// the lot is valued at its price less the haircut, so volatile lots
+		// and auctions far from closing need a larger margin at spot prices
+		assets := data.Assets
+		if haircut, ok := data.LotHaircuts[auction.GetID()]; ok {
+			decision = decision.Haircut(haircut)
+			assets = discountLot(data.Assets, lotDenom, haircut)
+		}
+
+		var bidInfo AuctionInfo
+		var err error
+
 		switch auction.GetType() {
 		case auctiontypes.CollateralAuctionType:
 			switch auction.GetPhase() {
 			case auctiontypes.ForwardAuctionPhase:
-				bidInfo, shouldBid := handleForwardCollateralAuction(
+				bidInfo, err = handleForwardCollateralAuction(
 					auction,
 					keeper,
-					data.Assets,
+					assets,
 					data.BidIncrement,
 					margin,
+					policy.Solver,
 				)
-				if !shouldBid {
-					continue
-				}
-				auctionBidInfos = append(auctionBidInfos, bidInfo)
 			case auctiontypes.ReverseAuctionPhase:
-				bidInfo, shouldBid := handleReverseCollateralAuction(
+				bidInfo, err = handleReverseCollateralAuction(
 					logger,
 					auction,
 					keeper,
-					data.Assets,
+					assets,
 					data.BidIncrement,
 					margin,
+					policy.Solver,
 				)
-				if !shouldBid {
-					continue
-				}
-				auctionBidInfos = append(auctionBidInfos, bidInfo)
 			default:
 				logger.Error().
 					Str("phase", auction.GetPhase()).
 					Msg("invalid collateral auction phase")
+
+				err = errInvalidPhase
 			}
 		case auctiontypes.DebtAuctionType:
-			bidInfo, shouldBid := handleReverseDebtAuction(
+			bidInfo, err = handleReverseDebtAuction(
 				logger,
 				auction,
 				keeper,
-				data.Assets,
+				assets,
 				data.BidIncrement,
 				margin,
+				policy.Solver,
 			)
-			if !shouldBid {
-				continue
-			}
-			auctionBidInfos = append(auctionBidInfos, bidInfo)
 		default:
 			logger.Error().
 				Str("auction type", auction.GetType()).
 				Msg("unsupported auction type")
+
+			err = errUnsupportedType
+		}
+
+		if err != nil {
+			decisions = append(decisions, decision.Skip(err))
+			continue
 		}
+
+		decisions = append(decisions, decision.Propose(bidInfo, assets))
 	}
 
 	logger.Info().
@@ -104,7 +164,19 @@ func GetBids(
 		Str("debt", debt.String()).
 		Msg("checked auctions")
 
-	return auctionBidInfos
+	return decisions
+}
+
+// unsafePrice returns an error if the price of any of the denoms failed the
+// price guard
+func unsafePrice(data *AuctionData, denoms ...string) error {
+	for _, denom := range denoms {
+		if err, found := data.PriceErrors[denom]; found {
+			return fmt.Errorf("%w for %s: %v", errUnsafePrice, denom, err)
+		}
+	}
+
+	return nil
 }
 
 func handleForwardCollateralAuction(
@@ -113,19 +185,25 @@ func handleForwardCollateralAuction(
 	assetInfo map[string]AssetInfo,
 	increment,
 	margin sdk.Dec,
-) (AuctionInfo, bool) {
+	solver BidSolver,
+) (AuctionInfo, error) {
 	collateralAuction := auction.(*auctiontypes.CollateralAuction)
 	assetInfoLot, ok := assetInfo[collateralAuction.Lot.Denom]
 	if !ok {
-		return AuctionInfo{}, false
+		return AuctionInfo{}, errLotAssetMissing
 	}
 
 	assetInfoBid, ok := assetInfo[collateralAuction.MaxBid.Denom]
 	if !ok {
-		return AuctionInfo{}, false
+		return AuctionInfo{}, errBidAssetMissing
+	}
+
+	proposeBid := solveProposedBid
+	if solver == LadderSolver {
+		proposeBid = calculateProposedBid
 	}
 
-	proposedBid, ok := calculateProposedBid(
+	proposedBid, ok := proposeBid(
 		collateralAuction.Bid,
 		collateralAuction.Lot,
 		collateralAuction.MaxBid,
@@ -137,18 +215,18 @@ func handleForwardCollateralAuction(
 	)
 
 	if !ok {
-		return AuctionInfo{}, false
+		return AuctionInfo{}, errNoProfitableBid
 	}
 
 	if proposedBid.IsZero() {
-		return AuctionInfo{}, false
+		return AuctionInfo{}, errZeroProposal
 	}
 
 	return AuctionInfo{
 		ID:     collateralAuction.ID,
 		Bidder: keeper,
 		Amount: proposedBid,
-	}, true
+	}, nil
 }
 
 func handleReverseCollateralAuction(
@@ -158,7 +236,8 @@ func handleReverseCollateralAuction(
 	assetInfo map[string]AssetInfo,
 	increment,
 	margin sdk.Dec,
-) (AuctionInfo, bool) {
+	solver BidSolver,
+) (AuctionInfo, error) {
 	collateralAuction := auction.(*auctiontypes.CollateralAuction)
 	assetInfoLot, ok := assetInfo[collateralAuction.Lot.Denom]
 	if !ok {
@@ -167,7 +246,7 @@ func handleReverseCollateralAuction(
 			Str("log denom", collateralAuction.Lot.Denom).
 			Msg("lot asset info missing, exiting")
 
-		return AuctionInfo{}, false
+		return AuctionInfo{}, errLotAssetMissing
 	}
 	assetInfoBid, ok := assetInfo[collateralAuction.MaxBid.Denom]
 	if !ok {
@@ -176,10 +255,15 @@ func handleReverseCollateralAuction(
 			Str("maxbid denom", collateralAuction.MaxBid.Denom).
 			Msg("max bid asset info missing, exiting")
 
-		return AuctionInfo{}, false
+		return AuctionInfo{}, errBidAssetMissing
 	}
 
-	proposedLot, ok := calculateProposedLot(
+	proposeLot := solveProposedLot
+	if solver == LadderSolver {
+		proposeLot = calculateProposedLot
+	}
+
+	proposedLot, ok := proposeLot(
 		logger,
 		collateralAuction.Lot,
 		collateralAuction.MaxBid,
@@ -190,18 +274,18 @@ func handleReverseCollateralAuction(
 		collateralAuction.GetID(),
 	)
 	if !ok {
-		return AuctionInfo{}, false
+		return AuctionInfo{}, errNoProfitableBid
 	}
 
 	if proposedLot.IsZero() {
-		return AuctionInfo{}, false
+		return AuctionInfo{}, errZeroProposal
 	}
 
 	return AuctionInfo{
 		ID:     collateralAuction.ID,
 		Bidder: keeper,
 		Amount: proposedLot,
-	}, true
+	}, nil
 }
 
 func handleReverseDebtAuction(
@@ -211,7 +295,8 @@ func handleReverseDebtAuction(
 	assetInfo map[string]AssetInfo,
 	increment,
 	margin sdk.Dec,
-) (AuctionInfo, bool) {
+	solver BidSolver,
+) (AuctionInfo, error) {
 	debtAuction := auction.(*auctiontypes.DebtAuction)
 	assetInfoLot, ok := assetInfo[debtAuction.Lot.Denom]
 	if !ok {
@@ -220,7 +305,7 @@ func handleReverseDebtAuction(
 			Str("log denom", debtAuction.Lot.Denom).
 			Msg("reverse debt lot asset info missing")
 
-		return AuctionInfo{}, false
+		return AuctionInfo{}, errLotAssetMissing
 	}
 	assetInfoBid, ok := assetInfo[debtAuction.Bid.Denom]
 	if !ok {
@@ -229,10 +314,15 @@ func handleReverseDebtAuction(
 			Str("bid denom", debtAuction.Bid.Denom).
 			Msg("reverse debt lot bid asset info missing")
 
-		return AuctionInfo{}, false
+		return AuctionInfo{}, errBidAssetMissing
+	}
+
+	proposeLot := solveProposedLot
+	if solver == LadderSolver {
+		proposeLot = calculateProposedLot
 	}
 
-	proposedLot, ok := calculateProposedLot(
+	proposedLot, ok := proposeLot(
 		logger,
 		debtAuction.Lot,
 		debtAuction.Bid,
@@ -243,18 +333,18 @@ func handleReverseDebtAuction(
 		debtAuction.GetID(),
 	)
 	if !ok {
-		return AuctionInfo{}, false
+		return AuctionInfo{}, errNoProfitableBid
 	}
 
 	if proposedLot.IsZero() {
-		return AuctionInfo{}, false
+		return AuctionInfo{}, errZeroProposal
 	}
 
 	return AuctionInfo{
 		ID:     debtAuction.ID,
 		Bidder: keeper,
 		Amount: proposedLot,
-	}, true
+	}, nil
 }
 
 func calculateUSDValue(coin sdk.Coin, assetInfo AssetInfo) sdk.Dec {
@@ -59,7 +59,7 @@ func TestGetBids(t *testing.T) {
 			margin: d("0.05"),
 			expectedBids: AuctionInfos{{
 				ID:     0,
-				Amount: c("usdx", 176_000e6),
+				Amount: c("usdx", 190_000e6),
 			}},
 		},
 		{
@@ -98,7 +98,7 @@ func TestGetBids(t *testing.T) {
 			testAddr, err := sdk.AccAddressFromBech32("kava10eup8kvq26z8ekjj9rkplr2lwwynskftqc4ytv")
 			require.NoError(t, err)
 
-			actualBids := GetBids(logger, &tc.auctionData, testAddr, tc.margin)
+			actualBids := GetBids(logger, &tc.auctionData, testAddr, NewBidPolicy(tc.margin))
 
 			// add in expected bidder address here to keep test cases simple
 			for i := 0; i < len(tc.expectedBids); i++ {
@@ -109,6 +109,100 @@ func TestGetBids(t *testing.T) {
 	}
 }
 
+func TestGetBidDecisions(t *testing.T) {
+	data := AuctionData{
+		Height: 100,
+		Auctions: []auctiontypes.Auction{
+			&auctiontypes.CollateralAuction{
+				BaseAuction: auctiontypes.BaseAuction{
+					ID:  1,
+					Lot: c("bnb", 1000e8),
+					Bid: c("usdx", 0),
+				},
+				MaxBid:            c("usdx", 220_000e6),
+				CorrespondingDebt: c("debt", 200_000e6),
+			},
+			&auctiontypes.CollateralAuction{
+				BaseAuction: auctiontypes.BaseAuction{
+					ID:  2,
+					Lot: c("xrp", 1000e8),
+					Bid: c("usdx", 0),
+				},
+				MaxBid:            c("usdx", 220_000e6),
+				CorrespondingDebt: c("debt", 200_000e6),
+			},
+		},
+		Assets: map[string]AssetInfo{
+			"usdx": {
+				Price:            d("1.00"),
+				ConversionFactor: sdk.NewInt(1e6),
+			},
+			"bnb": {
+				Price:            d("200"),
+				ConversionFactor: sdk.NewInt(1e8),
+			},
+		},
+		BidIncrement: d("0.01"),
+	}
+
+	testAddr, err := sdk.AccAddressFromBech32("kava10eup8kvq26z8ekjj9rkplr2lwwynskftqc4ytv")
+	require.NoError(t, err)
+
+	decisions := GetBidDecisions(logger, &data, testAddr, NewBidPolicy(d("0.05")))
+	require.Len(t, decisions, 2)
+
+	bid := decisions[0]
+	require.Equal(t, int64(100), bid.Height)
+	require.Empty(t, bid.SkipReason)
+	require.Equal(t, c("usdx", 190_000e6), *bid.Proposed)
+	require.Equal(t, d("200000"), bid.LotUSDValue)
+	require.Equal(t, d("0.05"), *bid.ExpectedMargin)
+
+	skipped := decisions[1]
+	require.Equal(t, errLotAssetMissing.Error(), skipped.SkipReason)
+	require.Nil(t, skipped.Proposed)
+
+	require.Equal(t, AuctionInfos{{ID: 1, Bidder: testAddr, Amount: c("usdx", 190_000e6)}}, decisions.Bids())
+
+	t.Run("denied denom is skipped", func(t *testing.T) {
+		policy := NewBidPolicy(d("0.05"))
+		policy.DeniedDenoms = append(policy.DeniedDenoms, "bnb")
+
+		decisions := GetBidDecisions(logger, &data, testAddr, policy)
+		require.Equal(t, errDenomNotAllowed.Error(), decisions[0].SkipReason)
+		require.Empty(t, decisions.Bids())
+	})
+
+	t.Run("lot above max value is skipped", func(t *testing.T) {
+		policy := NewBidPolicy(d("0.05"))
+		maxLot := d("100000")
+		policy.MaxLotUSDValue = &maxLot
+
+		decisions := GetBidDecisions(logger, &data, testAddr, policy)
+		require.Equal(t, errLotTooLarge.Error(), decisions[0].SkipReason)
+		require.Empty(t, decisions.Bids())
+	})
+
+	t.Run("lot denom margin overrides default", func(t *testing.T) {
+		policy := NewBidPolicy(d("0.05"))
+		policy.LotDenomMargins = map[string]sdk.Dec{"bnb": d("0.2")}
+
+		decisions := GetBidDecisions(logger, &data, testAddr, policy)
+		require.Empty(t, decisions[0].SkipReason)
+		require.True(t, decisions[0].ExpectedMargin.GTE(d("0.2")))
+		require.True(t, decisions[0].Proposed.IsLT(c("usdx", 190_000e6)))
+	})
+
+	t.Run("ladder solver proposes from the ladder", func(t *testing.T) {
+		policy := NewBidPolicy(d("0.05"))
+		policy.Solver = LadderSolver
+
+		decisions := GetBidDecisions(logger, &data, testAddr, policy)
+		require.Equal(t, c("usdx", 176_000e6), *decisions[0].Proposed)
+		require.Equal(t, d("0.12"), *decisions[0].ExpectedMargin)
+	})
+}
+
 func TestCalculateProposedBid(t *testing.T) {
 	assetInfos := map[string]AssetInfo{
 		"usdx": {
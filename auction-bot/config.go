@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/kava-labs/go-tools/signing"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
@@ -14,11 +16,65 @@ import (
 const (
 	kavaChainIdEnvKey       = "KAVA_CHAIN_ID"
 	kavaGrpcUrlEnvKey       = "KAVA_GRPC_URL"
+	kavaRpcUrlEnvKey        = "KAVA_RPC_URL"
 	mnemonicEnvKey          = "KEEPER_MNEMONIC"
 	profitMarginKey         = "BID_MARGIN"
+	bidPolicyFileKey        = "BID_POLICY_FILE"
 	bidIntervalKey          = "BID_INTERVAL"
 	priceOverridesKey       = "PRICE_OVERRIDES"
 	heathCheckListenAddrKey = "HEALTH_CHECK_LISTEN_ADDR"
+	dryRunKey               = "DRY_RUN"
+	decisionLogPathKey      = "DECISION_LOG_PATH"
+	auctionEndWindowKey     = "AUCTION_END_WINDOW"
+	bidScheduleWindowKey    = "BID_SCHEDULE_WINDOW"
+	ledgerPathKey           = "LEDGER_PATH"
+	disposeCollateralKey    = "DISPOSE_COLLATERAL"
+	disposalMaxSlippageKey  = "DISPOSAL_MAX_SLIPPAGE"
+	disposalMinTradeKey     = "DISPOSAL_MIN_TRADE_USD"
+	disposalMaxTradeKey     = "DISPOSAL_MAX_TRADE_USD"
+	disposalDeadlineKey     = "DISPOSAL_DEADLINE"
+	disposalMaxAttemptsKey  = "DISPOSAL_MAX_ATTEMPTS"
+	priceTwapDivergenceKey  = "PRICE_MAX_TWAP_DIVERGENCE"
+	priceMinOraclesKey      = "PRICE_MIN_VALID_ORACLES"
+	priceRefDivergenceKey   = "PRICE_MAX_REFERENCE_DIVERGENCE"
+	referenceSymbolsKey     = "REFERENCE_PRICE_SYMBOLS"
+	feeBaseGasPriceKey      = "FEE_BASE_GAS_PRICE"
+	feeEscalationRateKey    = "FEE_ESCALATION_RATE"
+	feeMaxAmountKey         = "FEE_MAX_AMOUNT"
+	feeCongestedTxsKey      = "FEE_CONGESTED_MEMPOOL_TXS"
+	feeNodeMinPricesKey     = "FEE_NODE_MIN_GAS_PRICES"
+	adminApiTokenKey        = "ADMIN_API_TOKEN"
+	healthMaxCycleAgeKey    = "HEALTH_MAX_CYCLE_AGE"
+	healthMaxBlockAgeKey    = "HEALTH_MAX_BLOCK_AGE"
+	healthMaxInflightKey    = "HEALTH_MAX_INFLIGHT_AGE"
+	healthMinBalancesKey    = "HEALTH_MIN_BALANCES"
+	volatilitySamplesKey    = "VOLATILITY_SAMPLES"
+	volatilityIntervalKey   = "VOLATILITY_SAMPLE_INTERVAL"
+	volatilityConfidenceKey = "VOLATILITY_CONFIDENCE"
+	volatilityMaxHaircutKey = "VOLATILITY_MAX_HAIRCUT"
+	volatilityMaxHoldingKey = "VOLATILITY_MAX_HOLDING_PERIOD"
+)
+
+const (
+	defaultDecisionLogPath     = "decisions.jsonl"
+	defaultAuctionEndWindow    = 2 * time.Minute
+	defaultDisposalMaxSlippage = "0.01"
+	defaultDisposalMinTrade    = "100"
+	defaultDisposalMaxTrade    = "50000"
+	defaultDisposalDeadline    = 10 * time.Minute
+	defaultDisposalAttempts    = 3
+	defaultPriceDivergence     = "0.05"
+	defaultPriceMinOracles     = 1
+	defaultFeeDenom            = "ukava"
+	defaultFeeBaseGasPrice     = "0.05"
+	defaultFeeEscalationRate   = "0.25"
+	defaultFeeMaxAmount        = "1000000"
+	defaultHealthMaxBlockAge   = 2 * time.Minute
+	defaultHealthMaxInflight   = 10 * time.Minute
+	defaultVolInterval         = 600
+	defaultVolConfidence       = "2"
+	defaultVolMaxHaircut       = "0.5"
+	defaultVolMaxHolding       = 24 * time.Hour
 )
 
 // ConfigLoader provides an interface for
@@ -31,11 +87,49 @@ type ConfigLoader interface {
 type Config struct {
 	KavaChainId          string
 	KavaGrpcUrl          string
+	KavaRpcUrl           string
 	KavaBidInterval      time.Duration
 	KavaKeeperMnemonic   string
-	ProfitMargin         sdk.Dec
+	BidPolicy            BidPolicy
 	HeathCheckListenAddr string
 	PriceOverrides       map[string]sdk.Dec
+	DryRun               bool
+	DecisionLogPath      string
+	AuctionEndWindow     time.Duration
+	BidScheduleWindow    time.Duration
+	LedgerPath           string
+	// Disposal is set when won collateral is swapped back into the bid denom
+	Disposal   *DisposalConfig
+	PriceGuard PriceGuardConfig
+	// ReferencePriceSymbols maps denoms to AscendEX symbols used as reference prices
+	ReferencePriceSymbols map[string]string
+	Fee                   signing.FeePolicyConfig
+	// FeeNodeMinGasPrices is set when the node's minimum gas prices are used
+	// as a floor for the base gas price
+	FeeNodeMinGasPrices bool
+	// AdminApiToken is the bearer token for the admin api, which is disabled
+	// when empty
+	AdminApiToken string
+	Health        HealthConfig
+	// Volatility is set when lot values are discounted for volatility until
+	// auctions close
+	Volatility *VolatilityConfig
+}
+
+// HealthConfig sets the limits used by the liveness and readiness checks
+type HealthConfig struct {
+	// MaxCycleAge is the longest time since the last completed bid cycle
+	// before the bot is not live
+	MaxCycleAge time.Duration
+	// MaxBlockAge is the oldest the node's latest block may be for the bot
+	// to be ready
+	MaxBlockAge time.Duration
+	// MaxInflightAge is the longest a request may wait for the signer before
+	// the bot is not live
+	MaxInflightAge time.Duration
+	// MinBalances are the keeper balances required to be ready, unchecked
+	// when empty
+	MinBalances sdk.Coins
 }
 
 // LoadConfig loads key values from a ConfigLoader
@@ -56,12 +150,7 @@ func LoadConfig(loader ConfigLoader) (Config, error) {
 
 	keeperMnemonic := loader.Get(mnemonicEnvKey)
 
-	marginStr := loader.Get(profitMarginKey)
-	if marginStr == "" {
-		return Config{}, fmt.Errorf("%s not set", profitMarginKey)
-	}
-
-	marginDec, err := sdk.NewDecFromStr(marginStr)
+	bidPolicy, err := loadBidPolicy(loader)
 	if err != nil {
 		return Config{}, err
 	}
@@ -83,17 +172,403 @@ func LoadConfig(loader ConfigLoader) (Config, error) {
 		}
 	}
 
+	dryRun := false
+	if raw := loader.Get(dryRunKey); raw != "" {
+		dryRun, err = strconv.ParseBool(raw)
+		if err != nil {
+			return Config{}, fmt.Errorf("%s invalid bool: %v", dryRunKey, err)
+		}
+	}
+
+	// decisions are always logged in dry run mode, otherwise only when a path is set
+	decisionLogPath := loader.Get(decisionLogPathKey)
+	if decisionLogPath == "" && dryRun {
+		decisionLogPath = defaultDecisionLogPath
+	}
+
+	auctionEndWindow := defaultAuctionEndWindow
+	if raw := loader.Get(auctionEndWindowKey); raw != "" {
+		auctionEndWindow, err = time.ParseDuration(raw)
+		if err != nil {
+			return Config{}, fmt.Errorf("%s invalid duration: %v", auctionEndWindowKey, err)
+		}
+	}
+
+	// bids are sent immediately unless a schedule window is set
+	var bidScheduleWindow time.Duration
+	if raw := loader.Get(bidScheduleWindowKey); raw != "" {
+		bidScheduleWindow, err = time.ParseDuration(raw)
+		if err != nil {
+			return Config{}, fmt.Errorf("%s invalid duration: %v", bidScheduleWindowKey, err)
+		}
+		if bidScheduleWindow <= 0 {
+			return Config{}, fmt.Errorf("%s must be positive", bidScheduleWindowKey)
+		}
+	}
+
+	ledgerPath := loader.Get(ledgerPathKey)
+
+	var disposal *DisposalConfig
+	if raw := loader.Get(disposeCollateralKey); raw != "" {
+		enabled, err := strconv.ParseBool(raw)
+		if err != nil {
+			return Config{}, fmt.Errorf("%s invalid bool: %v", disposeCollateralKey, err)
+		}
+
+		if enabled {
+			// wins are only known once resolved by the ledger
+			if ledgerPath == "" {
+				return Config{}, fmt.Errorf("%s must be set when %s is enabled", ledgerPathKey, disposeCollateralKey)
+			}
+
+			disposalConfig, err := loadDisposalConfig(loader)
+			if err != nil {
+				return Config{}, err
+			}
+			disposal = &disposalConfig
+		}
+	}
+
+	priceGuard, err := loadPriceGuardConfig(loader)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var referencePriceSymbols map[string]string
+	if raw := loader.Get(referenceSymbolsKey); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &referencePriceSymbols); err != nil {
+			return Config{}, fmt.Errorf("%s invalid json: %v", referenceSymbolsKey, err)
+		}
+	}
+
+	fee, err := loadFeePolicyConfig(loader)
+	if err != nil {
+		return Config{}, err
+	}
+	// the mempool is read from the rpc endpoint
+	if fee.CongestedMempoolTxs > 0 && loader.Get(kavaRpcUrlEnvKey) == "" {
+		return Config{}, fmt.Errorf("%s must be set when %s is set", kavaRpcUrlEnvKey, feeCongestedTxsKey)
+	}
+
+	feeNodeMinGasPrices := false
+	if raw := loader.Get(feeNodeMinPricesKey); raw != "" {
+		feeNodeMinGasPrices, err = strconv.ParseBool(raw)
+		if err != nil {
+			return Config{}, fmt.Errorf("%s invalid bool: %v", feeNodeMinPricesKey, err)
+		}
+	}
+
+	health, err := loadHealthConfig(loader, keeperBidInterval)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var volatility *VolatilityConfig
+	if loader.Get(volatilitySamplesKey) != "" {
+		volatilityConfig, err := loadVolatilityConfig(loader)
+		if err != nil {
+			return Config{}, err
+		}
+		volatility = &volatilityConfig
+	}
+
 	return Config{
-		KavaChainId:          chainId,
-		KavaGrpcUrl:          grpcURL,
-		KavaBidInterval:      keeperBidInterval,
-		KavaKeeperMnemonic:   keeperMnemonic,
-		ProfitMargin:         marginDec,
-		HeathCheckListenAddr: healthCheckListenAddr,
-		PriceOverrides:       priceOverrides,
+		KavaChainId:           chainId,
+		KavaGrpcUrl:           grpcURL,
+		KavaRpcUrl:            loader.Get(kavaRpcUrlEnvKey),
+		KavaBidInterval:       keeperBidInterval,
+		KavaKeeperMnemonic:    keeperMnemonic,
+		BidPolicy:             bidPolicy,
+		HeathCheckListenAddr:  healthCheckListenAddr,
+		PriceOverrides:        priceOverrides,
+		DryRun:                dryRun,
+		DecisionLogPath:       decisionLogPath,
+		AuctionEndWindow:      auctionEndWindow,
+		BidScheduleWindow:     bidScheduleWindow,
+		LedgerPath:            ledgerPath,
+		Disposal:              disposal,
+		PriceGuard:            priceGuard,
+		ReferencePriceSymbols: referencePriceSymbols,
+		Fee:                   fee,
+		FeeNodeMinGasPrices:   feeNodeMinGasPrices,
+		AdminApiToken:         loader.Get(adminApiTokenKey),
+		Health:                health,
+		Volatility:            volatility,
+	}, nil
+}
+
+// loadBidPolicy creates a policy from the configured margin, overridden by
+// the policy file if one is set, and validates it
+func loadBidPolicy(loader ConfigLoader) (BidPolicy, error) {
+	marginStr := loader.Get(profitMarginKey)
+	if marginStr == "" {
+		return BidPolicy{}, fmt.Errorf("%s not set", profitMarginKey)
+	}
+
+	marginDec, err := sdk.NewDecFromStr(marginStr)
+	if err != nil {
+		return BidPolicy{}, err
+	}
+
+	policy := NewBidPolicy(marginDec)
+	if path := loader.Get(bidPolicyFileKey); path != "" {
+		policy, err = LoadBidPolicy(path, policy)
+		if err != nil {
+			return BidPolicy{}, err
+		}
+	}
+
+	if err := policy.Validate(); err != nil {
+		return BidPolicy{}, fmt.Errorf("invalid bid policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// loadDisposalConfig loads swap limits for selling won collateral, using
+// defaults for any that are not set
+func loadDisposalConfig(loader ConfigLoader) (DisposalConfig, error) {
+	maxSlippage, err := decOrDefault(loader, disposalMaxSlippageKey, defaultDisposalMaxSlippage)
+	if err != nil {
+		return DisposalConfig{}, err
+	}
+
+	minTrade, err := decOrDefault(loader, disposalMinTradeKey, defaultDisposalMinTrade)
+	if err != nil {
+		return DisposalConfig{}, err
+	}
+
+	maxTrade, err := decOrDefault(loader, disposalMaxTradeKey, defaultDisposalMaxTrade)
+	if err != nil {
+		return DisposalConfig{}, err
+	}
+
+	deadline := defaultDisposalDeadline
+	if raw := loader.Get(disposalDeadlineKey); raw != "" {
+		deadline, err = time.ParseDuration(raw)
+		if err != nil {
+			return DisposalConfig{}, fmt.Errorf("%s invalid duration: %v", disposalDeadlineKey, err)
+		}
+	}
+
+	maxAttempts := defaultDisposalAttempts
+	if raw := loader.Get(disposalMaxAttemptsKey); raw != "" {
+		maxAttempts, err = strconv.Atoi(raw)
+		if err != nil {
+			return DisposalConfig{}, fmt.Errorf("%s invalid: %v", disposalMaxAttemptsKey, err)
+		}
+	}
+
+	config := DisposalConfig{
+		MaxSlippage:      maxSlippage,
+		MinTradeUSDValue: minTrade,
+		MaxTradeUSDValue: maxTrade,
+		Deadline:         deadline,
+		MaxAttempts:      maxAttempts,
+	}
+	if err := config.Validate(); err != nil {
+		return DisposalConfig{}, fmt.Errorf("invalid disposal config: %w", err)
+	}
+
+	return config, nil
+}
+
+// loadPriceGuardConfig loads the limits prices must be within to be bid with,
+// using defaults for any that are not set
+func loadPriceGuardConfig(loader ConfigLoader) (PriceGuardConfig, error) {
+	maxTwapDivergence, err := decOrDefault(loader, priceTwapDivergenceKey, defaultPriceDivergence)
+	if err != nil {
+		return PriceGuardConfig{}, err
+	}
+	if !maxTwapDivergence.IsPositive() {
+		return PriceGuardConfig{}, fmt.Errorf("%s must be positive", priceTwapDivergenceKey)
+	}
+
+	maxReferenceDivergence, err := decOrDefault(loader, priceRefDivergenceKey, defaultPriceDivergence)
+	if err != nil {
+		return PriceGuardConfig{}, err
+	}
+	if !maxReferenceDivergence.IsPositive() {
+		return PriceGuardConfig{}, fmt.Errorf("%s must be positive", priceRefDivergenceKey)
+	}
+
+	minValidOracles := defaultPriceMinOracles
+	if raw := loader.Get(priceMinOraclesKey); raw != "" {
+		minValidOracles, err = strconv.Atoi(raw)
+		if err != nil {
+			return PriceGuardConfig{}, fmt.Errorf("%s invalid: %v", priceMinOraclesKey, err)
+		}
+		if minValidOracles < 0 {
+			return PriceGuardConfig{}, fmt.Errorf("%s must not be negative", priceMinOraclesKey)
+		}
+	}
+
+	return PriceGuardConfig{
+		MaxTwapDivergence:      maxTwapDivergence,
+		MinValidOracles:        minValidOracles,
+		MaxReferenceDivergence: maxReferenceDivergence,
+	}, nil
+}
+
+// loadFeePolicyConfig loads the gas price, escalation and fee cap used to
+// price txs, using defaults for any that are not set
+func loadFeePolicyConfig(loader ConfigLoader) (signing.FeePolicyConfig, error) {
+	baseGasPrice, err := decOrDefault(loader, feeBaseGasPriceKey, defaultFeeBaseGasPrice)
+	if err != nil {
+		return signing.FeePolicyConfig{}, err
+	}
+
+	escalationRate, err := decOrDefault(loader, feeEscalationRateKey, defaultFeeEscalationRate)
+	if err != nil {
+		return signing.FeePolicyConfig{}, err
+	}
+
+	rawMaxFee := loader.Get(feeMaxAmountKey)
+	if rawMaxFee == "" {
+		rawMaxFee = defaultFeeMaxAmount
+	}
+	maxFee, ok := sdk.NewIntFromString(rawMaxFee)
+	if !ok {
+		return signing.FeePolicyConfig{}, fmt.Errorf("%s invalid integer: %s", feeMaxAmountKey, rawMaxFee)
+	}
+
+	// congestion is not checked unless a threshold is set
+	congestedMempoolTxs := 0
+	if raw := loader.Get(feeCongestedTxsKey); raw != "" {
+		congestedMempoolTxs, err = strconv.Atoi(raw)
+		if err != nil {
+			return signing.FeePolicyConfig{}, fmt.Errorf("%s invalid: %v", feeCongestedTxsKey, err)
+		}
+	}
+
+	config := signing.FeePolicyConfig{
+		Denom:               defaultFeeDenom,
+		BaseGasPrice:        baseGasPrice,
+		EscalationRate:      escalationRate,
+		MaxFee:              maxFee,
+		CongestedMempoolTxs: congestedMempoolTxs,
+	}
+	if err := config.Validate(); err != nil {
+		return signing.FeePolicyConfig{}, fmt.Errorf("invalid fee policy: %w", err)
+	}
+
+	return config, nil
+}
+
+// loadHealthConfig loads the health check limits, by default a bot is not live
+// if three bid intervals pass without a completed cycle
+func loadHealthConfig(loader ConfigLoader, bidInterval time.Duration) (HealthConfig, error) {
+	maxCycleAge, err := durationOrDefault(loader, healthMaxCycleAgeKey, 3*bidInterval)
+	if err != nil {
+		return HealthConfig{}, err
+	}
+
+	maxBlockAge, err := durationOrDefault(loader, healthMaxBlockAgeKey, defaultHealthMaxBlockAge)
+	if err != nil {
+		return HealthConfig{}, err
+	}
+
+	maxInflightAge, err := durationOrDefault(loader, healthMaxInflightKey, defaultHealthMaxInflight)
+	if err != nil {
+		return HealthConfig{}, err
+	}
+
+	var minBalances sdk.Coins
+	if raw := loader.Get(healthMinBalancesKey); raw != "" {
+		minBalances, err = sdk.ParseCoinsNormalized(raw)
+		if err != nil {
+			return HealthConfig{}, fmt.Errorf("%s invalid coins: %v", healthMinBalancesKey, err)
+		}
+	}
+
+	return HealthConfig{
+		MaxCycleAge:    maxCycleAge,
+		MaxBlockAge:    maxBlockAge,
+		MaxInflightAge: maxInflightAge,
+		MinBalances:    minBalances,
 	}, nil
 }
 
+// decOrDefault parses the decimal set for key, or fallback when it is not set
+func decOrDefault(loader ConfigLoader, key, fallback string) (sdk.Dec, error) {
+	raw := loader.Get(key)
+	if raw == "" {
+		raw = fallback
+	}
+
+	value, err := sdk.NewDecFromStr(raw)
+	if err != nil {
+		return sdk.Dec{}, fmt.Errorf("%s invalid decimal: %v", key, err)
+	}
+	return value, nil
+}
+
+// durationOrDefault parses the positive duration set for key, or returns
+// fallback when it is not set
+func durationOrDefault(loader ConfigLoader, key string, fallback time.Duration) (time.Duration, error) {
+	raw := loader.Get(key)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	value, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%s invalid duration: %v", key, err)
+	}
+	if value <= 0 {
+		return 0, fmt.Errorf("%s must be positive", key)
+	}
+	return value, nil
+}
+
+// loadVolatilityConfig loads how price history is sampled and lot values
+// discounted, using defaults for any that are not set except the samples
+func loadVolatilityConfig(loader ConfigLoader) (VolatilityConfig, error) {
+	samples, err := strconv.Atoi(loader.Get(volatilitySamplesKey))
+	if err != nil {
+		return VolatilityConfig{}, fmt.Errorf("%s invalid: %v", volatilitySamplesKey, err)
+	}
+
+	sampleInterval := int64(defaultVolInterval)
+	if raw := loader.Get(volatilityIntervalKey); raw != "" {
+		sampleInterval, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return VolatilityConfig{}, fmt.Errorf("%s invalid: %v", volatilityIntervalKey, err)
+		}
+	}
+
+	confidence, err := decOrDefault(loader, volatilityConfidenceKey, defaultVolConfidence)
+	if err != nil {
+		return VolatilityConfig{}, err
+	}
+
+	maxHaircut, err := decOrDefault(loader, volatilityMaxHaircutKey, defaultVolMaxHaircut)
+	if err != nil {
+		return VolatilityConfig{}, err
+	}
+
+	maxHolding := defaultVolMaxHolding
+	if raw := loader.Get(volatilityMaxHoldingKey); raw != "" {
+		maxHolding, err = time.ParseDuration(raw)
+		if err != nil {
+			return VolatilityConfig{}, fmt.Errorf("%s invalid duration: %v", volatilityMaxHoldingKey, err)
+		}
+	}
+
+	config := VolatilityConfig{
+		Samples:          samples,
+		SampleInterval:   sampleInterval,
+		Confidence:       confidence,
+		MaxHaircut:       maxHaircut,
+		MaxHoldingPeriod: maxHolding,
+	}
+	if err := config.Validate(); err != nil {
+		return VolatilityConfig{}, fmt.Errorf("invalid volatility config: %w", err)
+	}
+
+	return config, nil
+}
+
 // EnvLoader loads keys from os environment
 type EnvLoader struct{}
 
@@ -101,3 +576,139 @@ type EnvLoader struct{}
 func (l *EnvLoader) Get(key string) string {
 	return os.Getenv(key)
 }
+
+const (
+	backtestStartHeightKey    = "BACKTEST_START_HEIGHT"
+	backtestEndHeightKey      = "BACKTEST_END_HEIGHT"
+	backtestSampleIntervalKey = "BACKTEST_SAMPLE_INTERVAL"
+	backtestStrategyKey       = "BACKTEST_STRATEGY"
+	backtestFixturesDirKey    = "BACKTEST_FIXTURES_DIR"
+	backtestRecordDirKey      = "BACKTEST_RECORD_DIR"
+	backtestReportPathKey     = "BACKTEST_REPORT_PATH"
+)
+
+const (
+	defaultBacktestSampleInterval = 100
+	defaultBacktestReportPath     = "backtest.csv"
+)
+
+// BacktestConfig provides configuration for replaying historical auctions
+type BacktestConfig struct {
+	KavaGrpcUrl    string
+	StartHeight    int64
+	EndHeight      int64
+	SampleInterval int64
+	BidPolicy      BidPolicy
+	Strategy       BacktestStrategy
+	FixturesDir    string
+	RecordDir      string
+	ReportPath     string
+}
+
+// LoadBacktestConfig loads key values from a ConfigLoader
+// and returns a new BacktestConfig
+func LoadBacktestConfig(loader ConfigLoader) (BacktestConfig, error) {
+	err := godotenv.Load()
+	if err != nil {
+		fmt.Printf(".env not found, attempting to proceed with available env variables\n")
+	}
+
+	// fixtures replace the archive node, so grpc is only required without them
+	fixturesDir := loader.Get(backtestFixturesDirKey)
+	grpcURL := loader.Get(kavaGrpcUrlEnvKey)
+	if grpcURL == "" && fixturesDir == "" {
+		return BacktestConfig{}, fmt.Errorf("one of %s or %s must be set", kavaGrpcUrlEnvKey, backtestFixturesDirKey)
+	}
+
+	startHeight, err := strconv.ParseInt(loader.Get(backtestStartHeightKey), 10, 64)
+	if err != nil {
+		return BacktestConfig{}, fmt.Errorf("%s invalid: %v", backtestStartHeightKey, err)
+	}
+
+	endHeight, err := strconv.ParseInt(loader.Get(backtestEndHeightKey), 10, 64)
+	if err != nil {
+		return BacktestConfig{}, fmt.Errorf("%s invalid: %v", backtestEndHeightKey, err)
+	}
+
+	if endHeight < startHeight {
+		return BacktestConfig{}, fmt.Errorf("%s must not be less than %s", backtestEndHeightKey, backtestStartHeightKey)
+	}
+
+	sampleInterval := int64(defaultBacktestSampleInterval)
+	if raw := loader.Get(backtestSampleIntervalKey); raw != "" {
+		sampleInterval, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return BacktestConfig{}, fmt.Errorf("%s invalid: %v", backtestSampleIntervalKey, err)
+		}
+		if sampleInterval <= 0 {
+			return BacktestConfig{}, fmt.Errorf("%s must be positive", backtestSampleIntervalKey)
+		}
+	}
+
+	bidPolicy, err := loadBidPolicy(loader)
+	if err != nil {
+		return BacktestConfig{}, err
+	}
+
+	strategy := ProposalStrategy
+	if raw := loader.Get(backtestStrategyKey); raw != "" {
+		strategy = BacktestStrategy(raw)
+		if err := strategy.Validate(); err != nil {
+			return BacktestConfig{}, fmt.Errorf("%s invalid: %v", backtestStrategyKey, err)
+		}
+	}
+
+	reportPath := loader.Get(backtestReportPathKey)
+	if reportPath == "" {
+		reportPath = defaultBacktestReportPath
+	}
+
+	return BacktestConfig{
+		KavaGrpcUrl:    grpcURL,
+		StartHeight:    startHeight,
+		EndHeight:      endHeight,
+		SampleInterval: sampleInterval,
+		BidPolicy:      bidPolicy,
+		Strategy:       strategy,
+		FixturesDir:    fixturesDir,
+		RecordDir:      loader.Get(backtestRecordDirKey),
+		ReportPath:     reportPath,
+	}, nil
+}
+
+const pnlPeriodKey = "PNL_PERIOD"
+
+const defaultLedgerPath = "ledger.jsonl"
+
+// PnlConfig provides configuration for reporting profit from the ledger
+type PnlConfig struct {
+	LedgerPath string
+	Period     PnlPeriod
+}
+
+// LoadPnlConfig loads key values from a ConfigLoader
+// and returns a new PnlConfig
+func LoadPnlConfig(loader ConfigLoader) (PnlConfig, error) {
+	err := godotenv.Load()
+	if err != nil {
+		fmt.Printf(".env not found, attempting to proceed with available env variables\n")
+	}
+
+	ledgerPath := loader.Get(ledgerPathKey)
+	if ledgerPath == "" {
+		ledgerPath = defaultLedgerPath
+	}
+
+	period := PnlPeriodMonth
+	if raw := loader.Get(pnlPeriodKey); raw != "" {
+		period = PnlPeriod(raw)
+		if err := period.Validate(); err != nil {
+			return PnlConfig{}, fmt.Errorf("%s invalid: %v", pnlPeriodKey, err)
+		}
+	}
+
+	return PnlConfig{
+		LedgerPath: ledgerPath,
+		Period:     period,
+	}, nil
+}
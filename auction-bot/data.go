@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"math/big"
 	"strconv"
+	"time"
 
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
 	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
@@ -19,12 +21,20 @@ import (
 type AssetInfo struct {
 	Price            sdk.Dec
 	ConversionFactor sdk.Int
+	SpotMarketID     string
 }
 
 type AuctionData struct {
+	Height       int64
+	BlockTime    time.Time
 	Assets       map[string]AssetInfo
 	Auctions     []auctiontypes.Auction
 	BidIncrement sdk.Dec
+	// PriceErrors are set for assets with prices that should not be bid with
+	PriceErrors map[string]error
+	// LotHaircuts are the fractions of lot values discounted for volatility
+	// until close, by auction id
+	LotHaircuts map[uint64]sdk.Dec
 }
 
 func GetAuctionData(client GrpcClient, cdc codec.Codec) (*AuctionData, error) {
@@ -34,22 +44,60 @@ func GetAuctionData(client GrpcClient, cdc codec.Codec) (*AuctionData, error) {
 		return nil, err
 	}
 
-	pricesRes, err := client.Pricefeed.Prices(ctxAtHeight(latestHeight), &types.QueryPricesRequest{})
+	return GetAuctionDataAtHeight(client, latestHeight)
+}
+
+// GetAuctionDataAtHeight fetches auctions and asset info at a specific height,
+// which requires an archive node for heights that have been pruned
+func GetAuctionDataAtHeight(client GrpcClient, height int64) (*AuctionData, error) {
+	blockTime, err := GetBlockTimeAtHeight(client, height)
+	if err != nil {
+		return nil, err
+	}
+
+	assetInfo, err := GetAssetInfoAtHeight(client, height)
 	if err != nil {
 		return nil, err
 	}
 
-	auctions, err := client.AllAuctions(ctxAtHeight(latestHeight))
+	auctions, err := client.AllAuctions(ctxAtHeight(height))
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuctionData{
+		Height:       height,
+		BlockTime:    blockTime,
+		Assets:       assetInfo,
+		Auctions:     auctions,
+		BidIncrement: sdk.MustNewDecFromStr("0.01"), // TODO could fetch increment from chain
+	}, nil
+}
+
+// GetBlockTimeAtHeight fetches the time of the block at a height
+func GetBlockTimeAtHeight(client GrpcClient, height int64) (time.Time, error) {
+	blockRes, err := client.Tm.GetBlockByHeight(context.Background(), &tmservice.GetBlockByHeightRequest{Height: height})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to fetch block %d: %w", height, err)
+	}
+
+	return blockRes.Block.Header.Time, nil
+}
+
+// GetAssetInfoAtHeight fetches prices and conversion factors for all cdp
+// and hard markets at a height
+func GetAssetInfoAtHeight(client GrpcClient, height int64) (map[string]AssetInfo, error) {
+	pricesRes, err := client.Pricefeed.Prices(ctxAtHeight(height), &types.QueryPricesRequest{})
 	if err != nil {
 		return nil, err
 	}
 
-	cdpParamsRes, err := client.Cdp.Params(ctxAtHeight(latestHeight), &cdptypes.QueryParamsRequest{})
+	cdpParamsRes, err := client.Cdp.Params(ctxAtHeight(height), &cdptypes.QueryParamsRequest{})
 	if err != nil {
 		return nil, err
 	}
 
-	hardParamsRes, err := client.Hard.Params(ctxAtHeight(latestHeight), &hardtypes.QueryParamsRequest{})
+	hardParamsRes, err := client.Hard.Params(ctxAtHeight(height), &hardtypes.QueryParamsRequest{})
 	if err != nil {
 		return nil, err
 	}
@@ -73,14 +121,11 @@ func GetAuctionData(client GrpcClient, cdc codec.Codec) (*AuctionData, error) {
 		assetInfo[market.Denom] = AssetInfo{
 			Price:            price,
 			ConversionFactor: market.ConversionFactor,
+			SpotMarketID:     market.SpotMarketID,
 		}
 	}
 
-	return &AuctionData{
-		Assets:       assetInfo,
-		Auctions:     auctions,
-		BidIncrement: sdk.MustNewDecFromStr("0.01"), // TODO could fetch increment from chain
-	}, nil
+	return assetInfo, nil
 }
 
 func ctxAtHeight(height int64) context.Context {
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	auctiontypes "github.com/kava-labs/kava/x/auction/types"
+)
+
+// BidDecision records the outcome of evaluating a single auction, used to
+// audit bidding behavior without sending any bids
+type BidDecision struct {
+	Height           int64      `json:"height"`
+	AuctionID        uint64     `json:"auction_id"`
+	AuctionType      string     `json:"auction_type"`
+	Phase            string     `json:"phase"`
+	Lot              sdk.Coin   `json:"lot"`
+	Bid              sdk.Coin   `json:"bid"`
+	MaxBid           *sdk.Coin  `json:"max_bid,omitempty"`
+	EndTime          time.Time  `json:"end_time"`
+	LotUSDValue      sdk.Dec    `json:"lot_usd_value"`
+	LotHaircut       *sdk.Dec   `json:"lot_haircut,omitempty"`
+	BidUSDValue      sdk.Dec    `json:"bid_usd_value"`
+	Proposed         *sdk.Coin  `json:"proposed,omitempty"`
+	ProposedUSDValue *sdk.Dec   `json:"proposed_usd_value,omitempty"`
+	ExpectedMargin   *sdk.Dec   `json:"expected_margin,omitempty"`
+	SkipReason       string     `json:"skip_reason,omitempty"`
+	HeldUntil        *time.Time `json:"held_until,omitempty"`
+
+	bidInfo *AuctionInfo
+}
+
+type BidDecisions []BidDecision
+
+// NewBidDecision creates a decision for an auction populated with the current
+// lot and bid, valued in USD when asset info is available
+func NewBidDecision(data *AuctionData, auction auctiontypes.Auction) BidDecision {
+	decision := BidDecision{
+		Height:      data.Height,
+		AuctionID:   auction.GetID(),
+		AuctionType: auction.GetType(),
+		Phase:       auction.GetPhase(),
+		Lot:         auction.GetLot(),
+		Bid:         auction.GetBid(),
+		EndTime:     auction.GetEndTime(),
+		LotUSDValue: sdk.ZeroDec(),
+		BidUSDValue: sdk.ZeroDec(),
+	}
+
+	if collateralAuction, ok := auction.(*auctiontypes.CollateralAuction); ok {
+		maxBid := collateralAuction.MaxBid
+		decision.MaxBid = &maxBid
+	}
+
+	if assetInfo, ok := data.Assets[decision.Lot.Denom]; ok {
+		decision.LotUSDValue = calculateUSDValue(decision.Lot, assetInfo)
+	}
+	if assetInfo, ok := data.Assets[decision.Bid.Denom]; ok {
+		decision.BidUSDValue = calculateUSDValue(decision.Bid, assetInfo)
+	}
+
+	return decision
+}
+
+// Skip returns a copy of the decision marked as skipped with the provided reason
+func (d BidDecision) Skip(reason error) BidDecision {
+	d.SkipReason = reason.Error()
+	return d
+}
+
+// Haircut returns a copy of the decision with the fraction of the lot value
+// discounted for volatility until close
+func (d BidDecision) Haircut(haircut sdk.Dec) BidDecision {
+	d.LotHaircut = &haircut
+	return d
+}
+
+// Propose returns a copy of the decision with the proposed bid and the margin
+// expected if the auction is won with it
+//
+// A proposal in the lot denom is a reverse bid, so the margin is taken against
+// the current bid, otherwise it is a forward bid and taken against the lot.
+// Assets should be those the proposal was made with, so the margin of a
+// reverse bid is after any haircut, and the lot value is discounted by the
+// haircut for forward bids.
+func (d BidDecision) Propose(bidInfo AuctionInfo, assets map[string]AssetInfo) BidDecision {
+	proposed := bidInfo.Amount
+	proposedUSDValue := calculateUSDValue(proposed, assets[proposed.Denom])
+
+	lotUSDValue := d.LotUSDValue
+	if d.LotHaircut != nil {
+		lotUSDValue = lotUSDValue.Mul(sdk.OneDec().Sub(*d.LotHaircut))
+	}
+
+	var margin sdk.Dec
+	if proposed.Denom == d.Lot.Denom {
+		margin = sdk.OneDec().Sub(d.BidUSDValue.Quo(proposedUSDValue))
+	} else {
+		margin = sdk.OneDec().Sub(proposedUSDValue.Quo(lotUSDValue))
+	}
+
+	d.Proposed = &proposed
+	d.ProposedUSDValue = &proposedUSDValue
+	d.ExpectedMargin = &margin
+	d.bidInfo = &bidInfo
+
+	return d
+}
+
+// Hold returns a copy of the decision with the proposed bid held until the
+// provided chain time
+func (d BidDecision) Hold(until time.Time) BidDecision {
+	d.HeldUntil = &until
+	return d
+}
+
+// Bids returns the bids for all decisions that were not skipped or held
+func (ds BidDecisions) Bids() AuctionInfos {
+	var bids AuctionInfos
+	for _, d := range ds {
+		if d.bidInfo == nil || d.HeldUntil != nil {
+			continue
+		}
+		bids = append(bids, *d.bidInfo)
+	}
+
+	return bids
+}
+
+// DecisionLog appends bid decisions to a file as JSON lines
+type DecisionLog struct {
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// OpenDecisionLog opens or creates the decision log at path for appending
+func OpenDecisionLog(path string) (*DecisionLog, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open decision log: %w", err)
+	}
+
+	return &DecisionLog{
+		file:    file,
+		encoder: json.NewEncoder(file),
+	}, nil
+}
+
+// Write appends one line per decision
+func (l *DecisionLog) Write(decisions BidDecisions) error {
+	for _, decision := range decisions {
+		if err := l.encoder.Encode(decision); err != nil {
+			return fmt.Errorf("failed to write decision for auction %d: %w", decision.AuctionID, err)
+		}
+	}
+
+	return nil
+}
+
+// Close closes the underlying file
+func (l *DecisionLog) Close() error {
+	return l.file.Close()
+}
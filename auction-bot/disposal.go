@@ -0,0 +1,354 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	swaptypes "github.com/kava-labs/kava/x/swap/types"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+)
+
+// DisposalConfig limits the swaps used to sell won collateral
+type DisposalConfig struct {
+	// MaxSlippage is the fraction the swap output may be below the output
+	// expected from pricefeed prices
+	MaxSlippage sdk.Dec
+	// MinTradeUSDValue is the smallest swap sent, smaller amounts are held
+	// until more collateral of the same denom is won
+	MinTradeUSDValue sdk.Dec
+	// MaxTradeUSDValue is the largest swap sent per cycle, larger amounts are
+	// sold over several cycles
+	MaxTradeUSDValue sdk.Dec
+	// Deadline is how long after the current block time a swap is valid
+	Deadline time.Duration
+	// MaxAttempts is the number of failed swaps of a pair after which its
+	// collateral is parked instead of being sold again
+	MaxAttempts int
+}
+
+// Validate returns an error if the slippage is outside [0, 1) or the trade
+// sizes are not positive and ordered
+func (c DisposalConfig) Validate() error {
+	if c.MaxSlippage.IsNil() || c.MaxSlippage.IsNegative() || c.MaxSlippage.GTE(sdk.OneDec()) {
+		return fmt.Errorf("max slippage must be in [0, 1), got %s", c.MaxSlippage)
+	}
+	if c.MinTradeUSDValue.IsNil() || !c.MinTradeUSDValue.IsPositive() {
+		return fmt.Errorf("min trade usd value must be positive, got %s", c.MinTradeUSDValue)
+	}
+	if c.MaxTradeUSDValue.IsNil() || c.MaxTradeUSDValue.LT(c.MinTradeUSDValue) {
+		return fmt.Errorf("max trade usd value must not be less than min, got %s", c.MaxTradeUSDValue)
+	}
+	if c.Deadline <= 0 {
+		return fmt.Errorf("deadline must be positive, got %s", c.Deadline)
+	}
+	if c.MaxAttempts <= 0 {
+		return fmt.Errorf("max attempts must be positive, got %d", c.MaxAttempts)
+	}
+
+	return nil
+}
+
+// Disposal is an amount of won collateral to sell for the target denom
+type Disposal struct {
+	Sell   sdk.Coin
+	Target string
+}
+
+// Disposer queues collateral from won auctions and creates swaps selling it
+// back into the denom that was bid
+//
+// Only amounts received from auctions are sold, so other balances held by the
+// keeper are never swapped. Pairs whose swaps keep failing, and swaps whose
+// outcome is unknown, are parked with a warning and left for an operator to
+// sell. The queue is held in memory and is lost on restart.
+type Disposer struct {
+	config DisposalConfig
+	keeper sdk.AccAddress
+
+	mu          sync.Mutex
+	queue       map[[2]string]sdk.Int
+	failures    map[[2]string]int
+	parked      map[[2]string]sdk.Int
+	parkedPairs map[[2]string]bool
+}
+
+func NewDisposer(config DisposalConfig, keeper sdk.AccAddress) *Disposer {
+	return &Disposer{
+		config:      config,
+		keeper:      keeper,
+		queue:       make(map[[2]string]sdk.Int),
+		failures:    make(map[[2]string]int),
+		parked:      make(map[[2]string]sdk.Int),
+		parkedPairs: make(map[[2]string]bool),
+	}
+}
+
+// AddWon queues the lot of a won auction for sale into the denom that was paid
+func (d *Disposer) AddWon(closed LedgerClose) {
+	if !closed.Won || closed.Received.Denom == closed.Paid.Denom {
+		return
+	}
+
+	d.Add(Disposal{Sell: closed.Received, Target: closed.Paid.Denom})
+}
+
+// Add queues a disposal, amounts for the same denoms are combined and
+// amounts for a parked pair are parked with it
+func (d *Disposer) Add(disposal Disposal) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := disposal.key()
+	if d.parkedPairs[key] {
+		addAmount(d.parked, key, disposal.Sell.Amount)
+		return
+	}
+	addAmount(d.queue, key, disposal.Sell.Amount)
+}
+
+// Failed queues a disposal whose swap failed to be sold again, parking its
+// pair once swaps of the pair have failed the max attempts
+func (d *Disposer) Failed(logger zerolog.Logger, disposal Disposal, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := disposal.key()
+	if d.parkedPairs[key] {
+		addAmount(d.parked, key, disposal.Sell.Amount)
+		return
+	}
+	addAmount(d.queue, key, disposal.Sell.Amount)
+
+	d.failures[key]++
+	if d.failures[key] < d.config.MaxAttempts {
+		logger.Warn().
+			Err(err).
+			Str("sell", disposal.Sell.String()).
+			Str("target", disposal.Target).
+			Int("attempts", d.failures[key]).
+			Msg("disposal failed, queued again")
+		return
+	}
+
+	d.parkedPairs[key] = true
+	addAmount(d.parked, key, d.queue[key])
+	delete(d.queue, key)
+	delete(d.failures, key)
+
+	logger.Warn().
+		Err(err).
+		Str("sell", sdk.NewCoin(key[0], d.parked[key]).String()).
+		Str("target", key[1]).
+		Int("attempts", d.config.MaxAttempts).
+		Msg("disposal failed too many times, parking collateral")
+}
+
+// Succeeded clears the failed attempts of the pair of a disposal that was sold
+func (d *Disposer) Succeeded(disposal Disposal) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.failures, disposal.key())
+}
+
+// Park holds a disposal without selling it, used when it is not known if its
+// swap was delivered so the same collateral is not sold twice. Later
+// disposals of the pair are still sold.
+func (d *Disposer) Park(logger zerolog.Logger, disposal Disposal, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := disposal.key()
+	addAmount(d.parked, key, disposal.Sell.Amount)
+
+	logger.Warn().
+		Err(err).
+		Str("sell", disposal.Sell.String()).
+		Str("target", disposal.Target).
+		Msg("disposal outcome unknown, parking collateral")
+}
+
+// Confirm checks the delivered tx of a swap, queuing the disposal again if
+// the swap failed and parking it if the tx can not be fetched
+//
+// The signer responds once a tx is committed, a swap can still fail when it
+// is delivered, for example when the pool output is below the slippage bound.
+func (d *Disposer) Confirm(ctx context.Context, logger zerolog.Logger, client TxClient, disposal Disposal, txHash string) {
+	res, err := FetchTx(ctx, client, txHash)
+	if err != nil {
+		d.Park(logger, disposal, err)
+		return
+	}
+
+	if res.Code != 0 {
+		d.Failed(logger, disposal, fmt.Errorf("tx %s failed with code %d: %s", res.TxHash, res.Code, res.RawLog))
+		return
+	}
+
+	d.Succeeded(disposal)
+	logger.Info().
+		Str("tx_hash", res.TxHash).
+		Str("sell", disposal.Sell.String()).
+		Str("target", disposal.Target).
+		Msg("disposal confirmed")
+}
+
+// Parked returns the parked disposals, sorted by denoms
+func (d *Disposer) Parked() []Disposal {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var parked []Disposal
+	for _, key := range sortedKeys(d.parked) {
+		parked = append(parked, Disposal{Sell: sdk.NewCoin(key[0], d.parked[key]), Target: key[1]})
+	}
+
+	return parked
+}
+
+// Swaps removes queued disposals that can be sold at current prices and
+// returns them with a swap for each
+//
+// Disposals worth less than the min trade size remain queued and those worth
+// more are capped at the max trade size, with the remainder left queued.
+// Disposals of denoms without a price, or with a price that failed the price
+// guard, remain queued.
+func (d *Disposer) Swaps(logger zerolog.Logger, data *AuctionData) ([]Disposal, []*swaptypes.MsgSwapExactForTokens) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var disposals []Disposal
+	var msgs []*swaptypes.MsgSwapExactForTokens
+	for _, key := range sortedKeys(d.queue) {
+		sellDenom, targetDenom := key[0], key[1]
+
+		// the expected output and slippage bound are priced from pricefeed,
+		// so a price that failed the price guard would bound the swap badly
+		if err := unsafePrice(data, sellDenom, targetDenom); err != nil {
+			logger.Warn().
+				Err(err).
+				Str("sell", sellDenom).
+				Str("target", targetDenom).
+				Msg("unsafe price for disposal, holding collateral")
+			continue
+		}
+
+		sellInfo, sellOk := data.Assets[sellDenom]
+		targetInfo, targetOk := data.Assets[targetDenom]
+		if !sellOk || !targetOk {
+			logger.Warn().
+				Str("sell", sellDenom).
+				Str("target", targetDenom).
+				Msg("no price for disposal, holding collateral")
+			continue
+		}
+
+		sell := sdk.NewCoin(sellDenom, d.queue[key])
+		sellValue := calculateUSDValue(sell, sellInfo)
+		if sellValue.LT(d.config.MinTradeUSDValue) {
+			continue
+		}
+
+		if sellValue.GT(d.config.MaxTradeUSDValue) {
+			sell.Amount = sdk.NewDecFromInt(sell.Amount).
+				Mul(d.config.MaxTradeUSDValue).
+				Quo(sellValue).
+				TruncateInt()
+			sellValue = calculateUSDValue(sell, sellInfo)
+		}
+
+		// the output expected at pricefeed prices, the swap fails if the pool
+		// returns less than this minus the max slippage
+		expected := sdk.NewCoin(
+			targetDenom,
+			sellValue.Quo(targetInfo.Price).MulInt(targetInfo.ConversionFactor).TruncateInt(),
+		)
+		if !sell.IsPositive() || !expected.IsPositive() {
+			continue
+		}
+
+		msgs = append(msgs, swaptypes.NewMsgSwapExactForTokens(
+			d.keeper.String(),
+			sell,
+			expected,
+			d.config.MaxSlippage,
+			data.BlockTime.Add(d.config.Deadline).Unix(),
+		))
+		disposals = append(disposals, Disposal{Sell: sell, Target: targetDenom})
+
+		remaining := d.queue[key].Sub(sell.Amount)
+		if remaining.IsPositive() {
+			d.queue[key] = remaining
+		} else {
+			delete(d.queue, key)
+		}
+	}
+
+	return disposals, msgs
+}
+
+// TxClient fetches delivered txs, it is satisfied by the tx service client
+type TxClient interface {
+	GetTx(ctx context.Context, in *txtypes.GetTxRequest, opts ...grpc.CallOption) (*txtypes.GetTxResponse, error)
+}
+
+const (
+	fetchTxAttempts = 5
+	fetchTxDelay    = 2 * time.Second
+)
+
+// FetchTx fetches a delivered tx, retrying while the node has not indexed it
+func FetchTx(ctx context.Context, client TxClient, txHash string) (*sdk.TxResponse, error) {
+	var res *txtypes.GetTxResponse
+	var err error
+
+	// the signer responds once the tx is committed, which can be before the
+	// node has indexed it
+	for attempt := 1; ; attempt++ {
+		res, err = client.GetTx(ctx, &txtypes.GetTxRequest{Hash: txHash})
+		if err == nil || attempt == fetchTxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(fetchTxDelay):
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tx %s: %w", txHash, err)
+	}
+
+	return res.TxResponse, nil
+}
+
+func (d Disposal) key() [2]string {
+	return [2]string{d.Sell.Denom, d.Target}
+}
+
+func addAmount(amounts map[[2]string]sdk.Int, key [2]string, amount sdk.Int) {
+	current, found := amounts[key]
+	if !found {
+		current = sdk.ZeroInt()
+	}
+	amounts[key] = current.Add(amount)
+}
+
+func sortedKeys(amounts map[[2]string]sdk.Int) [][2]string {
+	keys := make([][2]string, 0, len(amounts))
+	for key := range amounts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i][0]+keys[i][1] < keys[j][0]+keys[j][1]
+	})
+
+	return keys
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestDisposerSwaps(t *testing.T) {
+	keeper, err := sdk.AccAddressFromBech32("kava10eup8kvq26z8ekjj9rkplr2lwwynskftqc4ytv")
+	require.NoError(t, err)
+
+	blockTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	data := &AuctionData{
+		BlockTime: blockTime,
+		Assets: map[string]AssetInfo{
+			"usdx": {Price: d("1.00"), ConversionFactor: sdk.NewInt(1e6)},
+			"bnb":  {Price: d("200"), ConversionFactor: sdk.NewInt(1e8)},
+		},
+	}
+
+	disposer := NewDisposer(DisposalConfig{
+		MaxSlippage:      d("0.01"),
+		MinTradeUSDValue: d("100"),
+		MaxTradeUSDValue: d("10000"),
+		Deadline:         10 * time.Minute,
+		MaxAttempts:      3,
+	}, keeper)
+
+	// lost auctions and auctions paid in the lot denom are not disposed of
+	disposer.AddWon(LedgerClose{Won: false, Received: c("bnb", 1e8), Paid: c("usdx", 1e6)})
+	disposer.AddWon(LedgerClose{Won: true, Received: c("usdx", 1e6), Paid: c("usdx", 1e6)})
+	// worth 80 usd, below the min trade size
+	disposer.AddWon(LedgerClose{Won: true, Received: c("bnb", 0.4e8), Paid: c("usdx", 70e6)})
+
+	disposals, swaps := disposer.Swaps(zerolog.Nop(), data)
+	require.Empty(t, disposals)
+	require.Empty(t, swaps)
+
+	// worth 20,080 usd combined, above the max trade size
+	disposer.AddWon(LedgerClose{Won: true, Received: c("bnb", 100e8), Paid: c("usdx", 18_000e6)})
+
+	disposals, swaps = disposer.Swaps(zerolog.Nop(), data)
+	require.Len(t, swaps, 1)
+	require.Equal(t, Disposal{Sell: c("bnb", 50e8), Target: "usdx"}, disposals[0])
+
+	swap := swaps[0]
+	require.NoError(t, swap.ValidateBasic())
+	require.Equal(t, keeper.String(), swap.Requester)
+	require.Equal(t, c("bnb", 50e8), swap.ExactTokenA)
+	require.Equal(t, c("usdx", 10_000e6), swap.TokenB)
+	require.Equal(t, d("0.01"), swap.Slippage)
+	require.Equal(t, blockTime.Add(10*time.Minute).Unix(), swap.Deadline)
+
+	// the remainder is still above the max trade size
+	_, swaps = disposer.Swaps(zerolog.Nop(), data)
+	require.Len(t, swaps, 1)
+	require.Equal(t, c("bnb", 50e8), swaps[0].ExactTokenA)
+
+	// the last 80 usd is held until more is won
+	_, swaps = disposer.Swaps(zerolog.Nop(), data)
+	require.Empty(t, swaps)
+
+	// failed swaps are queued again
+	disposer.Failed(zerolog.Nop(), Disposal{Sell: c("bnb", 1e8), Target: "usdx"}, errors.New("slippage exceeded"))
+	_, swaps = disposer.Swaps(zerolog.Nop(), data)
+	require.Len(t, swaps, 1)
+	require.Equal(t, c("bnb", 1.4e8), swaps[0].ExactTokenA)
+
+	// disposals are held while either price failed the price guard
+	disposer.Add(Disposal{Sell: c("bnb", 1e8), Target: "usdx"})
+	data.PriceErrors = map[string]error{"usdx": errors.New("stale price")}
+	_, swaps = disposer.Swaps(zerolog.Nop(), data)
+	require.Empty(t, swaps)
+
+	data.PriceErrors = nil
+	_, swaps = disposer.Swaps(zerolog.Nop(), data)
+	require.Len(t, swaps, 1)
+}
+
+func TestDisposerParksFailingPairs(t *testing.T) {
+	keeper, err := sdk.AccAddressFromBech32("kava10eup8kvq26z8ekjj9rkplr2lwwynskftqc4ytv")
+	require.NoError(t, err)
+
+	data := &AuctionData{
+		Assets: map[string]AssetInfo{
+			"usdx": {Price: d("1.00"), ConversionFactor: sdk.NewInt(1e6)},
+			"bnb":  {Price: d("200"), ConversionFactor: sdk.NewInt(1e8)},
+			"hard": {Price: d("0.10"), ConversionFactor: sdk.NewInt(1e6)},
+		},
+	}
+
+	disposer := NewDisposer(DisposalConfig{
+		MaxSlippage:      d("0.01"),
+		MinTradeUSDValue: d("100"),
+		MaxTradeUSDValue: d("10000"),
+		Deadline:         10 * time.Minute,
+		MaxAttempts:      2,
+	}, keeper)
+
+	bnb := Disposal{Sell: c("bnb", 1e8), Target: "usdx"}
+	noPool := errors.New("pool not found")
+
+	// a success clears the failed attempts
+	disposer.Failed(zerolog.Nop(), bnb, noPool)
+	disposer.Succeeded(bnb)
+	disposals, _ := disposer.Swaps(zerolog.Nop(), data)
+	require.Equal(t, []Disposal{bnb}, disposals)
+
+	disposer.Failed(zerolog.Nop(), bnb, noPool)
+	disposals, _ = disposer.Swaps(zerolog.Nop(), data)
+	require.Equal(t, []Disposal{bnb}, disposals)
+	disposer.Failed(zerolog.Nop(), bnb, noPool)
+
+	// parked pairs are not sold, and later wins of the pair are parked too
+	disposer.Add(bnb)
+	disposals, _ = disposer.Swaps(zerolog.Nop(), data)
+	require.Empty(t, disposals)
+	require.Equal(t, []Disposal{{Sell: c("bnb", 2e8), Target: "usdx"}}, disposer.Parked())
+
+	// disposals with an unknown outcome are parked without parking the pair
+	hard := Disposal{Sell: c("hard", 2000e6), Target: "usdx"}
+	disposer.Park(zerolog.Nop(), Disposal{Sell: c("hard", 1000e6), Target: "usdx"}, errors.New("tx not found"))
+	disposer.Add(hard)
+	disposals, _ = disposer.Swaps(zerolog.Nop(), data)
+	require.Equal(t, []Disposal{hard}, disposals)
+	require.Equal(t, []Disposal{
+		{Sell: c("bnb", 2e8), Target: "usdx"},
+		{Sell: c("hard", 1000e6), Target: "usdx"},
+	}, disposer.Parked())
+}
+
+type mockTxClient struct {
+	res *txtypes.GetTxResponse
+	err error
+}
+
+func (c mockTxClient) GetTx(ctx context.Context, in *txtypes.GetTxRequest, opts ...grpc.CallOption) (*txtypes.GetTxResponse, error) {
+	return c.res, c.err
+}
+
+func TestDisposerConfirm(t *testing.T) {
+	keeper, err := sdk.AccAddressFromBech32("kava10eup8kvq26z8ekjj9rkplr2lwwynskftqc4ytv")
+	require.NoError(t, err)
+
+	data := &AuctionData{
+		Assets: map[string]AssetInfo{
+			"usdx": {Price: d("1.00"), ConversionFactor: sdk.NewInt(1e6)},
+			"bnb":  {Price: d("200"), ConversionFactor: sdk.NewInt(1e8)},
+		},
+	}
+
+	disposer := NewDisposer(DisposalConfig{
+		MaxSlippage:      d("0.01"),
+		MinTradeUSDValue: d("100"),
+		MaxTradeUSDValue: d("10000"),
+		Deadline:         10 * time.Minute,
+		MaxAttempts:      3,
+	}, keeper)
+	bnb := Disposal{Sell: c("bnb", 1e8), Target: "usdx"}
+
+	// delivered swaps are not queued again
+	delivered := mockTxClient{res: &txtypes.GetTxResponse{TxResponse: &sdk.TxResponse{TxHash: "A"}}}
+	disposer.Confirm(context.Background(), zerolog.Nop(), delivered, bnb, "A")
+	disposals, _ := disposer.Swaps(zerolog.Nop(), data)
+	require.Empty(t, disposals)
+
+	// swaps that fail when delivered are queued again
+	failed := mockTxClient{res: &txtypes.GetTxResponse{TxResponse: &sdk.TxResponse{TxHash: "B", Code: 1, RawLog: "slippage exceeded"}}}
+	disposer.Confirm(context.Background(), zerolog.Nop(), failed, bnb, "B")
+	disposals, _ = disposer.Swaps(zerolog.Nop(), data)
+	require.Equal(t, []Disposal{bnb}, disposals)
+
+	// swaps that can not be fetched may have been delivered, so are parked
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	disposer.Confirm(ctx, zerolog.Nop(), mockTxClient{err: errors.New("tx not found")}, bnb, "C")
+	disposals, _ = disposer.Swaps(zerolog.Nop(), data)
+	require.Empty(t, disposals)
+	require.Equal(t, []Disposal{bnb}, disposer.Parked())
+}
+
+func TestDisposalConfigValidate(t *testing.T) {
+	valid := DisposalConfig{
+		MaxSlippage:      d("0.01"),
+		MinTradeUSDValue: d("100"),
+		MaxTradeUSDValue: d("10000"),
+		Deadline:         time.Minute,
+		MaxAttempts:      3,
+	}
+	require.NoError(t, valid.Validate())
+
+	invalid := valid
+	invalid.MaxSlippage = d("1")
+	require.Error(t, invalid.Validate())
+
+	invalid = valid
+	invalid.MaxTradeUSDValue = d("50")
+	require.Error(t, invalid.Validate())
+
+	invalid = valid
+	invalid.Deadline = 0
+	require.Error(t, invalid.Validate())
+
+	invalid = valid
+	invalid.MaxAttempts = 0
+	require.Error(t, invalid.Validate())
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	rpchttp "github.com/cometbft/cometbft/rpc/client/http"
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	cmttypes "github.com/cometbft/cometbft/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	auctiontypes "github.com/kava-labs/kava/x/auction/types"
+	"github.com/rs/zerolog"
+)
+
+const eventSubscriber = "auction-bot"
+
+var (
+	// auctions are started in begin block by cdp and in txs by hard liquidations
+	auctionStartBlockQuery = fmt.Sprintf("tm.event='NewBlockHeader' AND %s.%s EXISTS", auctiontypes.EventTypeAuctionStart, auctiontypes.AttributeKeyAuctionID)
+	auctionStartTxQuery    = fmt.Sprintf("tm.event='Tx' AND %s.%s EXISTS", auctiontypes.EventTypeAuctionStart, auctiontypes.AttributeKeyAuctionID)
+	auctionBidQuery        = fmt.Sprintf("tm.event='Tx' AND %s.%s EXISTS", auctiontypes.EventTypeAuctionBid, auctiontypes.AttributeKeyAuctionID)
+	newBlockHeaderQuery    = "tm.event='NewBlockHeader'"
+)
+
+// AuctionEventWatcher subscribes to CometBFT events and triggers a bid cycle
+// when an auction starts, when another bidder places a bid, or when a new
+// block is close to the end time of a tracked auction
+type AuctionEventWatcher struct {
+	client    *rpchttp.HTTP
+	logger    zerolog.Logger
+	keeper    string
+	endWindow time.Duration
+	triggers  chan struct{}
+	onBlock   func(height int64, blockTime time.Time)
+
+	mu       sync.Mutex
+	endTimes map[uint64]time.Time
+}
+
+// NewAuctionEventWatcher creates a watcher for the node at rpcURL, endWindow
+// is how long before an auction ends every new block triggers a cycle
+func NewAuctionEventWatcher(
+	rpcURL string,
+	keeper sdk.AccAddress,
+	endWindow time.Duration,
+	logger zerolog.Logger,
+) (*AuctionEventWatcher, error) {
+	client, err := rpchttp.New(rpcURL, "/websocket")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rpc client: %w", err)
+	}
+
+	return &AuctionEventWatcher{
+		client:    client,
+		logger:    logger,
+		keeper:    keeper.String(),
+		endWindow: endWindow,
+		// buffer a single trigger so events during a cycle coalesce into one
+		triggers: make(chan struct{}, 1),
+		endTimes: make(map[uint64]time.Time),
+	}, nil
+}
+
+// Triggers returns a channel that receives when a bid cycle should run
+func (w *AuctionEventWatcher) Triggers() <-chan struct{} {
+	return w.triggers
+}
+
+// OnBlock sets a function called with every new block, must be set before Run
+func (w *AuctionEventWatcher) OnBlock(fn func(height int64, blockTime time.Time)) {
+	w.onBlock = fn
+}
+
+// Track replaces the tracked end times with those of the provided auctions
+func (w *AuctionEventWatcher) Track(auctions []auctiontypes.Auction) {
+	endTimes := make(map[uint64]time.Time, len(auctions))
+	for _, auction := range auctions {
+		endTimes[auction.GetID()] = auction.GetEndTime()
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.endTimes = endTimes
+}
+
+// Run starts the websocket client and subscribes to auction events, events
+// are handled in the background until the context is canceled
+func (w *AuctionEventWatcher) Run(ctx context.Context) error {
+	if err := w.client.Start(); err != nil {
+		return fmt.Errorf("failed to start rpc client: %w", err)
+	}
+
+	queries := []string{auctionStartBlockQuery, auctionStartTxQuery, auctionBidQuery, newBlockHeaderQuery}
+	subscriptions := make([]<-chan ctypes.ResultEvent, 0, len(queries))
+	for _, query := range queries {
+		events, err := w.client.Subscribe(ctx, eventSubscriber, query, 100)
+		if err != nil {
+			_ = w.client.Stop()
+			return fmt.Errorf("failed to subscribe to %s: %w", query, err)
+		}
+		subscriptions = append(subscriptions, events)
+	}
+
+	go func() {
+		defer func() { _ = w.client.Stop() }()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-subscriptions[0]:
+				w.handleEvent(event)
+			case event := <-subscriptions[1]:
+				w.handleEvent(event)
+			case event := <-subscriptions[2]:
+				w.handleEvent(event)
+			case event := <-subscriptions[3]:
+				w.handleEvent(event)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// handleEvent triggers a cycle if the event is relevant to bidding
+func (w *AuctionEventWatcher) handleEvent(event ctypes.ResultEvent) {
+	if ids := event.Events[auctionEventKey(auctiontypes.EventTypeAuctionStart, auctiontypes.AttributeKeyAuctionID)]; len(ids) > 0 {
+		w.notify("auction started", ids)
+		return
+	}
+
+	if ids := event.Events[auctionEventKey(auctiontypes.EventTypeAuctionBid, auctiontypes.AttributeKeyAuctionID)]; len(ids) > 0 {
+		if w.updateBids(event.Events, ids) {
+			w.notify("auction bid", ids)
+		}
+		return
+	}
+
+	if header, ok := event.Data.(cmttypes.EventDataNewBlockHeader); ok {
+		if w.onBlock != nil {
+			w.onBlock(header.Header.Height, header.Header.Time)
+		}
+		if ids := w.endingBefore(header.Header.Time.Add(w.endWindow)); len(ids) > 0 {
+			w.notify("auction ending", ids)
+		}
+	}
+}
+
+// updateBids updates tracked end times from bid events and returns true if
+// any of the bids were placed by someone else
+func (w *AuctionEventWatcher) updateBids(events map[string][]string, ids []string) bool {
+	bidders := events[auctionEventKey(auctiontypes.EventTypeAuctionBid, auctiontypes.AttributeKeyBidder)]
+	endTimes := events[auctionEventKey(auctiontypes.EventTypeAuctionBid, auctiontypes.AttributeKeyEndTime)]
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// attributes are flattened across events, so values at the same index
+	// belong to the same bid
+	outbid := false
+	for i, rawID := range ids {
+		if i < len(bidders) && bidders[i] != w.keeper {
+			outbid = true
+		}
+
+		id, err := strconv.ParseUint(rawID, 10, 64)
+		if err != nil || i >= len(endTimes) {
+			continue
+		}
+		endTime, err := strconv.ParseInt(endTimes[i], 10, 64)
+		if err != nil {
+			continue
+		}
+		w.endTimes[id] = time.Unix(endTime, 0)
+	}
+
+	return outbid
+}
+
+// endingBefore returns the ids of tracked auctions ending at or before t
+func (w *AuctionEventWatcher) endingBefore(t time.Time) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var ids []string
+	for id, endTime := range w.endTimes {
+		if !endTime.After(t) {
+			ids = append(ids, strconv.FormatUint(id, 10))
+		}
+	}
+
+	return ids
+}
+
+func (w *AuctionEventWatcher) notify(reason string, ids []string) {
+	select {
+	case w.triggers <- struct{}{}:
+		w.logger.Debug().Strs("auctionIds", ids).Msgf("%s, triggering bid cycle", reason)
+	default:
+		// a cycle is already pending
+	}
+}
+
+func auctionEventKey(eventType, attribute string) string {
+	return fmt.Sprintf("%s.%s", eventType, attribute)
+}
+
+// waitForCycle blocks until a cycle is triggered by an event or forced, or the
+// wait elapses, nil channels are never received from
+func waitForCycle(triggers, forced <-chan struct{}, wait time.Duration) {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-triggers:
+	case <-forced:
+	case <-timer.C:
+	}
+}
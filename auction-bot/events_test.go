@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	ctypes "github.com/cometbft/cometbft/rpc/core/types"
+	cmttypes "github.com/cometbft/cometbft/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	auctiontypes "github.com/kava-labs/kava/x/auction/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuctionEventWatcher(t *testing.T) {
+	keeper, err := sdk.AccAddressFromBech32("kava10eup8kvq26z8ekjj9rkplr2lwwynskftqc4ytv")
+	require.NoError(t, err)
+	other := "kava1w66puffhccjck70hw75wu3v92tshw5rmdxp8hb"
+
+	endTime := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	newWatcher := func(t *testing.T) *AuctionEventWatcher {
+		watcher, err := NewAuctionEventWatcher("http://localhost:26657", keeper, 2*time.Minute, zerolog.Nop())
+		require.NoError(t, err)
+		watcher.Track([]auctiontypes.Auction{
+			&auctiontypes.CollateralAuction{BaseAuction: auctiontypes.BaseAuction{ID: 1, EndTime: endTime}},
+		})
+		return watcher
+	}
+	triggered := func(watcher *AuctionEventWatcher) bool {
+		select {
+		case <-watcher.Triggers():
+			return true
+		default:
+			return false
+		}
+	}
+	bidEvent := func(bidder string, endTime time.Time) ctypes.ResultEvent {
+		return ctypes.ResultEvent{Events: map[string][]string{
+			"auction_bid.auction_id": {"1"},
+			"auction_bid.bidder":     {bidder},
+			"auction_bid.end_time":   {strconv.FormatInt(endTime.Unix(), 10)},
+		}}
+	}
+	blockEvent := func(blockTime time.Time) ctypes.ResultEvent {
+		return ctypes.ResultEvent{Data: cmttypes.EventDataNewBlockHeader{Header: cmttypes.Header{Time: blockTime}}}
+	}
+
+	t.Run("auction start triggers", func(t *testing.T) {
+		watcher := newWatcher(t)
+		watcher.handleEvent(ctypes.ResultEvent{Events: map[string][]string{"auction_start.auction_id": {"2"}}})
+		require.True(t, triggered(watcher))
+	})
+
+	t.Run("only bids from others trigger", func(t *testing.T) {
+		watcher := newWatcher(t)
+		watcher.handleEvent(bidEvent(keeper.String(), endTime))
+		require.False(t, triggered(watcher))
+
+		watcher.handleEvent(bidEvent(other, endTime))
+		require.True(t, triggered(watcher))
+	})
+
+	t.Run("blocks trigger within the end window", func(t *testing.T) {
+		watcher := newWatcher(t)
+		watcher.handleEvent(blockEvent(endTime.Add(-3 * time.Minute)))
+		require.False(t, triggered(watcher))
+
+		watcher.handleEvent(blockEvent(endTime.Add(-time.Minute)))
+		require.True(t, triggered(watcher))
+	})
+
+	t.Run("bids extend tracked end time", func(t *testing.T) {
+		watcher := newWatcher(t)
+		watcher.handleEvent(bidEvent(keeper.String(), endTime.Add(time.Hour)))
+
+		watcher.handleEvent(blockEvent(endTime.Add(-time.Minute)))
+		require.False(t, triggered(watcher))
+	})
+
+	t.Run("triggers coalesce", func(t *testing.T) {
+		watcher := newWatcher(t)
+		watcher.handleEvent(bidEvent(other, endTime))
+		watcher.handleEvent(bidEvent(other, endTime))
+		require.True(t, triggered(watcher))
+		require.False(t, triggered(watcher))
+	})
+}
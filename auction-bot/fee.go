@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/node"
+	"github.com/kava-labs/go-tools/signing"
+	"github.com/rs/zerolog"
+)
+
+// FeeKeys returns the keys retries of the bids are counted by, a bid is a
+// retry if a previous bid on the same auction failed
+func (r bidRequest) FeeKeys() []string {
+	keys := make([]string, len(r.AuctionIDs))
+	for i, id := range r.AuctionIDs {
+		keys[i] = fmt.Sprintf("auction:%d", id)
+	}
+
+	return keys
+}
+
+// FeeKeys returns the keys retries of the swap are counted by
+func (d Disposal) FeeKeys() []string {
+	return []string{fmt.Sprintf("swap:%s:%s", d.Sell.Denom, d.Target)}
+}
+
+// requestFeeKeys returns the retry keys of a request sent by the bot
+func requestFeeKeys(request signing.MsgRequest) []string {
+	switch data := request.Data.(type) {
+	case bidRequest:
+		return data.FeeKeys()
+	case swapRequest:
+		return data.Disposal.FeeKeys()
+	default:
+		return nil
+	}
+}
+
+// updateFeePolicy refreshes the node minimum gas prices and mempool
+// congestion used to price fees, either client may be nil to skip it
+//
+// Failures are logged and the previous values are kept, so fees are still
+// priced from the base gas price if the node does not support the queries.
+func updateFeePolicy(
+	logger zerolog.Logger,
+	policy *signing.FeePolicy,
+	nodeClient node.ServiceClient,
+	mempoolClient signing.MempoolClient,
+) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if nodeClient != nil {
+		if err := policy.UpdateMinGasPrices(ctx, nodeClient); err != nil {
+			logger.Warn().Err(err).Msg("failed to update node minimum gas prices")
+		}
+	}
+
+	if mempoolClient != nil {
+		if err := policy.UpdateCongestion(ctx, mempoolClient); err != nil {
+			logger.Warn().Err(err).Msg("failed to update mempool congestion")
+		}
+	}
+}
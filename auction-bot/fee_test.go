@@ -0,0 +1,23 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/kava-labs/go-tools/signing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestFeeKeys(t *testing.T) {
+	bids := signing.MsgRequest{Data: bidRequest{AuctionIDs: []uint64{1, 2}}}
+	require.Equal(t, []string{"auction:1", "auction:2"}, requestFeeKeys(bids))
+
+	swap := signing.MsgRequest{Data: swapRequest{Disposal: Disposal{Sell: c("bnb", 100), Target: "usdx"}}}
+	require.Equal(t, []string{"swap:bnb:usdx"}, requestFeeKeys(swap))
+
+	require.Empty(t, requestFeeKeys(signing.MsgRequest{}))
+
+	// a failed bid escalates the fee of the next batch containing the auction
+	retries := signing.NewFeeRetries()
+	retries.Failed(requestFeeKeys(bids)...)
+	require.Equal(t, 1, retries.Retries(bidRequest{AuctionIDs: []uint64{2, 3}}.FeeKeys()...))
+}
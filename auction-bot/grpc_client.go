@@ -6,12 +6,16 @@ import (
 	"fmt"
 	"log"
 	"net/url"
+	"time"
 
+	"github.com/cosmos/cosmos-sdk/client/grpc/node"
 	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
 	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/query"
 	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
 	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 	auctiontypes "github.com/kava-labs/kava/x/auction/types"
 	cdptypes "github.com/kava-labs/kava/x/cdp/types"
 	hardtypes "github.com/kava-labs/kava/x/hard/types"
@@ -28,8 +32,10 @@ type GrpcClient struct {
 	cdc            codec.Codec
 	GrpcClientConn *grpc.ClientConn
 	Auth           authtypes.QueryClient
+	Bank           banktypes.QueryClient
 	Tx             txtypes.ServiceClient
 	Tm             tmservice.ServiceClient
+	Node           node.ServiceClient
 	Auction        auctiontypes.QueryClient
 	Cdp            cdptypes.QueryClient
 	Hard           hardtypes.QueryClient
@@ -62,7 +68,9 @@ func NewGrpcClient(target string, cdc codec.Codec) GrpcClient {
 		cdc:            cdc,
 		GrpcClientConn: grpcConn,
 		Auth:           authtypes.NewQueryClient(grpcConn),
+		Bank:           banktypes.NewQueryClient(grpcConn),
 		Tm:             tmservice.NewServiceClient(grpcConn),
+		Node:           node.NewServiceClient(grpcConn),
 		Tx:             txtypes.NewServiceClient(grpcConn),
 		Auction:        auctiontypes.NewQueryClient(grpcConn),
 		Cdp:            cdptypes.NewQueryClient(grpcConn),
@@ -80,6 +88,39 @@ func (c *GrpcClient) LatestHeight() (int64, error) {
 	return latestBlock.Block.Header.Height, nil
 }
 
+// LatestBlockTime returns the time of the latest block
+func (c *GrpcClient) LatestBlockTime() (time.Time, error) {
+	latestBlock, err := c.Tm.GetLatestBlock(context.Background(), &tmservice.GetLatestBlockRequest{})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to fetch latest block: %w", err)
+	}
+
+	return latestBlock.Block.Header.Time, nil
+}
+
+// Syncing returns true if the node is catching up to the network
+func (c *GrpcClient) Syncing() (bool, error) {
+	res, err := c.Tm.GetSyncing(context.Background(), &tmservice.GetSyncingRequest{})
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch syncing status: %w", err)
+	}
+
+	return res.Syncing, nil
+}
+
+// Balances returns all balances of an address
+func (c *GrpcClient) Balances(addr string) (sdk.Coins, error) {
+	res, err := c.Bank.AllBalances(context.Background(), &banktypes.QueryAllBalancesRequest{
+		Address:    addr,
+		Pagination: &query.PageRequest{Limit: PageLimit},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch balances: %w", err)
+	}
+
+	return res.Balances, nil
+}
+
 func (c *GrpcClient) ChainID() (string, error) {
 	latestBlock, err := c.Tm.GetLatestBlock(context.Background(), &tmservice.GetLatestBlockRequest{})
 	if err != nil {
@@ -120,6 +161,27 @@ func (c *GrpcClient) BaseAccount(addr string) (authtypes.BaseAccount, error) {
 	return *bAcc, nil
 }
 
+// AuctionAtHeight fetches a single auction at a height, returning false if
+// the auction did not exist at that height
+func (c *GrpcClient) AuctionAtHeight(id uint64, height int64) (auctiontypes.Auction, bool, error) {
+	res, err := c.Auction.Auction(ctxAtHeight(height), &auctiontypes.QueryAuctionRequest{AuctionId: id})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to fetch auction %d at height %d: %w", id, height, err)
+	}
+
+	// the query returns an empty response for auctions that do not exist
+	if res.Auction == nil {
+		return nil, false, nil
+	}
+
+	var auction auctiontypes.Auction
+	if err := c.cdc.UnpackAny(res.Auction, &auction); err != nil {
+		return nil, false, fmt.Errorf("failed to unpack auction: %w", err)
+	}
+
+	return auction, true, nil
+}
+
 func (c *GrpcClient) AllAuctions(ctx context.Context) ([]auctiontypes.Auction, error) {
 	var key []byte
 	var auctions []auctiontypes.Auction
@@ -3,26 +3,53 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/alexliesenfeld/health"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/go-chi/chi/v5"
 	"github.com/kava-labs/go-tools/signing"
 	"github.com/rs/zerolog"
 )
 
+// HealthSource provides the node and account state used by readiness checks
+type HealthSource interface {
+	LatestBlockTime() (time.Time, error)
+	Syncing() (bool, error)
+	Balances(addr string) (sdk.Coins, error)
+}
+
+var _ HealthSource = (*GrpcClient)(nil)
+
 func startHealthCheckService(
 	ctx context.Context,
 	logger zerolog.Logger,
 	config Config,
 	client GrpcClient,
 	signer *signing.Signer,
+	state *BotState,
 ) {
-	// Create a new Checker.
-	checker := health.NewChecker(
-		health.WithCacheDuration(1*time.Second),
-		health.WithTimeout(10*time.Second),
+	// the bot is not live when the bid loop or signer is wedged, and should be
+	// restarted
+	livenessChecks := []health.CheckerOption{
+		health.WithCheck(health.Check{
+			Name: "bid cycle",
+			Check: func(ctx context.Context) error {
+				return checkCycleAge(state.LastCycle(), time.Now(), config.Health.MaxCycleAge)
+			},
+		}),
+		health.WithCheck(health.Check{
+			Name: "signer inflight",
+			Check: func(ctx context.Context) error {
+				return checkInflight(state.Inflight(), time.Now(), config.Health.MaxInflightAge)
+			},
+		}),
+	}
+
+	// the bot is not ready when it cannot bid, restarting will not help
+	readinessChecks := []health.CheckerOption{
 		// Run every minute with initial delay of 3 seconds. Not run each HTTP request
 		health.WithPeriodicCheck(60*time.Second, 3*time.Second, health.Check{
 			Name: "kava grpc",
@@ -31,23 +58,56 @@ func startHealthCheckService(
 				return err
 			},
 		}),
+		health.WithPeriodicCheck(30*time.Second, 3*time.Second, health.Check{
+			Name: "kava node synced",
+			Check: func(ctx context.Context) error {
+				return checkNodeSynced(&client, time.Now(), config.Health.MaxBlockAge)
+			},
+		}),
 		health.WithCheck(health.Check{
 			Name: "signing account",
 			Check: func(ctx context.Context) error {
 				return signer.GetAccountError()
 			},
 		}),
-		// Runs when health status changes
-		health.WithStatusListener(func(ctx context.Context, state health.CheckerState) {
-			logger.
-				Debug().
-				Str("status", string(state.Status)).
-				Msg("health status changed")
-		}),
-	)
+	}
+	if !config.Health.MinBalances.Empty() {
+		readinessChecks = append(readinessChecks, health.WithPeriodicCheck(60*time.Second, 3*time.Second, health.Check{
+			Name: "keeper balances",
+			Check: func(ctx context.Context) error {
+				return checkBalances(&client, signer.Address(), config.Health.MinBalances)
+			},
+		}))
+	}
+
+	newChecker := func(name string, checks ...health.CheckerOption) health.Checker {
+		options := append([]health.CheckerOption{
+			health.WithCacheDuration(1 * time.Second),
+			health.WithTimeout(10 * time.Second),
+			// Runs when health status changes
+			health.WithStatusListener(func(ctx context.Context, state health.CheckerState) {
+				logger.
+					Debug().
+					Str("checker", name).
+					Str("status", string(state.Status)).
+					Msg("health status changed")
+			}),
+		}, checks...)
+
+		return health.NewChecker(options...)
+	}
 
 	r := chi.NewRouter()
-	r.Get("/health", health.NewHandler(checker))
+	r.Get("/livez", health.NewHandler(newChecker("liveness", livenessChecks...)))
+	r.Get("/readyz", health.NewHandler(newChecker("readiness", readinessChecks...)))
+	// all checks, kept for existing monitoring
+	allChecks := append(append([]health.CheckerOption{}, livenessChecks...), readinessChecks...)
+	r.Get("/health", health.NewHandler(newChecker("health", allChecks...)))
+
+	// admin endpoints are only served when a token is configured
+	if config.AdminApiToken != "" {
+		r.Mount("/admin", NewAdminRouter(logger, state, config.AdminApiToken))
+	}
 
 	server := &http.Server{
 		Addr:    config.HeathCheckListenAddr,
@@ -64,3 +124,63 @@ func startHealthCheckService(
 		}
 	}()
 }
+
+// checkCycleAge returns an error if the last bid cycle completed too long ago
+func checkCycleAge(lastCycle, now time.Time, maxAge time.Duration) error {
+	if age := now.Sub(lastCycle); age > maxAge {
+		return fmt.Errorf("last bid cycle completed %s ago, max %s", age.Round(time.Second), maxAge)
+	}
+
+	return nil
+}
+
+// checkInflight returns an error if a request has waited too long for the
+// signer to place it in a block
+func checkInflight(requests []InflightRequest, now time.Time, maxAge time.Duration) error {
+	for _, request := range requests {
+		if age := now.Sub(request.SentAt); age > maxAge {
+			return fmt.Errorf("request %d in flight for %s, max %s", request.ID, age.Round(time.Second), maxAge)
+		}
+	}
+
+	return nil
+}
+
+// checkNodeSynced returns an error if the node is catching up or its latest
+// block is too old
+func checkNodeSynced(source HealthSource, now time.Time, maxBlockAge time.Duration) error {
+	syncing, err := source.Syncing()
+	if err != nil {
+		return err
+	}
+	if syncing {
+		return fmt.Errorf("node is catching up")
+	}
+
+	blockTime, err := source.LatestBlockTime()
+	if err != nil {
+		return err
+	}
+	if age := now.Sub(blockTime); age > maxBlockAge {
+		return fmt.Errorf("latest block is %s old, max %s", age.Round(time.Second), maxBlockAge)
+	}
+
+	return nil
+}
+
+// checkBalances returns an error if the keeper holds less than the minimum of
+// any denom
+func checkBalances(source HealthSource, keeper sdk.AccAddress, minBalances sdk.Coins) error {
+	balances, err := source.Balances(keeper.String())
+	if err != nil {
+		return err
+	}
+
+	for _, minimum := range minBalances {
+		if balance := balances.AmountOf(minimum.Denom); balance.LT(minimum.Amount) {
+			return fmt.Errorf("balance %s%s below minimum %s", balance, minimum.Denom, minimum)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+type mockHealthSource struct {
+	blockTime time.Time
+	syncing   bool
+	balances  sdk.Coins
+}
+
+func (s mockHealthSource) LatestBlockTime() (time.Time, error) {
+	return s.blockTime, nil
+}
+
+func (s mockHealthSource) Syncing() (bool, error) {
+	return s.syncing, nil
+}
+
+func (s mockHealthSource) Balances(addr string) (sdk.Coins, error) {
+	return s.balances, nil
+}
+
+func TestLivenessChecks(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, checkCycleAge(now.Add(-29*time.Minute), now, 30*time.Minute))
+	require.ErrorContains(t, checkCycleAge(now.Add(-31*time.Minute), now, 30*time.Minute), "last bid cycle completed 31m0s ago")
+
+	requests := []InflightRequest{
+		{ID: 1, SentAt: now.Add(-11 * time.Minute)},
+		{ID: 2, SentAt: now.Add(-time.Minute)},
+	}
+	require.NoError(t, checkInflight(requests[1:], now, 10*time.Minute))
+	require.ErrorContains(t, checkInflight(requests, now, 10*time.Minute), "request 1 in flight")
+	require.NoError(t, checkInflight(nil, now, 10*time.Minute))
+}
+
+func TestReadinessChecks(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, checkNodeSynced(mockHealthSource{blockTime: now.Add(-10 * time.Second)}, now, time.Minute))
+	require.ErrorContains(t, checkNodeSynced(mockHealthSource{blockTime: now, syncing: true}, now, time.Minute), "catching up")
+	require.ErrorContains(t, checkNodeSynced(mockHealthSource{blockTime: now.Add(-2 * time.Minute)}, now, time.Minute), "latest block is 2m0s old")
+
+	keeper := sdk.AccAddress(make([]byte, 20))
+	minBalances := sdk.NewCoins(c("ukava", 1_000_000), c("usdx", 500e6))
+	source := mockHealthSource{balances: sdk.NewCoins(c("ukava", 2_000_000), c("usdx", 600e6))}
+	require.NoError(t, checkBalances(source, keeper, minBalances))
+
+	source.balances = sdk.NewCoins(c("ukava", 2_000_000))
+	require.ErrorContains(t, checkBalances(source, keeper, minBalances), "balance 0usdx below minimum")
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	auctiontypes "github.com/kava-labs/kava/x/auction/types"
+	"github.com/rs/zerolog"
+)
+
+const (
+	ledgerEntryBid   = "bid"
+	ledgerEntryClose = "close"
+)
+
+// LedgerBid records a bid submitted on an auction along with the asset info
+// at the time of the bid
+//
+// Amount is in the bid denom for forward bids and in the lot denom for reverse bids.
+type LedgerBid struct {
+	AuctionID uint64               `json:"auction_id"`
+	Height    int64                `json:"height"`
+	Time      time.Time            `json:"time"`
+	Lot       sdk.Coin             `json:"lot"`
+	Bid       sdk.Coin             `json:"bid"`
+	Amount    sdk.Coin             `json:"amount"`
+	Assets    map[string]AssetInfo `json:"assets"`
+}
+
+// LedgerClose records the outcome of a closed auction that was bid on, the
+// received lot and paid bid are only set if the auction was won
+type LedgerClose struct {
+	AuctionID          uint64    `json:"auction_id"`
+	CloseHeight        int64     `json:"close_height"`
+	CloseTime          time.Time `json:"close_time"`
+	Winner             string    `json:"winner"`
+	Won                bool      `json:"won"`
+	Received           sdk.Coin  `json:"received"`
+	Paid               sdk.Coin  `json:"paid"`
+	ReceivedUSDAtBid   sdk.Dec   `json:"received_usd_at_bid"`
+	PaidUSDAtBid       sdk.Dec   `json:"paid_usd_at_bid"`
+	ReceivedUSDAtClose sdk.Dec   `json:"received_usd_at_close"`
+	PaidUSDAtClose     sdk.Dec   `json:"paid_usd_at_close"`
+}
+
+// ProfitUSD is the value of the received lot less the amount paid at close
+func (c LedgerClose) ProfitUSD() sdk.Dec {
+	return c.ReceivedUSDAtClose.Sub(c.PaidUSDAtClose)
+}
+
+// ledgerEntry is a single line of the ledger file
+type ledgerEntry struct {
+	Type  string       `json:"type"`
+	Bid   *LedgerBid   `json:"bid,omitempty"`
+	Close *LedgerClose `json:"close,omitempty"`
+}
+
+// LedgerSource provides the historical state needed to resolve closed auctions
+type LedgerSource interface {
+	AuctionAtHeight(id uint64, height int64) (auctiontypes.Auction, bool, error)
+	AssetInfoAtHeight(height int64) (map[string]AssetInfo, error)
+	BlockTimeAtHeight(height int64) (time.Time, error)
+}
+
+// GrpcLedgerSource reads historical state from a node, heights before the
+// node's pruning window require an archive node
+type GrpcLedgerSource struct {
+	client GrpcClient
+}
+
+var _ LedgerSource = (*GrpcLedgerSource)(nil)
+
+func NewGrpcLedgerSource(client GrpcClient) *GrpcLedgerSource {
+	return &GrpcLedgerSource{client: client}
+}
+
+func (s *GrpcLedgerSource) AuctionAtHeight(id uint64, height int64) (auctiontypes.Auction, bool, error) {
+	return s.client.AuctionAtHeight(id, height)
+}
+
+func (s *GrpcLedgerSource) AssetInfoAtHeight(height int64) (map[string]AssetInfo, error) {
+	return GetAssetInfoAtHeight(s.client, height)
+}
+
+func (s *GrpcLedgerSource) BlockTimeAtHeight(height int64) (time.Time, error) {
+	return GetBlockTimeAtHeight(s.client, height)
+}
+
+// Ledger appends submitted bids and auction outcomes to a file as JSON lines,
+// entries are never rewritten
+type Ledger struct {
+	file    *os.File
+	encoder *json.Encoder
+	// last bid on each auction that has not been resolved
+	pending map[uint64]LedgerBid
+}
+
+// OpenLedger opens or creates the ledger at path for appending, auctions bid
+// on in previous runs that were not resolved are resolved by this ledger
+func OpenLedger(path string) (*Ledger, error) {
+	bids, closes, err := ReadLedger(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make(map[uint64]LedgerBid)
+	for _, bid := range bids {
+		pending[bid.AuctionID] = bid
+	}
+	for _, closed := range closes {
+		delete(pending, closed.AuctionID)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ledger: %w", err)
+	}
+
+	return &Ledger{
+		file:    file,
+		encoder: json.NewEncoder(file),
+		pending: pending,
+	}, nil
+}
+
+// ReadLedger returns all bid and close entries in the ledger at path, a
+// missing file is an empty ledger
+func ReadLedger(path string) ([]LedgerBid, []LedgerClose, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open ledger: %w", err)
+	}
+	defer file.Close()
+
+	var bids []LedgerBid
+	var closes []LedgerClose
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		var entry ledgerEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, nil, fmt.Errorf("invalid ledger entry on line %d: %w", line, err)
+		}
+
+		switch {
+		case entry.Type == ledgerEntryBid && entry.Bid != nil:
+			bids = append(bids, *entry.Bid)
+		case entry.Type == ledgerEntryClose && entry.Close != nil:
+			closes = append(closes, *entry.Close)
+		default:
+			return nil, nil, fmt.Errorf("invalid ledger entry on line %d: unknown type %q", line, entry.Type)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read ledger: %w", err)
+	}
+
+	return bids, closes, nil
+}
+
+// RecordBids appends an entry for every decision with a bid that was submitted
+func (l *Ledger) RecordBids(data *AuctionData, decisions BidDecisions) error {
+	for _, decision := range decisions {
+		if decision.Proposed == nil || decision.HeldUntil != nil {
+			continue
+		}
+
+		assets := make(map[string]AssetInfo)
+		for _, denom := range []string{decision.Lot.Denom, decision.Bid.Denom} {
+			if info, ok := data.Assets[denom]; ok {
+				assets[denom] = info
+			}
+		}
+
+		bid := LedgerBid{
+			AuctionID: decision.AuctionID,
+			Height:    data.Height,
+			Time:      data.BlockTime,
+			Lot:       decision.Lot,
+			Bid:       decision.Bid,
+			Amount:    *decision.Proposed,
+			Assets:    assets,
+		}
+		if err := l.encoder.Encode(ledgerEntry{Type: ledgerEntryBid, Bid: &bid}); err != nil {
+			return fmt.Errorf("failed to write bid on auction %d: %w", bid.AuctionID, err)
+		}
+
+		l.pending[bid.AuctionID] = bid
+	}
+
+	return nil
+}
+
+// Resolve records the outcome of every pending auction that is no longer open
+// at the height of the provided data, returning the newly closed auctions
+//
+// Auctions that fail to resolve, for example because the node has pruned the
+// required state, remain pending and are retried on the next call.
+func (l *Ledger) Resolve(
+	logger zerolog.Logger,
+	source LedgerSource,
+	keeper sdk.AccAddress,
+	data *AuctionData,
+) []LedgerClose {
+	open := make(map[uint64]bool, len(data.Auctions))
+	for _, auction := range data.Auctions {
+		open[auction.GetID()] = true
+	}
+
+	var resolved []LedgerClose
+	for id, bid := range l.pending {
+		if open[id] {
+			continue
+		}
+
+		closed, err := resolveClose(source, keeper, bid, data.Height)
+		if err != nil {
+			logger.Error().Err(err).Uint64("auctionId", id).Msg("failed to resolve closed auction")
+			continue
+		}
+
+		if err := l.encoder.Encode(ledgerEntry{Type: ledgerEntryClose, Close: &closed}); err != nil {
+			logger.Error().Err(err).Uint64("auctionId", id).Msg("failed to write closed auction")
+			continue
+		}
+		delete(l.pending, id)
+		resolved = append(resolved, closed)
+
+		logger.Info().
+			Uint64("auctionId", id).
+			Bool("won", closed.Won).
+			Str("received", closed.Received.String()).
+			Str("paid", closed.Paid.String()).
+			Str("profitUsd", closed.ProfitUSD().String()).
+			Msg("auction closed")
+	}
+
+	return resolved
+}
+
+// Close closes the underlying file
+func (l *Ledger) Close() error {
+	return l.file.Close()
+}
+
+// resolveClose finds the final state of an auction that existed at the bid
+// height and no longer exists at the closed height
+func resolveClose(source LedgerSource, keeper sdk.AccAddress, bid LedgerBid, closedHeight int64) (LedgerClose, error) {
+	// binary search for the last height the auction existed, the auction is
+	// closed in the begin block of the following height
+	lastOpen, closeHeight := bid.Height, closedHeight
+	var final auctiontypes.Auction
+	for closeHeight-lastOpen > 1 {
+		mid := lastOpen + (closeHeight-lastOpen)/2
+
+		auction, found, err := source.AuctionAtHeight(bid.AuctionID, mid)
+		if err != nil {
+			return LedgerClose{}, err
+		}
+
+		if found {
+			lastOpen, final = mid, auction
+		} else {
+			closeHeight = mid
+		}
+	}
+
+	if final == nil {
+		auction, found, err := source.AuctionAtHeight(bid.AuctionID, lastOpen)
+		if err != nil {
+			return LedgerClose{}, err
+		}
+		if !found {
+			return LedgerClose{}, fmt.Errorf("auction %d not found at bid height %d", bid.AuctionID, lastOpen)
+		}
+		final = auction
+	}
+
+	closeTime, err := source.BlockTimeAtHeight(closeHeight)
+	if err != nil {
+		return LedgerClose{}, err
+	}
+
+	closed := LedgerClose{
+		AuctionID:          bid.AuctionID,
+		CloseHeight:        closeHeight,
+		CloseTime:          closeTime,
+		Winner:             final.GetBidder().String(),
+		Won:                final.GetBidder().Equals(keeper),
+		ReceivedUSDAtBid:   sdk.ZeroDec(),
+		PaidUSDAtBid:       sdk.ZeroDec(),
+		ReceivedUSDAtClose: sdk.ZeroDec(),
+		PaidUSDAtClose:     sdk.ZeroDec(),
+	}
+	if !closed.Won {
+		return closed, nil
+	}
+
+	assets, err := source.AssetInfoAtHeight(closeHeight)
+	if err != nil {
+		return LedgerClose{}, err
+	}
+
+	closed.Received = final.GetLot()
+	closed.Paid = final.GetBid()
+	closed.ReceivedUSDAtBid = ledgerUSDValue(closed.Received, bid.Assets)
+	closed.PaidUSDAtBid = ledgerUSDValue(closed.Paid, bid.Assets)
+	closed.ReceivedUSDAtClose = ledgerUSDValue(closed.Received, assets)
+	closed.PaidUSDAtClose = ledgerUSDValue(closed.Paid, assets)
+
+	return closed, nil
+}
+
+// ledgerUSDValue values a coin, or returns zero if there is no asset info
+func ledgerUSDValue(coin sdk.Coin, assets map[string]AssetInfo) sdk.Dec {
+	info, ok := assets[coin.Denom]
+	if !ok {
+		return sdk.ZeroDec()
+	}
+
+	return calculateUSDValue(coin, info)
+}
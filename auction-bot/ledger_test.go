@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	auctiontypes "github.com/kava-labs/kava/x/auction/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+type mockLedgerSource struct {
+	// auction state by height, missing heights have no auction
+	auctions map[int64]auctiontypes.Auction
+	assets   map[string]AssetInfo
+	start    time.Time
+}
+
+func (s mockLedgerSource) AuctionAtHeight(id uint64, height int64) (auctiontypes.Auction, bool, error) {
+	auction, found := s.auctions[height]
+	return auction, found, nil
+}
+
+func (s mockLedgerSource) AssetInfoAtHeight(height int64) (map[string]AssetInfo, error) {
+	return s.assets, nil
+}
+
+func (s mockLedgerSource) BlockTimeAtHeight(height int64) (time.Time, error) {
+	return s.start.Add(time.Duration(height) * approxBlockSeconds * time.Second), nil
+}
+
+func TestLedger(t *testing.T) {
+	keeper, err := sdk.AccAddressFromBech32("kava10eup8kvq26z8ekjj9rkplr2lwwynskftqc4ytv")
+	require.NoError(t, err)
+	competitor := sdk.AccAddress(bytes.Repeat([]byte{1}, 20))
+
+	start := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	assets := func(bnbPrice string) map[string]AssetInfo {
+		return map[string]AssetInfo{
+			"usdx": {Price: d("1.00"), ConversionFactor: sdk.NewInt(1e6)},
+			"bnb":  {Price: d(bnbPrice), ConversionFactor: sdk.NewInt(1e8)},
+		}
+	}
+	auctionWithBidder := func(id uint64, bidder sdk.AccAddress) auctiontypes.Auction {
+		return &auctiontypes.CollateralAuction{
+			BaseAuction: auctiontypes.BaseAuction{
+				ID:     id,
+				Bidder: bidder,
+				Lot:    c("bnb", 1000e8),
+				Bid:    c("usdx", 176_000e6),
+			},
+			MaxBid: c("usdx", 220_000e6),
+		}
+	}
+	decisions := func(id uint64) BidDecisions {
+		bid := c("usdx", 176_000e6)
+		return BidDecisions{
+			{AuctionID: id, Lot: c("bnb", 1000e8), Bid: c("usdx", 0), Proposed: &bid},
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+	ledger, err := OpenLedger(path)
+	require.NoError(t, err)
+
+	bidData := &AuctionData{Height: 100, BlockTime: start, Assets: assets("200")}
+	require.NoError(t, ledger.RecordBids(bidData, decisions(1)))
+	require.NoError(t, ledger.RecordBids(bidData, decisions(2)))
+
+	// auction 1 was won and last open at height 140, auction 2 was lost
+	won := make(map[int64]auctiontypes.Auction)
+	lost := make(map[int64]auctiontypes.Auction)
+	for height := int64(100); height <= 140; height++ {
+		won[height] = auctionWithBidder(1, keeper)
+		lost[height] = auctionWithBidder(2, competitor)
+	}
+
+	// auction 2 remains open, so is not resolved
+	openData := &AuctionData{Height: 200, Auctions: []auctiontypes.Auction{auctionWithBidder(2, competitor)}}
+	resolved := ledger.Resolve(zerolog.Nop(), mockLedgerSource{auctions: won, assets: assets("190"), start: start}, keeper, openData)
+	require.Len(t, resolved, 1)
+	resolved = ledger.Resolve(zerolog.Nop(), mockLedgerSource{auctions: lost, assets: assets("190"), start: start}, keeper, &AuctionData{Height: 300})
+	require.Len(t, resolved, 1)
+	require.NoError(t, ledger.Close())
+
+	bids, closes, err := ReadLedger(path)
+	require.NoError(t, err)
+	require.Len(t, bids, 2)
+	require.Equal(t, c("usdx", 176_000e6), bids[0].Amount)
+	require.Equal(t, assets("200"), bids[0].Assets)
+
+	require.Len(t, closes, 2)
+	require.Equal(t, LedgerClose{
+		AuctionID:          1,
+		CloseHeight:        141,
+		CloseTime:          start.Add(141 * approxBlockSeconds * time.Second),
+		Winner:             keeper.String(),
+		Won:                true,
+		Received:           c("bnb", 1000e8),
+		Paid:               c("usdx", 176_000e6),
+		ReceivedUSDAtBid:   d("200000"),
+		PaidUSDAtBid:       d("176000"),
+		ReceivedUSDAtClose: d("190000"),
+		PaidUSDAtClose:     d("176000"),
+	}, closes[0])
+	require.Equal(t, d("14000"), closes[0].ProfitUSD())
+
+	require.Equal(t, uint64(2), closes[1].AuctionID)
+	require.False(t, closes[1].Won)
+	require.Equal(t, competitor.String(), closes[1].Winner)
+
+	// resolved auctions are not pending when reopened
+	ledger, err = OpenLedger(path)
+	require.NoError(t, err)
+	require.Empty(t, ledger.pending)
+	require.NoError(t, ledger.Close())
+}
+
+func TestSummarizePnl(t *testing.T) {
+	closed := func(day int, denom string, receivedAtClose string) LedgerClose {
+		return LedgerClose{
+			CloseTime:          time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC),
+			Won:                true,
+			Received:           c(denom, 100),
+			ReceivedUSDAtBid:   d("110"),
+			PaidUSDAtBid:       d("100"),
+			ReceivedUSDAtClose: d(receivedAtClose),
+			PaidUSDAtClose:     d("100"),
+		}
+	}
+
+	closes := []LedgerClose{
+		closed(1, "bnb", "120"),
+		closed(2, "bnb", "90"),
+		closed(2, "btcb", "105"),
+		closed(31, "bnb", "130"),
+		{CloseTime: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Won: false},
+	}
+
+	monthly := SummarizePnl(closes, PnlPeriodMonth)
+	require.Len(t, monthly, 2)
+	require.Equal(t, "2024-01", monthly[0].Period)
+	require.Equal(t, "bnb", monthly[0].Asset)
+	require.Equal(t, 3, monthly[0].Won)
+	require.Equal(t, sdk.NewInt(300), monthly[0].Received)
+	require.Equal(t, d("30"), monthly[0].ProfitAtBid)
+	require.Equal(t, d("40"), monthly[0].ProfitAtClose)
+	require.Equal(t, "btcb", monthly[1].Asset)
+	require.Equal(t, d("5"), monthly[1].ProfitAtClose)
+
+	weekly := SummarizePnl(closes, PnlPeriodWeek)
+	require.Len(t, weekly, 3)
+	require.Equal(t, "2024-W01", weekly[0].Period)
+	require.Equal(t, "2024-W05", weekly[2].Period)
+
+	var report bytes.Buffer
+	require.NoError(t, WritePnlReport(&report, monthly))
+	require.Contains(t, report.String(), "2024-01")
+	require.Contains(t, report.String(), "total")
+}
@@ -3,7 +3,6 @@ package main
 import (
 	"context"
 	"fmt"
-	"math"
 	"os"
 	"time"
 
@@ -11,6 +10,8 @@ import (
 	"github.com/kava-labs/kava/app"
 	"github.com/rs/zerolog"
 
+	rpchttp "github.com/cometbft/cometbft/rpc/client/http"
+	"github.com/cosmos/cosmos-sdk/client/grpc/node"
 	"github.com/cosmos/cosmos-sdk/crypto/hd"
 	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -29,6 +30,25 @@ func main() {
 	//
 	app.SetSDKConfig()
 
+	//
+	// run subcommands to completion instead of bidding
+	//
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "backtest":
+			if err := RunBacktest(logger); err != nil {
+				logger.Fatal().Err(err).Send()
+			}
+		case "pnl":
+			if err := RunPnl(logger); err != nil {
+				logger.Fatal().Err(err).Send()
+			}
+		default:
+			logger.Fatal().Msgf("unknown command %s", os.Args[1])
+		}
+		return
+	}
+
 	//
 	// Load config
 	//
@@ -44,8 +64,13 @@ func main() {
 		Info().
 		Str("chainId", config.KavaChainId).
 		Str("grpcUrl", config.KavaGrpcUrl).
+		Str("rpcUrl", config.KavaRpcUrl).
 		Dur("bidInterval", config.KavaBidInterval).
-		Str("profitMargin", config.ProfitMargin.String()).
+		Str("profitMargin", config.BidPolicy.Margin.String()).
+		Bool("dryRun", config.DryRun).
+		Dur("bidScheduleWindow", config.BidScheduleWindow).
+		Str("baseGasPrice", config.Fee.BaseGasPrice.String()).
+		Str("maxFee", config.Fee.MaxFee.String()).
 		Msg("config loaded")
 
 	//
@@ -88,40 +113,189 @@ func main() {
 		logger,
 	)
 
+	//
+	// settings and state that can be changed at runtime by the admin api
+	//
+	state := NewBotState(config.BidPolicy, config.PriceOverrides)
+
 	startHealthCheckService(
 		context.Background(),
 		logger,
 		config,
 		grpcClient,
 		signer,
+		state,
 	)
 
+	//
+	// price fees from the base gas price, the node minimum and mempool
+	// congestion, escalating for requests that failed before
+	//
+	feePolicy, err := signing.NewFeePolicy(config.Fee)
+	if err != nil {
+		logger.Fatal().Err(err).Send()
+	}
+	feeRetries := signing.NewFeeRetries()
+
+	var nodeClient node.ServiceClient
+	if config.FeeNodeMinGasPrices {
+		nodeClient = grpcClient.Node
+	}
+	var mempoolClient signing.MempoolClient
+	if config.Fee.CongestedMempoolTxs > 0 {
+		mempoolClient, err = rpchttp.New(config.KavaRpcUrl, "/websocket")
+		if err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+	}
+
+	//
+	// optionally sell won collateral back into the bid denom
+	//
+	var disposer *Disposer
+	if config.Disposal != nil {
+		disposer = NewDisposer(*config.Disposal, sdk.AccAddress(privKey.PubKey().Address()))
+	}
+
 	// channels to communicate with signer
 	requests := make(chan signing.MsgRequest)
 
-	// signer starts it's own go routines and returns
-	responses, err := signer.Run(requests)
-	if err != nil {
-		logger.Fatal().Err(err).Msg("failed to start signer")
-	}
+	// in dry run mode the signer is never started and no bids are sent
+	if !config.DryRun {
+		// signer starts it's own go routines and returns
+		responses, err := signer.Run(requests)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("failed to start signer")
+		}
 
-	// log responses, if responses are not read, requests will block
-	go func() {
-		for {
-			// response is not returned until the msg is committed to a block
-			response := <-responses
+		// log responses, if responses are not read, requests will block
+		go func() {
+			for {
+				// response is not returned until the msg is committed to a block
+				response := <-responses
 
-			// error will be set if response is not Code 0 (success) or Code 19 (already in mempool)
-			if response.Err != nil {
-				fmt.Printf("response code: %d error %s\n", response.Result.Code, response.Err)
-				continue
+				if id, ok := requestID(response.Request); ok {
+					state.RemoveInflight(id)
+				}
+
+				// error will be set if response is not Code 0 (success) or Code 19 (already in mempool)
+				if response.Err != nil {
+					fmt.Printf("response code: %d error %s\n", response.Result.Code, response.Err)
+
+					// requests sent again for the same auctions or swaps pay
+					// escalated fees
+					feeRetries.Failed(requestFeeKeys(response.Request)...)
+
+					// failed swaps are retried on a later cycle
+					if swap, ok := response.Request.Data.(swapRequest); ok && disposer != nil {
+						disposer.Failed(logger, swap.Disposal, response.Err)
+					}
+					continue
+				}
+
+				feeRetries.Succeeded(requestFeeKeys(response.Request)...)
+
+				// code and result are from broadcast, not deliver tx
+				// it is up to the caller/requester to check the deliver tx code and deal with failure
+				fmt.Printf("response code: %d, hash %s\n", response.Result.Code, response.Result.TxHash)
+
+				// a committed swap can still fail when delivered, it is queued
+				// again if it did
+				if swap, ok := response.Request.Data.(swapRequest); ok && disposer != nil {
+					go func(disposal Disposal, txHash string) {
+						ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+						defer cancel()
+
+						disposer.Confirm(ctx, logger, grpcClient.Tx, disposal, txHash)
+					}(swap.Disposal, response.Result.TxHash)
+				}
 			}
+		}()
+	}
+
+	var decisionLog *DecisionLog
+	if config.DecisionLogPath != "" {
+		decisionLog, err = OpenDecisionLog(config.DecisionLogPath)
+		if err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+		defer decisionLog.Close()
+
+		logger.Info().
+			Str("path", config.DecisionLogPath).
+			Msg("writing bid decisions")
+	}
+
+	//
+	// record submitted bids and the outcome of closed auctions
+	//
+	var ledger *Ledger
+	if config.LedgerPath != "" {
+		ledger, err = OpenLedger(config.LedgerPath)
+		if err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+		defer ledger.Close()
+
+		logger.Info().
+			Str("path", config.LedgerPath).
+			Msg("recording bids to ledger")
+	}
+	ledgerSource := NewGrpcLedgerSource(grpcClient)
+
+	//
+	// check prices are fresh and agree with the twap, and optionally an
+	// external reference, before bidding
+	//
+	var referencePrices ReferencePriceSource
+	if len(config.ReferencePriceSymbols) > 0 {
+		referencePrices = NewAscendexReferenceSource(config.ReferencePriceSymbols)
+	}
+	priceGuard := NewPriceGuard(config.PriceGuard, NewGrpcPriceGuardSource(grpcClient), referencePrices)
+
+	//
+	// optionally discount lot values for price movement until auctions close
+	//
+	var volatilityModel *VolatilityModel
+	if config.Volatility != nil {
+		volatilityModel = NewVolatilityModel(*config.Volatility, NewGrpcVolatilitySource(grpcClient))
+	}
+
+	//
+	// optionally hold bids until shortly before each auction ends
+	//
+	var scheduler *BidScheduler
+	if config.BidScheduleWindow > 0 {
+		scheduler = NewBidScheduler(config.BidScheduleWindow)
+	}
+
+	//
+	// subscribe to auction events to run bid cycles as soon as something
+	// changes, the bid interval remains as a backstop
+	//
+	var watcher *AuctionEventWatcher
+	var cycleTriggers <-chan struct{}
+	if config.KavaRpcUrl != "" {
+		watcher, err = NewAuctionEventWatcher(
+			config.KavaRpcUrl,
+			sdk.AccAddress(privKey.PubKey().Address()),
+			config.AuctionEndWindow,
+			logger,
+		)
+		if err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+
+		if scheduler != nil {
+			watcher.OnBlock(scheduler.ObserveBlock)
+		}
 
-			// code and result are from broadcast, not deliver tx
-			// it is up to the caller/requester to check the deliver tx code and deal with failure
-			fmt.Printf("response code: %d, hash %s\n", response.Result.Code, response.Result.TxHash)
+		if err := watcher.Run(context.Background()); err != nil {
+			logger.Error().Err(err).Msg("failed to subscribe to auction events, bidding on interval only")
+		} else {
+			cycleTriggers = watcher.Triggers()
 		}
-	}()
+	}
 
 	priceErrors := 0
 	for {
@@ -134,6 +308,9 @@ func main() {
 
 			priceErrors += 1
 			logger.Debug().Err(err).Msg("failed to fetch auction data")
+			// upstream failures end the cycle, liveness only tracks the loop
+			// while readiness reports the node
+			state.CycleCompleted(time.Now())
 			time.Sleep(time.Second * 5)
 			continue
 		}
@@ -141,28 +318,87 @@ func main() {
 		logger.Info().Msgf("fetched prices after %d attempt(s)\n", priceErrors+1)
 		priceErrors = 0
 
-		// apply price overrides
-		for denom, price := range config.PriceOverrides {
+		if watcher != nil {
+			watcher.Track(data.Auctions)
+		}
+
+		if ledger != nil {
+			closed := ledger.Resolve(logger, ledgerSource, sdk.AccAddress(privKey.PubKey().Address()), data)
+			if disposer != nil {
+				for _, c := range closed {
+					disposer.AddWon(c)
+				}
+			}
+		}
+
+		data.PriceErrors, err = priceGuard.Check(logger, data)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to check prices, retrying")
+			state.CycleCompleted(time.Now())
+			time.Sleep(time.Second * 5)
+			continue
+		}
+
+		// apply price overrides, overridden prices are trusted
+		for denom, price := range state.PriceOverrides() {
 			info := data.Assets[denom]
 			info.Price = price
 			data.Assets[denom] = info
+			delete(data.PriceErrors, denom)
+		}
+
+		for denom, err := range data.PriceErrors {
+			logger.Warn().Err(err).Str("denom", denom).Msg("unsafe price, skipping auctions")
+		}
+
+		if volatilityModel != nil {
+			data.LotHaircuts, err = volatilityModel.Haircuts(logger, data)
+			if err != nil {
+				logger.Error().Err(err).Msg("failed to estimate volatility, retrying")
+				state.CycleCompleted(time.Now())
+				time.Sleep(time.Second * 5)
+				continue
+			}
 		}
 
 		latestHeight, err := grpcClient.LatestHeight()
 		if err != nil {
+			logger.Error().Err(err).Msg("failed to fetch latest height, retrying")
+			state.CycleCompleted(time.Now())
+			time.Sleep(time.Second * 5)
 			continue
 		}
 
 		logger.Info().Msgf("latest height: %d", latestHeight)
 		logger.Info().Msgf("checking %d auctions", len(data.Auctions))
 
-		auctionBids := GetBids(
+		decisions := GetBidDecisions(
 			logger,
 			data,
 			sdk.AccAddress(privKey.PubKey().Address()),
-			config.ProfitMargin,
+			state.Policy(),
 		)
 
+		// bids not yet due are held, the next cycle runs when the first is due
+		// or earlier if another account bids
+		nextCycle := config.KavaBidInterval
+		if scheduler != nil {
+			var wake time.Duration
+			scheduler.ObserveBlock(data.Height, data.BlockTime)
+			decisions, wake = scheduler.Schedule(decisions, data.BlockTime)
+			if wake > 0 && wake < nextCycle {
+				nextCycle = wake
+			}
+		}
+		auctionBids := decisions.Bids()
+		state.SetDecisions(decisions)
+
+		if decisionLog != nil {
+			if err := decisionLog.Write(decisions); err != nil {
+				logger.Error().Err(err).Msg("failed to write bid decisions")
+			}
+		}
+
 		msgs := CreateBidMsgs(sdk.AccAddress(privKey.PubKey().Address()), auctionBids)
 		logger.Info().Msgf("creating %d bids", len(msgs))
 
@@ -183,11 +419,30 @@ func main() {
 			}
 		}
 
+		if config.DryRun {
+			logger.Info().Msgf("dry run, not sending %d bids", len(msgs))
+			state.CycleCompleted(time.Now())
+			waitForCycle(cycleTriggers, state.Forced(), nextCycle)
+			continue
+		}
+
+		if state.Paused() {
+			logger.Warn().Msgf("bidding paused, not sending %d bids", len(msgs))
+			state.CycleCompleted(time.Now())
+			waitForCycle(cycleTriggers, state.Forced(), nextCycle)
+			continue
+		}
+
+		if ledger != nil {
+			if err := ledger.RecordBids(data, decisions); err != nil {
+				logger.Error().Err(err).Msg("failed to record bids to ledger")
+			}
+		}
+
 		// gas limit of one bit
 		gasBaseLimit := uint64(300000)
 
-		// max gas price to get into any block
-		gasPrice := 0.05
+		updateFeePolicy(logger, feePolicy, nodeClient, mempoolClient)
 
 		// aggregator for msgs between loops
 		msgBatch := []sdk.Msg{}
@@ -211,21 +466,59 @@ func main() {
 				// reset batch
 				msgBatch = []sdk.Msg{}
 
+				batch := bidRequest{AuctionIDs: make([]uint64, batchSize)}
+				for j, batchMsg := range msgs[i+1-batchSize : i+1] {
+					batch.AuctionIDs[j] = batchMsg.AuctionId
+				}
+
 				// add up total gas for tx
 				gasLimit := gasBaseLimit * uint64(batchSize)
-				// calculate gas fee amount, ceil so we stay over limit on rounding
-				feeAmount := int64(math.Ceil(float64(gasLimit) * gasPrice))
+				fee := feePolicy.Fee(gasLimit, feeRetries.Retries(batch.FeeKeys()...))
+
+				batch.ID = state.AddInflight(InflightRequest{
+					AuctionIDs: batch.AuctionIDs,
+					Fee:        fee,
+					SentAt:     time.Now(),
+				})
 
 				requests <- signing.MsgRequest{
 					Msgs:      requestMsgBatch,
 					GasLimit:  gasLimit,
-					FeeAmount: sdk.NewCoins(sdk.NewCoin("ukava", sdk.NewInt(feeAmount))),
+					FeeAmount: fee,
 					Memo:      "",
+					Data:      batch,
 				}
 			}
 		}
 
-		// wait for next interval
-		time.Sleep(config.KavaBidInterval)
+		if disposer != nil {
+			disposals, swaps := disposer.Swaps(logger, data)
+			for i, swap := range swaps {
+				logger.Info().
+					Str("sell", swap.ExactTokenA.String()).
+					Str("expected", swap.TokenB.String()).
+					Msg("disposing of won collateral")
+
+				fee := feePolicy.Fee(gasBaseLimit, feeRetries.Retries(disposals[i].FeeKeys()...))
+				id := state.AddInflight(InflightRequest{
+					Swap:   &disposals[i],
+					Fee:    fee,
+					SentAt: time.Now(),
+				})
+
+				requests <- signing.MsgRequest{
+					Msgs:      []sdk.Msg{swap},
+					GasLimit:  gasBaseLimit,
+					FeeAmount: fee,
+					Memo:      "",
+					Data:      swapRequest{ID: id, Disposal: disposals[i]},
+				}
+			}
+		}
+
+		state.CycleCompleted(time.Now())
+
+		// wait for next interval, scheduled bid, auction event or forced cycle
+		waitForCycle(cycleTriggers, state.Forced(), nextCycle)
 	}
 }
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+)
+
+// PnlPeriod is the length of time won auctions are grouped by
+type PnlPeriod string
+
+const (
+	PnlPeriodDay   PnlPeriod = "day"
+	PnlPeriodWeek  PnlPeriod = "week"
+	PnlPeriodMonth PnlPeriod = "month"
+)
+
+func (p PnlPeriod) Validate() error {
+	switch p {
+	case PnlPeriodDay, PnlPeriodWeek, PnlPeriodMonth:
+		return nil
+	default:
+		return fmt.Errorf("unknown period %q", p)
+	}
+}
+
+// Key returns the period containing t, formatted so keys sort in time order
+func (p PnlPeriod) Key(t time.Time) string {
+	t = t.UTC()
+
+	switch p {
+	case PnlPeriodDay:
+		return t.Format("2006-01-02")
+	case PnlPeriodWeek:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	default:
+		return t.Format("2006-01")
+	}
+}
+
+// PnlRow is the realized profit of auctions won for a single lot asset within
+// a period, all values are in USD
+type PnlRow struct {
+	Period          string
+	Asset           string
+	Won             int
+	Received        sdk.Int
+	PaidAtBid       sdk.Dec
+	ReceivedAtBid   sdk.Dec
+	PaidAtClose     sdk.Dec
+	ReceivedAtClose sdk.Dec
+	ProfitAtBid     sdk.Dec
+	ProfitAtClose   sdk.Dec
+}
+
+// SummarizePnl groups won auctions by period and lot asset, rows are sorted
+// by period then asset
+func SummarizePnl(closes []LedgerClose, period PnlPeriod) []PnlRow {
+	rows := make(map[[2]string]*PnlRow)
+
+	for _, closed := range closes {
+		if !closed.Won {
+			continue
+		}
+
+		key := [2]string{period.Key(closed.CloseTime), closed.Received.Denom}
+		row, found := rows[key]
+		if !found {
+			row = &PnlRow{
+				Period:          key[0],
+				Asset:           key[1],
+				Received:        sdk.ZeroInt(),
+				PaidAtBid:       sdk.ZeroDec(),
+				ReceivedAtBid:   sdk.ZeroDec(),
+				PaidAtClose:     sdk.ZeroDec(),
+				ReceivedAtClose: sdk.ZeroDec(),
+			}
+			rows[key] = row
+		}
+
+		row.Won++
+		row.Received = row.Received.Add(closed.Received.Amount)
+		row.PaidAtBid = row.PaidAtBid.Add(closed.PaidUSDAtBid)
+		row.ReceivedAtBid = row.ReceivedAtBid.Add(closed.ReceivedUSDAtBid)
+		row.PaidAtClose = row.PaidAtClose.Add(closed.PaidUSDAtClose)
+		row.ReceivedAtClose = row.ReceivedAtClose.Add(closed.ReceivedUSDAtClose)
+	}
+
+	summary := make([]PnlRow, 0, len(rows))
+	for _, row := range rows {
+		row.ProfitAtBid = row.ReceivedAtBid.Sub(row.PaidAtBid)
+		row.ProfitAtClose = row.ReceivedAtClose.Sub(row.PaidAtClose)
+		summary = append(summary, *row)
+	}
+
+	sort.Slice(summary, func(i, j int) bool {
+		if summary[i].Period != summary[j].Period {
+			return summary[i].Period < summary[j].Period
+		}
+		return summary[i].Asset < summary[j].Asset
+	})
+
+	return summary
+}
+
+// WritePnlReport writes the summary as an aligned table with a total row
+func WritePnlReport(w io.Writer, rows []PnlRow) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintln(tw, "period\tasset\twon\treceived\tpaid usd\treceived usd at bid\treceived usd at close\tprofit usd at bid\tprofit usd at close\t")
+
+	won := 0
+	totalAtBid, totalAtClose := sdk.ZeroDec(), sdk.ZeroDec()
+	for _, row := range rows {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			row.Period,
+			row.Asset,
+			row.Won,
+			row.Received,
+			formatUSD(row.PaidAtClose),
+			formatUSD(row.ReceivedAtBid),
+			formatUSD(row.ReceivedAtClose),
+			formatUSD(row.ProfitAtBid),
+			formatUSD(row.ProfitAtClose),
+		)
+
+		won += row.Won
+		totalAtBid = totalAtBid.Add(row.ProfitAtBid)
+		totalAtClose = totalAtClose.Add(row.ProfitAtClose)
+	}
+
+	fmt.Fprintf(tw, "total\t\t%d\t\t\t\t\t%s\t%s\t\n", won, formatUSD(totalAtBid), formatUSD(totalAtClose))
+
+	return tw.Flush()
+}
+
+func formatUSD(value sdk.Dec) string {
+	return value.RoundInt().String()
+}
+
+// RunPnl prints the realized profit of won auctions recorded in the ledger
+func RunPnl(logger zerolog.Logger) error {
+	config, err := LoadPnlConfig(&EnvLoader{})
+	if err != nil {
+		return err
+	}
+
+	_, closes, err := ReadLedger(config.LedgerPath)
+	if err != nil {
+		return err
+	}
+
+	logger.Debug().
+		Str("ledger", config.LedgerPath).
+		Int("closed", len(closes)).
+		Msg("ledger loaded")
+
+	return WritePnlReport(os.Stdout, SummarizePnl(closes, config.Period))
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// BidSolver selects how proposals are calculated from the required margin
+type BidSolver string
+
+const (
+	// ExactSolver proposes the most competitive valid bid that meets the margin
+	ExactSolver BidSolver = "exact"
+	// LadderSolver proposes the first of a fixed list of fractions of the max
+	// bid or lot that meets the margin
+	LadderSolver BidSolver = "ladder"
+)
+
+// BidPolicy restricts which auctions are bid on and sets the minimum margin
+// required for each
+type BidPolicy struct {
+	// Margin is the minimum margin required for every auction
+	Margin sdk.Dec `json:"margin"`
+	// LotDenomMargins are minimum margins for auctions by lot denom
+	LotDenomMargins map[string]sdk.Dec `json:"lot_denom_margins,omitempty"`
+	// BidDenomMargins are minimum margins for auctions by bid denom
+	BidDenomMargins map[string]sdk.Dec `json:"bid_denom_margins,omitempty"`
+	// AllowedDenoms restricts bidding to auctions where both the lot and bid
+	// denom are listed, all denoms are allowed when empty
+	AllowedDenoms []string `json:"allowed_denoms,omitempty"`
+	// DeniedDenoms skips auctions where either the lot or bid denom is listed
+	DeniedDenoms []string `json:"denied_denoms,omitempty"`
+	// MaxLotUSDValue skips auctions with a lot worth more than this, if set
+	MaxLotUSDValue *sdk.Dec `json:"max_lot_usd_value,omitempty"`
+	// Solver calculates proposals, the exact solver is used when empty
+	Solver BidSolver `json:"solver,omitempty"`
+}
+
+// NewBidPolicy returns a policy applying a single margin to all auctions,
+// denying UST
+func NewBidPolicy(margin sdk.Dec) BidPolicy {
+	return BidPolicy{
+		Margin:       margin,
+		DeniedDenoms: []string{USTDenom},
+	}
+}
+
+// LoadBidPolicy reads a json policy file, fields missing from the file keep the
+// values of the provided default policy
+func LoadBidPolicy(path string, defaults BidPolicy) (BidPolicy, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return BidPolicy{}, fmt.Errorf("failed to read bid policy: %w", err)
+	}
+
+	return ParseBidPolicy(bz, defaults)
+}
+
+// ParseBidPolicy reads a json policy, fields missing from the json keep the
+// values of the provided default policy
+func ParseBidPolicy(bz []byte, defaults BidPolicy) (BidPolicy, error) {
+	// json decodes into existing maps and slices, so copy them to leave the
+	// defaults unchanged
+	policy := defaults.clone()
+	if err := json.Unmarshal(bz, &policy); err != nil {
+		return BidPolicy{}, fmt.Errorf("invalid bid policy json: %w", err)
+	}
+
+	return policy, nil
+}
+
+func (p BidPolicy) clone() BidPolicy {
+	cloneMargins := func(margins map[string]sdk.Dec) map[string]sdk.Dec {
+		if margins == nil {
+			return nil
+		}
+		cloned := make(map[string]sdk.Dec, len(margins))
+		for denom, margin := range margins {
+			cloned[denom] = margin
+		}
+		return cloned
+	}
+
+	p.LotDenomMargins = cloneMargins(p.LotDenomMargins)
+	p.BidDenomMargins = cloneMargins(p.BidDenomMargins)
+	p.AllowedDenoms = append([]string(nil), p.AllowedDenoms...)
+	p.DeniedDenoms = append([]string(nil), p.DeniedDenoms...)
+
+	return p
+}
+
+// Validate returns an error if any margin is outside [0, 1), a denom is both
+// allowed and denied, the max lot value is not positive or the solver is
+// unknown
+func (p BidPolicy) Validate() error {
+	if err := validateMargin(p.Margin); err != nil {
+		return fmt.Errorf("margin %w", err)
+	}
+
+	for denom, margin := range p.LotDenomMargins {
+		if err := validateMargin(margin); err != nil {
+			return fmt.Errorf("lot denom %s margin %w", denom, err)
+		}
+	}
+
+	for denom, margin := range p.BidDenomMargins {
+		if err := validateMargin(margin); err != nil {
+			return fmt.Errorf("bid denom %s margin %w", denom, err)
+		}
+	}
+
+	denied := make(map[string]bool)
+	for _, denom := range p.DeniedDenoms {
+		denied[denom] = true
+	}
+	for _, denom := range p.AllowedDenoms {
+		if denied[denom] {
+			return fmt.Errorf("denom %s is both allowed and denied", denom)
+		}
+	}
+
+	if p.MaxLotUSDValue != nil && !p.MaxLotUSDValue.IsPositive() {
+		return fmt.Errorf("max lot usd value must be positive, got %s", p.MaxLotUSDValue)
+	}
+
+	switch p.Solver {
+	case "", ExactSolver, LadderSolver:
+	default:
+		return fmt.Errorf("unknown solver %q", p.Solver)
+	}
+
+	return nil
+}
+
+func validateMargin(margin sdk.Dec) error {
+	if margin.IsNil() {
+		return fmt.Errorf("not set")
+	}
+	if margin.IsNegative() || margin.GTE(sdk.OneDec()) {
+		return fmt.Errorf("must be in [0, 1), got %s", margin)
+	}
+
+	return nil
+}
+
+// Allows returns true if the denom may be bid on or bid with
+func (p BidPolicy) Allows(denom string) bool {
+	for _, denied := range p.DeniedDenoms {
+		if denied == denom {
+			return false
+		}
+	}
+
+	if len(p.AllowedDenoms) == 0 {
+		return true
+	}
+
+	for _, allowed := range p.AllowedDenoms {
+		if allowed == denom {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarginFor returns the largest margin that applies to an auction
+func (p BidPolicy) MarginFor(lotDenom, bidDenom string) sdk.Dec {
+	margin := p.Margin
+
+	if lotMargin, ok := p.LotDenomMargins[lotDenom]; ok {
+		margin = sdk.MaxDec(margin, lotMargin)
+	}
+	if bidMargin, ok := p.BidDenomMargins[bidDenom]; ok {
+		margin = sdk.MaxDec(margin, bidMargin)
+	}
+
+	return margin
+}
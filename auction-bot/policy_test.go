@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadBidPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	err := os.WriteFile(path, []byte(`{
+		"lot_denom_margins": {"bnb": "0.1"},
+		"allowed_denoms": ["bnb", "usdx"],
+		"max_lot_usd_value": "50000",
+		"solver": "ladder"
+	}`), 0o644)
+	require.NoError(t, err)
+
+	policy, err := LoadBidPolicy(path, NewBidPolicy(d("0.05")))
+	require.NoError(t, err)
+	require.NoError(t, policy.Validate())
+
+	// omitted fields keep their defaults
+	require.Equal(t, d("0.05"), policy.Margin)
+	require.Equal(t, []string{USTDenom}, policy.DeniedDenoms)
+	require.Equal(t, d("50000"), *policy.MaxLotUSDValue)
+	require.Equal(t, LadderSolver, policy.Solver)
+
+	require.Equal(t, d("0.1"), policy.MarginFor("bnb", "usdx"))
+	require.Equal(t, d("0.05"), policy.MarginFor("usdx", "bnb"))
+	require.True(t, policy.Allows("bnb"))
+	require.False(t, policy.Allows("xrp"))
+	require.False(t, policy.Allows(USTDenom))
+
+	_, err = LoadBidPolicy(filepath.Join(t.TempDir(), "missing.json"), NewBidPolicy(d("0.05")))
+	require.Error(t, err)
+}
+
+func TestBidPolicyValidate(t *testing.T) {
+	negative := d("-1")
+
+	testCases := []struct {
+		name   string
+		policy BidPolicy
+		errMsg string
+	}{
+		{
+			name:   "default policy",
+			policy: NewBidPolicy(d("0.05")),
+		},
+		{
+			name:   "margin not set",
+			policy: BidPolicy{},
+			errMsg: "margin not set",
+		},
+		{
+			name:   "margin of one",
+			policy: NewBidPolicy(d("1")),
+			errMsg: "margin must be in [0, 1)",
+		},
+		{
+			name: "negative bid denom margin",
+			policy: BidPolicy{
+				Margin:          d("0.05"),
+				BidDenomMargins: map[string]sdk.Dec{"usdx": d("-0.1")},
+			},
+			errMsg: "bid denom usdx margin must be in [0, 1)",
+		},
+		{
+			name: "denom allowed and denied",
+			policy: BidPolicy{
+				Margin:        d("0.05"),
+				AllowedDenoms: []string{USTDenom},
+				DeniedDenoms:  []string{USTDenom},
+			},
+			errMsg: "both allowed and denied",
+		},
+		{
+			name: "negative max lot value",
+			policy: BidPolicy{
+				Margin:         d("0.05"),
+				MaxLotUSDValue: &negative,
+			},
+			errMsg: "max lot usd value must be positive",
+		},
+		{
+			name: "unknown solver",
+			policy: BidPolicy{
+				Margin: d("0.05"),
+				Solver: "greedy",
+			},
+			errMsg: `unknown solver "greedy"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.Validate()
+			if tc.errMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.errMsg)
+			}
+		})
+	}
+}
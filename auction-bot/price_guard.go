@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	pricefeedtypes "github.com/kava-labs/kava/x/pricefeed/types"
+	"github.com/rs/zerolog"
+)
+
+// suffix of the pricefeed market with the 30 minute time weighted average
+const twapMarketSuffix = ":30"
+
+// PriceGuardConfig sets the limits prices must be within to be bid with
+type PriceGuardConfig struct {
+	// MaxTwapDivergence is the max fraction the spot price may differ from
+	// the :30 twap price
+	MaxTwapDivergence sdk.Dec
+	// MinValidOracles is the minimum number of unexpired raw oracle prices
+	MinValidOracles int
+	// MaxReferenceDivergence is the max fraction the spot price may differ
+	// from the reference price, if a reference source is used
+	MaxReferenceDivergence sdk.Dec
+}
+
+// PriceGuardSource provides the pricefeed state checked by the price guard
+type PriceGuardSource interface {
+	CurrentPrices(height int64) (map[string]sdk.Dec, error)
+	RawPrices(marketID string, height int64) (pricefeedtypes.PostedPriceResponses, error)
+}
+
+// ReferencePriceSource provides usd prices from outside the chain
+type ReferencePriceSource interface {
+	// Price returns the usd price of a denom, or false if the source has no
+	// price for it
+	Price(denom string) (sdk.Dec, bool, error)
+}
+
+// PriceGuard finds assets with stale or divergent prices before bidding, so
+// auctions are not bid on while the oracle lags the market
+type PriceGuard struct {
+	config    PriceGuardConfig
+	source    PriceGuardSource
+	reference ReferencePriceSource
+}
+
+// NewPriceGuard creates a guard, reference may be nil to skip comparing
+// against external prices
+func NewPriceGuard(config PriceGuardConfig, source PriceGuardSource, reference ReferencePriceSource) *PriceGuard {
+	return &PriceGuard{
+		config:    config,
+		source:    source,
+		reference: reference,
+	}
+}
+
+// Check returns an error for every asset in data with a price that should
+// not be bid with
+func (g *PriceGuard) Check(logger zerolog.Logger, data *AuctionData) (map[string]error, error) {
+	prices, err := g.source.CurrentPrices(data.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	priceErrors := make(map[string]error)
+	for denom, info := range data.Assets {
+		if err := g.checkAsset(logger, denom, info, prices, data); err != nil {
+			priceErrors[denom] = err
+		}
+	}
+
+	return priceErrors, nil
+}
+
+func (g *PriceGuard) checkAsset(
+	logger zerolog.Logger,
+	denom string,
+	info AssetInfo,
+	prices map[string]sdk.Dec,
+	data *AuctionData,
+) error {
+	if twap, ok := prices[info.SpotMarketID+twapMarketSuffix]; ok {
+		if divergence := priceDivergence(info.Price, twap); divergence.GT(g.config.MaxTwapDivergence) {
+			return fmt.Errorf("spot price %s diverges %s from twap price %s", info.Price, divergence, twap)
+		}
+	}
+
+	rawPrices, err := g.source.RawPrices(info.SpotMarketID, data.Height)
+	if err != nil {
+		return fmt.Errorf("failed to fetch raw prices: %w", err)
+	}
+
+	valid := 0
+	for _, rawPrice := range rawPrices {
+		if rawPrice.Expiry.After(data.BlockTime) {
+			valid++
+		}
+	}
+	if valid < g.config.MinValidOracles {
+		return fmt.Errorf("%d of %d oracle prices unexpired, need %d", valid, len(rawPrices), g.config.MinValidOracles)
+	}
+
+	if g.reference == nil {
+		return nil
+	}
+
+	reference, found, err := g.reference.Price(denom)
+	if err != nil {
+		// an unavailable reference should not stop bidding when the
+		// oracle is otherwise healthy
+		logger.Warn().Err(err).Str("denom", denom).Msg("failed to fetch reference price")
+		return nil
+	}
+	if !found {
+		return nil
+	}
+
+	if divergence := priceDivergence(info.Price, reference); divergence.GT(g.config.MaxReferenceDivergence) {
+		return fmt.Errorf("spot price %s diverges %s from reference price %s", info.Price, divergence, reference)
+	}
+
+	return nil
+}
+
+// priceDivergence returns the absolute difference between a price and a
+// reference as a fraction of the reference
+func priceDivergence(price, reference sdk.Dec) sdk.Dec {
+	if !reference.IsPositive() {
+		return sdk.OneDec()
+	}
+
+	return price.Sub(reference).Abs().Quo(reference)
+}
+
+// GrpcPriceGuardSource reads pricefeed state from a node
+type GrpcPriceGuardSource struct {
+	client GrpcClient
+}
+
+var _ PriceGuardSource = (*GrpcPriceGuardSource)(nil)
+
+func NewGrpcPriceGuardSource(client GrpcClient) *GrpcPriceGuardSource {
+	return &GrpcPriceGuardSource{client: client}
+}
+
+func (s *GrpcPriceGuardSource) CurrentPrices(height int64) (map[string]sdk.Dec, error) {
+	res, err := s.client.Pricefeed.Prices(ctxAtHeight(height), &pricefeedtypes.QueryPricesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch prices: %w", err)
+	}
+
+	prices := make(map[string]sdk.Dec, len(res.Prices))
+	for _, price := range res.Prices {
+		prices[price.MarketID] = price.Price
+	}
+
+	return prices, nil
+}
+
+func (s *GrpcPriceGuardSource) RawPrices(marketID string, height int64) (pricefeedtypes.PostedPriceResponses, error) {
+	res, err := s.client.Pricefeed.RawPrices(ctxAtHeight(height), &pricefeedtypes.QueryRawPricesRequest{MarketId: marketID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch raw prices for %s: %w", marketID, err)
+	}
+
+	return res.RawPrices, nil
+}
+
+var ascendexBaseUrl = "https://ascendex.com/api/pro/v1"
+
+// AscendexTicker is the summary of a symbol returned by the AscendEX ticker api
+type AscendexTicker struct {
+	Symbol string  `json:"symbol"`
+	Close  sdk.Dec `json:"close"`
+}
+
+type ascendexTickerResponse struct {
+	Code int64          `json:"code"`
+	Data AscendexTicker `json:"data"`
+}
+
+// AscendexReferenceSource provides reference prices from AscendEX tickers,
+// quoted in USDT which is treated as one usd
+type AscendexReferenceSource struct {
+	client *http.Client
+	// symbols maps denoms to AscendEX symbols, for example "bnb" to "BNB/USDT"
+	symbols map[string]string
+}
+
+var _ ReferencePriceSource = (*AscendexReferenceSource)(nil)
+
+func NewAscendexReferenceSource(symbols map[string]string) *AscendexReferenceSource {
+	return &AscendexReferenceSource{
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		symbols: symbols,
+	}
+}
+
+func (s *AscendexReferenceSource) Price(denom string) (sdk.Dec, bool, error) {
+	symbol, ok := s.symbols[denom]
+	if !ok {
+		return sdk.Dec{}, false, nil
+	}
+
+	res, err := s.client.Get(fmt.Sprintf("%v/ticker?symbol=%s", ascendexBaseUrl, symbol))
+	if err != nil {
+		return sdk.Dec{}, false, err
+	}
+	defer res.Body.Close()
+
+	var tickerRes ascendexTickerResponse
+	if err := json.NewDecoder(res.Body).Decode(&tickerRes); err != nil {
+		return sdk.Dec{}, false, err
+	}
+	if tickerRes.Code != 0 {
+		return sdk.Dec{}, false, fmt.Errorf("ascendex ticker %s returned code %d", symbol, tickerRes.Code)
+	}
+
+	return tickerRes.Data.Close, true, nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	auctiontypes "github.com/kava-labs/kava/x/auction/types"
+	pricefeedtypes "github.com/kava-labs/kava/x/pricefeed/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPriceGuardSource struct {
+	prices    map[string]sdk.Dec
+	rawPrices map[string]pricefeedtypes.PostedPriceResponses
+}
+
+func (s mockPriceGuardSource) CurrentPrices(height int64) (map[string]sdk.Dec, error) {
+	return s.prices, nil
+}
+
+func (s mockPriceGuardSource) RawPrices(marketID string, height int64) (pricefeedtypes.PostedPriceResponses, error) {
+	return s.rawPrices[marketID], nil
+}
+
+type mockReferenceSource map[string]sdk.Dec
+
+func (s mockReferenceSource) Price(denom string) (sdk.Dec, bool, error) {
+	if denom == "hard" {
+		return sdk.Dec{}, false, errors.New("unavailable")
+	}
+	price, found := s[denom]
+	return price, found, nil
+}
+
+func TestPriceGuard(t *testing.T) {
+	blockTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rawPrices := func(marketID string, expiries ...time.Duration) pricefeedtypes.PostedPriceResponses {
+		var prices pricefeedtypes.PostedPriceResponses
+		for _, expiry := range expiries {
+			prices = append(prices, pricefeedtypes.PostedPriceResponse{MarketID: marketID, Expiry: blockTime.Add(expiry)})
+		}
+		return prices
+	}
+
+	data := &AuctionData{
+		Height:    100,
+		BlockTime: blockTime,
+		Assets: map[string]AssetInfo{
+			"usdx": {Price: d("1.00"), ConversionFactor: sdk.NewInt(1e6), SpotMarketID: "usdx:usd"},
+			"bnb":  {Price: d("200"), ConversionFactor: sdk.NewInt(1e8), SpotMarketID: "bnb:usd"},
+			"btcb": {Price: d("40000"), SpotMarketID: "btc:usd"},
+			"xrpb": {Price: d("0.5"), SpotMarketID: "xrp:usd"},
+			"hard": {Price: d("0.2"), SpotMarketID: "hard:usd"},
+		},
+	}
+	source := mockPriceGuardSource{
+		prices: map[string]sdk.Dec{
+			"usdx:usd:30": d("1.01"),
+			// spot is 10% above twap
+			"bnb:usd:30":  d("181.81"),
+			"btc:usd:30":  d("40100"),
+			"xrp:usd:30":  d("0.5"),
+			"hard:usd:30": d("0.2"),
+		},
+		rawPrices: map[string]pricefeedtypes.PostedPriceResponses{
+			"usdx:usd": rawPrices("usdx:usd", time.Hour, -time.Hour),
+			"bnb:usd":  rawPrices("bnb:usd", time.Hour),
+			"btc:usd":  rawPrices("btc:usd", time.Hour),
+			// all oracle prices expired
+			"xrp:usd":  rawPrices("xrp:usd", -time.Minute, -time.Hour),
+			"hard:usd": rawPrices("hard:usd", time.Hour),
+		},
+	}
+	config := PriceGuardConfig{
+		MaxTwapDivergence:      d("0.05"),
+		MinValidOracles:        1,
+		MaxReferenceDivergence: d("0.05"),
+	}
+
+	guard := NewPriceGuard(config, source, nil)
+	priceErrors, err := guard.Check(zerolog.Nop(), data)
+	require.NoError(t, err)
+	require.Len(t, priceErrors, 2)
+	require.ErrorContains(t, priceErrors["bnb"], "diverges")
+	require.ErrorContains(t, priceErrors["xrpb"], "0 of 2 oracle prices unexpired")
+
+	// btc is 10% below the reference, reference errors do not fail the check
+	guard = NewPriceGuard(config, source, mockReferenceSource{"btcb": d("44444"), "usdx": d("1.00")})
+	priceErrors, err = guard.Check(zerolog.Nop(), data)
+	require.NoError(t, err)
+	require.Len(t, priceErrors, 3)
+	require.ErrorContains(t, priceErrors["btcb"], "reference price")
+
+	// auctions with an unsafe lot or bid price are skipped
+	data.PriceErrors = priceErrors
+	data.BidIncrement = d("0.01")
+	data.Auctions = []auctiontypes.Auction{
+		&auctiontypes.CollateralAuction{
+			BaseAuction: auctiontypes.BaseAuction{ID: 1, Lot: c("bnb", 1000e8), Bid: c("usdx", 0)},
+			MaxBid:      c("usdx", 220_000e6),
+		},
+	}
+	decisions := GetBidDecisions(zerolog.Nop(), data, sdk.AccAddress{}, NewBidPolicy(d("0.05")))
+	require.Len(t, decisions, 1)
+	require.Contains(t, decisions[0].SkipReason, errUnsafePrice.Error())
+	require.Empty(t, decisions.Bids())
+}
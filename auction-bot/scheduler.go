@@ -0,0 +1,143 @@
+package main
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	auctiontypes "github.com/kava-labs/kava/x/auction/types"
+)
+
+const (
+	// number of recent block intervals used to estimate block time
+	blockTimeSamples = 50
+	// blocks needed for a broadcast bid to be included, one to reach the
+	// mempool of the proposer and one to be committed
+	bidInclusionBlocks = 2
+	// standard deviations above the mean block time that are planned for
+	blockTimeDeviations = 3
+)
+
+// BlockTimeEstimator estimates an upper bound on the time between blocks
+// from observed block heights and times
+type BlockTimeEstimator struct {
+	mu         sync.Mutex
+	lastHeight int64
+	lastTime   time.Time
+	intervals  []float64
+}
+
+func NewBlockTimeEstimator() *BlockTimeEstimator {
+	return &BlockTimeEstimator{}
+}
+
+// Observe records a block, blocks older than the last observed are ignored
+func (e *BlockTimeEstimator) Observe(height int64, blockTime time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if height <= e.lastHeight {
+		return
+	}
+
+	if e.lastHeight != 0 {
+		// samples may span several blocks when observed between bid cycles
+		perBlock := blockTime.Sub(e.lastTime).Seconds() / float64(height-e.lastHeight)
+		e.intervals = append(e.intervals, perBlock)
+		if len(e.intervals) > blockTimeSamples {
+			e.intervals = e.intervals[len(e.intervals)-blockTimeSamples:]
+		}
+	}
+
+	e.lastHeight = height
+	e.lastTime = blockTime
+}
+
+// UpperBound returns the mean block time plus several standard deviations,
+// or a conservative default until enough blocks have been observed
+func (e *BlockTimeEstimator) UpperBound() time.Duration {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.intervals) < 2 {
+		return 2 * approxBlockSeconds * time.Second
+	}
+
+	var mean, max float64
+	for _, interval := range e.intervals {
+		mean += interval
+		max = math.Max(max, interval)
+	}
+	mean /= float64(len(e.intervals))
+
+	var variance float64
+	for _, interval := range e.intervals {
+		variance += (interval - mean) * (interval - mean)
+	}
+	variance /= float64(len(e.intervals) - 1)
+
+	upper := math.Max(mean+blockTimeDeviations*math.Sqrt(variance), max)
+
+	return time.Duration(upper * float64(time.Second))
+}
+
+// BidScheduler holds proposed bids until a window before each auction ends,
+// so competitors have as little time as possible to respond
+//
+// Bids are released early enough to be included before the end time even if
+// blocks are slower than average.
+type BidScheduler struct {
+	window time.Duration
+	blocks *BlockTimeEstimator
+}
+
+func NewBidScheduler(window time.Duration) *BidScheduler {
+	return &BidScheduler{
+		window: window,
+		blocks: NewBlockTimeEstimator(),
+	}
+}
+
+// ObserveBlock records a block for estimating block time variance
+func (s *BidScheduler) ObserveBlock(height int64, blockTime time.Time) {
+	s.blocks.Observe(height, blockTime)
+}
+
+// SendAt returns the chain time a bid on an auction ending at endTime should
+// be sent
+func (s *BidScheduler) SendAt(endTime time.Time) time.Time {
+	inclusion := bidInclusionBlocks * s.blocks.UpperBound()
+	return endTime.Add(-s.window - inclusion)
+}
+
+// Schedule holds proposed bids that are not yet due at the provided chain
+// time and returns the time until the earliest held bid is due, or zero if
+// no bids are held
+//
+// Auctions without any bids have no end time until the first bid is placed,
+// so bids on them are never held.
+func (s *BidScheduler) Schedule(decisions BidDecisions, blockTime time.Time) (BidDecisions, time.Duration) {
+	var wake time.Duration
+
+	scheduled := make(BidDecisions, 0, len(decisions))
+	for _, decision := range decisions {
+		if decision.Proposed == nil || !decision.EndTime.Before(auctiontypes.DistantFuture) {
+			scheduled = append(scheduled, decision)
+			continue
+		}
+
+		sendAt := s.SendAt(decision.EndTime)
+		if !blockTime.Before(sendAt) {
+			scheduled = append(scheduled, decision)
+			continue
+		}
+
+		scheduled = append(scheduled, decision.Hold(sendAt))
+
+		if until := sendAt.Sub(blockTime); wake == 0 || until < wake {
+			wake = until
+		}
+	}
+
+	return scheduled, wake
+}
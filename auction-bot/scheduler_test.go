@@ -0,0 +1,66 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	auctiontypes "github.com/kava-labs/kava/x/auction/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockTimeEstimator(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	estimator := NewBlockTimeEstimator()
+	require.Equal(t, 12*time.Second, estimator.UpperBound(), "default before enough blocks")
+
+	for i := int64(1); i <= 10; i++ {
+		estimator.Observe(i, start.Add(time.Duration(i)*6*time.Second))
+	}
+	require.Equal(t, 6*time.Second, estimator.UpperBound())
+
+	// a slow block raises the bound above the slowest observed block
+	estimator.Observe(11, start.Add(80*time.Second))
+	require.Greater(t, estimator.UpperBound(), 20*time.Second)
+
+	// out of order blocks are ignored
+	estimator.Observe(5, start)
+	require.Greater(t, estimator.UpperBound(), 20*time.Second)
+}
+
+func TestBidSchedulerSchedule(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	bid := c("usdx", 100e6)
+
+	scheduler := NewBidScheduler(time.Minute)
+	// no blocks observed, so inclusion takes two 12 second blocks
+	require.Equal(t, now.Add(-84*time.Second), scheduler.SendAt(now))
+
+	decisions := BidDecisions{
+		// due, within the window and inclusion time
+		{AuctionID: 1, EndTime: now.Add(80 * time.Second), Proposed: &bid, bidInfo: &AuctionInfo{ID: 1, Amount: bid}},
+		// not due for another 10 minutes
+		{AuctionID: 2, EndTime: now.Add(684 * time.Second), Proposed: &bid, bidInfo: &AuctionInfo{ID: 2, Amount: bid}},
+		// not due for another 20 minutes
+		{AuctionID: 3, EndTime: now.Add(1284 * time.Second), Proposed: &bid, bidInfo: &AuctionInfo{ID: 3, Amount: bid}},
+		// no bids yet, so no end time
+		{AuctionID: 4, EndTime: auctiontypes.DistantFuture, Proposed: &bid, bidInfo: &AuctionInfo{ID: 4, Amount: bid}},
+		// skipped
+		{AuctionID: 5, EndTime: now.Add(time.Hour), SkipReason: errNoProfitableBid.Error()},
+	}
+
+	scheduled, wake := scheduler.Schedule(decisions, now)
+	require.Len(t, scheduled, len(decisions))
+	require.Equal(t, 10*time.Minute, wake)
+
+	require.Nil(t, scheduled[0].HeldUntil)
+	require.Equal(t, now.Add(10*time.Minute), *scheduled[1].HeldUntil)
+	require.Equal(t, now.Add(20*time.Minute), *scheduled[2].HeldUntil)
+	require.Nil(t, scheduled[3].HeldUntil)
+	require.Nil(t, scheduled[4].HeldUntil)
+
+	require.Equal(t, AuctionInfos{{ID: 1, Amount: bid}, {ID: 4, Amount: bid}}, scheduled.Bids())
+
+	_, wake = scheduler.Schedule(BidDecisions{decisions[0]}, now)
+	require.Zero(t, wake, "nothing held")
+}
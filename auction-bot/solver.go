@@ -0,0 +1,133 @@
+package main
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+)
+
+// solveProposedBid finds the largest bid for a forward auction that still
+// makes the margin if the auction is won with it.
+//
+// Only bids the auction module accepts are considered, from the min new bid
+// (capped at the max bid) up to the max bid. Profit is checked with the same
+// usd valuation used for the lot, so rounding in the conversion can not push
+// the proposal below the margin.
+func solveProposedBid(
+	currentBid, lot, maxbid sdk.Coin,
+	assetInfoLot, assetInfoBid AssetInfo,
+	margin, increment sdk.Dec,
+	id uint64,
+) (sdk.Coin, bool) {
+	lotUSDValue := calculateUSDValue(lot, assetInfoLot)
+	if lotUSDValue.IsZero() {
+		return sdk.Coin{}, false
+	}
+
+	minBid := sdk.MinInt(minNewBid(currentBid.Amount, increment), maxbid.Amount)
+
+	// profit falls as the bid rises, so the largest profitable bid can be
+	// found by bisection
+	profitable := func(amount sdk.Int) bool {
+		bidUSDValue := calculateUSDValue(sdk.NewCoin(maxbid.Denom, amount), assetInfoBid)
+		return sdk.OneDec().Sub(bidUSDValue.Quo(lotUSDValue)).GTE(margin)
+	}
+
+	amount, ok := searchLargest(minBid, maxbid.Amount, profitable)
+	if !ok {
+		return sdk.Coin{}, false
+	}
+
+	return sdk.NewCoin(maxbid.Denom, amount), true
+}
+
+// solveProposedLot finds the smallest lot for a reverse auction that still
+// makes the margin when the max bid is paid for it.
+//
+// Only lots the auction module accepts are considered, from one up to the
+// current lot less the min decrement.
+func solveProposedLot(
+	logger zerolog.Logger,
+	lot, maxbid sdk.Coin,
+	assetInfoLot, assetInfoBid AssetInfo,
+	margin, increment sdk.Dec,
+	id uint64,
+) (sdk.Coin, bool) {
+	bidUSDValue := calculateUSDValue(maxbid, assetInfoBid)
+	if bidUSDValue.IsZero() {
+		logger.Info().
+			Uint64("auction id", id).
+			Msg("Exiting auction because of zero bid USD value")
+
+		return sdk.Coin{}, false
+	}
+
+	maxLot := maxNewLot(lot.Amount, increment)
+
+	// profit rises with the lot, so the smallest profitable lot can be found
+	// by bisection
+	profitable := func(amount sdk.Int) bool {
+		lotUSDValue := calculateUSDValue(sdk.NewCoin(lot.Denom, amount), assetInfoLot)
+		if lotUSDValue.IsZero() {
+			return false
+		}
+		return sdk.OneDec().Sub(bidUSDValue.Quo(lotUSDValue)).GTE(margin)
+	}
+
+	amount, ok := searchSmallest(sdk.OneInt(), maxLot, profitable)
+	if !ok {
+		return sdk.Coin{}, false
+	}
+
+	return sdk.NewCoin(lot.Denom, amount), true
+}
+
+// maxNewLot calculates the largest lot that can be placed in a reverse auction.
+// It returns the current lot - 1%. Unless the increment is <1 in which case its the current lot - 1.
+func maxNewLot(currentLot sdk.Int, increment sdk.Dec) sdk.Int {
+	return currentLot.Sub(
+		sdk.MaxInt(
+			sdk.NewInt(1),
+			sdk.NewDecFromInt(currentLot).Mul(increment).RoundInt(),
+		),
+	)
+}
+
+// searchLargest returns the largest value in [low, high] that satisfies ok,
+// where ok holds for every value up to some point and none after it
+func searchLargest(low, high sdk.Int, ok func(sdk.Int) bool) (sdk.Int, bool) {
+	if low.GT(high) || !ok(low) {
+		return sdk.Int{}, false
+	}
+
+	// ok(low) always holds
+	for low.LT(high) {
+		mid := low.Add(high.Sub(low).AddRaw(1).QuoRaw(2))
+		if ok(mid) {
+			low = mid
+		} else {
+			high = mid.SubRaw(1)
+		}
+	}
+
+	return low, true
+}
+
+// searchSmallest returns the smallest value in [low, high] that satisfies ok,
+// where ok holds for no value up to some point and every value after it
+func searchSmallest(low, high sdk.Int, ok func(sdk.Int) bool) (sdk.Int, bool) {
+	if low.GT(high) || !ok(high) {
+		return sdk.Int{}, false
+	}
+
+	// ok(high) always holds
+	for low.LT(high) {
+		mid := low.Add(high.Sub(low).QuoRaw(2))
+		if ok(mid) {
+			high = mid
+		} else {
+			low = mid.AddRaw(1)
+		}
+	}
+
+	return high, true
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSolveProposedBid(t *testing.T) {
+	usdx := AssetInfo{Price: d("1.00"), ConversionFactor: sdk.NewInt(1e6)}
+	bnb := AssetInfo{Price: d("200.00"), ConversionFactor: sdk.NewInt(1e8)}
+
+	// the ladder stops at 80% of the max bid
+	bid, ok := solveProposedBid(c("usdx", 0), c("bnb", 1000e8), c("usdx", 220_000e6), bnb, usdx, d("0.05"), d("0.01"), 0)
+	require.True(t, ok)
+	require.Equal(t, c("usdx", 190_000e6), bid)
+
+	// the max bid is proposed when profitable
+	bid, ok = solveProposedBid(c("usdx", 0), c("bnb", 1000e8), c("usdx", 150_000e6), bnb, usdx, d("0.05"), d("0.01"), 0)
+	require.True(t, ok)
+	require.Equal(t, c("usdx", 150_000e6), bid)
+
+	bid, ok = solveProposedBid(c("usdx", 0), c("bnb", 1), c("usdx", 2), bnb, usdx, d("0.05"), d("0.01"), 0)
+	require.True(t, ok)
+	require.Equal(t, c("usdx", 1), bid)
+
+	// the min new bid is not profitable
+	_, ok = solveProposedBid(c("usdx", 1), c("bnb", 1), c("usdx", 2), bnb, usdx, d("0.05"), d("0.01"), 0)
+	require.False(t, ok)
+}
+
+func TestSolveProposedLot(t *testing.T) {
+	usdx := AssetInfo{Price: d("1.00"), ConversionFactor: sdk.NewInt(1e6)}
+	bnb := AssetInfo{Price: d("200.00"), ConversionFactor: sdk.NewInt(1e8)}
+
+	// the ladder stops at 60% of the lot, the exact lot is 100000 / 0.95 usd
+	lot, ok := solveProposedLot(zerolog.Nop(), c("bnb", 1000e8), c("usdx", 100_000e6), bnb, usdx, d("0.05"), d("0.01"), 0)
+	require.True(t, ok)
+	require.Equal(t, c("bnb", 52_631_578_948), lot)
+
+	// the lot can not be reduced by the min decrement and stay profitable
+	_, ok = solveProposedLot(zerolog.Nop(), c("bnb", 1000e8), c("usdx", 190_000e6), bnb, usdx, d("0.05"), d("0.01"), 0)
+	require.False(t, ok)
+}
+
+// solverCase is a random auction, covering conversion factors, prices and
+// margins seen on chain as well as amounts small enough to hit truncation
+type solverCase struct {
+	Lot, CurrentBid, MaxBid sdk.Coin
+	LotInfo, BidInfo        AssetInfo
+	Margin, Increment       sdk.Dec
+	// Competing sets the final competing bid or lot of the auction
+	Competing int64
+}
+
+func (solverCase) Generate(r *rand.Rand, size int) reflect.Value {
+	amount := func() sdk.Int {
+		// spread amounts over orders of magnitude
+		return sdk.NewInt(r.Int63n(10) + 1).Mul(sdk.NewInt(10).ToLegacyDec().Power(uint64(r.Intn(16))).TruncateInt())
+	}
+	assetInfo := func() AssetInfo {
+		factors := []int64{1, 1e6, 1e8, 1e18}
+		return AssetInfo{
+			Price:            sdk.NewDecWithPrec(r.Int63n(100_000_000)+1, int64(r.Intn(9))),
+			ConversionFactor: sdk.NewInt(factors[r.Intn(len(factors))]),
+		}
+	}
+	increments := []sdk.Dec{d("0"), d("0.001"), d("0.01"), d("0.05")}
+
+	maxBid := amount()
+	return reflect.ValueOf(solverCase{
+		Lot:        sdk.NewCoin("lot", amount()),
+		CurrentBid: sdk.NewCoin("bid", sdk.NewInt(r.Int63()).Mod(maxBid)),
+		MaxBid:     sdk.NewCoin("bid", maxBid),
+		LotInfo:    assetInfo(),
+		BidInfo:    assetInfo(),
+		Margin:     sdk.NewDecWithPrec(r.Int63n(500), 3),
+		Increment:  increments[r.Intn(len(increments))],
+		Competing:  r.Int63(),
+	})
+}
+
+func (tc solverCase) forwardProfitable(amount sdk.Int) bool {
+	lotUSDValue := calculateUSDValue(tc.Lot, tc.LotInfo)
+	bidUSDValue := calculateUSDValue(sdk.NewCoin(tc.MaxBid.Denom, amount), tc.BidInfo)
+	return sdk.OneDec().Sub(bidUSDValue.Quo(lotUSDValue)).GTE(tc.Margin)
+}
+
+func (tc solverCase) reverseProfitable(amount sdk.Int) bool {
+	lotUSDValue := calculateUSDValue(sdk.NewCoin(tc.Lot.Denom, amount), tc.LotInfo)
+	if lotUSDValue.IsZero() {
+		return false
+	}
+	bidUSDValue := calculateUSDValue(tc.MaxBid, tc.BidInfo)
+	return sdk.OneDec().Sub(bidUSDValue.Quo(lotUSDValue)).GTE(tc.Margin)
+}
+
+// forwardCompeting returns the final competing forward bid, at least the
+// current bid and below the max bid
+func (tc solverCase) forwardCompeting() bidPosition {
+	extra := sdk.NewInt(tc.Competing).Mod(tc.MaxBid.Amount.Sub(tc.CurrentBid.Amount))
+	return bidPosition{Amount: tc.CurrentBid.Amount.Add(extra)}
+}
+
+// reverseCompeting returns the final competing lot, at most the current lot
+func (tc solverCase) reverseCompeting() bidPosition {
+	return bidPosition{Reverse: true, Amount: sdk.NewInt(tc.Competing).Mod(tc.Lot.Amount).AddRaw(1)}
+}
+
+// profit returns the profit of a proposal against the final competing bid,
+// placed as the most the bot is willing to bid and outbidding the competing
+// bid by the min increment, valued at the prices the proposal was made at
+func (tc solverCase) profit(proposal sdk.Coin, final bidPosition) sdk.Dec {
+	auction := &backtestAuction{Lot: tc.Lot, MaxBid: tc.MaxBid, Final: final}
+	auction.propose(proposal)
+
+	valuation := &AuctionData{
+		Assets:       map[string]AssetInfo{tc.Lot.Denom: tc.LotInfo, tc.MaxBid.Denom: tc.BidInfo},
+		BidIncrement: tc.Increment,
+	}
+	return auction.simulate(MinIncrementStrategy, valuation).Profit
+}
+
+var solverQuickConfig = &quick.Config{
+	MaxCount: 5000,
+	Rand:     rand.New(rand.NewSource(1)),
+}
+
+// TestSolveProposedBidProperties checks forward proposals are accepted by the
+// auction module, meet the margin and can not be raised and still meet it.
+// Against any competing bid, outbidding it up to the proposal never makes
+// less profit than doing the same up to the ladder's proposal.
+func TestSolveProposedBidProperties(t *testing.T) {
+	property := func(tc solverCase) bool {
+		bid, ok := solveProposedBid(tc.CurrentBid, tc.Lot, tc.MaxBid, tc.LotInfo, tc.BidInfo, tc.Margin, tc.Increment, 0)
+		ladderBid, ladderOk := calculateProposedBid(tc.CurrentBid, tc.Lot, tc.MaxBid, tc.LotInfo, tc.BidInfo, tc.Margin, tc.Increment, 0)
+
+		if ladderOk && !ok {
+			t.Logf("ladder bids %s on an auction the solver skips", ladderBid)
+			return false
+		}
+		if !ok {
+			return true
+		}
+
+		minBid := sdk.MinInt(minNewBid(tc.CurrentBid.Amount, tc.Increment), tc.MaxBid.Amount)
+		valid := bid.Denom == tc.MaxBid.Denom && bid.Amount.GTE(minBid) && bid.Amount.LTE(tc.MaxBid.Amount)
+		optimal := bid.Amount.Equal(tc.MaxBid.Amount) || !tc.forwardProfitable(bid.Amount.AddRaw(1))
+		if !valid || !tc.forwardProfitable(bid.Amount) || !optimal {
+			return false
+		}
+
+		final := tc.forwardCompeting()
+		profit := tc.profit(bid, final)
+		if profit.IsNegative() {
+			t.Logf("bid %s loses %s against %s", bid, profit, final.Amount)
+			return false
+		}
+		if ladderOk {
+			if ladderProfit := tc.profit(ladderBid, final); profit.LT(ladderProfit) {
+				t.Logf("ladder bid %s profits %s over %s from bid %s against %s", ladderBid, ladderProfit, profit, bid, final.Amount)
+				return false
+			}
+		}
+
+		return true
+	}
+
+	require.NoError(t, quick.Check(property, solverQuickConfig))
+}
+
+// TestSolveProposedLotProperties checks reverse proposals are accepted by the
+// auction module, meet the margin and can not be lowered and still meet it.
+// Against any competing lot, outbidding it down to the proposal never makes
+// less profit than doing the same down to the ladder's valid proposals.
+func TestSolveProposedLotProperties(t *testing.T) {
+	property := func(tc solverCase) bool {
+		lot, ok := solveProposedLot(zerolog.Nop(), tc.Lot, tc.MaxBid, tc.LotInfo, tc.BidInfo, tc.Margin, tc.Increment, 0)
+		ladderLot, ladderOk := calculateProposedLot(zerolog.Nop(), tc.Lot, tc.MaxBid, tc.LotInfo, tc.BidInfo, tc.Margin, tc.Increment, 0)
+
+		maxLot := maxNewLot(tc.Lot.Amount, tc.Increment)
+
+		// the ladder does not check its lot against the min decrement
+		ladderValid := ladderOk && ladderLot.Amount.IsPositive() && ladderLot.Amount.LTE(maxLot)
+		if ladderValid && !ok {
+			t.Logf("ladder bids %s on an auction the solver skips", ladderLot)
+			return false
+		}
+		if !ok {
+			return true
+		}
+
+		valid := lot.Denom == tc.Lot.Denom && lot.Amount.IsPositive() && lot.Amount.LTE(maxLot)
+		optimal := lot.Amount.Equal(sdk.OneInt()) || !tc.reverseProfitable(lot.Amount.SubRaw(1))
+		if !valid || !tc.reverseProfitable(lot.Amount) || !optimal {
+			return false
+		}
+
+		final := tc.reverseCompeting()
+		profit := tc.profit(lot, final)
+		if profit.IsNegative() {
+			t.Logf("lot %s loses %s against %s", lot, profit, final.Amount)
+			return false
+		}
+		if ladderValid {
+			if ladderProfit := tc.profit(ladderLot, final); profit.LT(ladderProfit) {
+				t.Logf("ladder lot %s profits %s over %s from lot %s against %s", ladderLot, ladderProfit, profit, lot, final.Amount)
+				return false
+			}
+		}
+
+		return true
+	}
+
+	require.NoError(t, quick.Check(property, solverQuickConfig))
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/kava-labs/go-tools/signing"
+)
+
+// bidRequest is attached to bid tx requests to match responses with the
+// auctions that were bid on
+type bidRequest struct {
+	ID         uint64
+	AuctionIDs []uint64
+}
+
+// swapRequest is attached to swap tx requests to match responses with the
+// disposal that was sold
+type swapRequest struct {
+	ID       uint64
+	Disposal Disposal
+}
+
+// requestID returns the in-flight id of a request sent by the bot, or false if
+// the request has none
+func requestID(request signing.MsgRequest) (uint64, bool) {
+	switch data := request.Data.(type) {
+	case bidRequest:
+		return data.ID, true
+	case swapRequest:
+		return data.ID, true
+	default:
+		return 0, false
+	}
+}
+
+// InflightRequest is a tx request sent to the signer that has not yet been
+// delivered to a block or failed
+type InflightRequest struct {
+	ID         uint64    `json:"id"`
+	AuctionIDs []uint64  `json:"auction_ids,omitempty"`
+	Swap       *Disposal `json:"swap,omitempty"`
+	Fee        sdk.Coins `json:"fee"`
+	SentAt     time.Time `json:"sent_at"`
+}
+
+// BotState holds the settings the bid loop reads each cycle and the state it
+// leaves behind, so both can be inspected and changed at runtime
+//
+// BotState is safe for concurrent use.
+type BotState struct {
+	mu             sync.RWMutex
+	paused         bool
+	policy         BidPolicy
+	priceOverrides map[string]sdk.Dec
+	decisions      BidDecisions
+	inflight       map[uint64]InflightRequest
+	nextRequestID  uint64
+	lastCycle      time.Time
+
+	forced chan struct{}
+}
+
+// NewBotState creates state starting from the configured policy and price
+// overrides
+func NewBotState(policy BidPolicy, priceOverrides map[string]sdk.Dec) *BotState {
+	overrides := make(map[string]sdk.Dec, len(priceOverrides))
+	for denom, price := range priceOverrides {
+		overrides[denom] = price
+	}
+
+	return &BotState{
+		policy:         policy,
+		priceOverrides: overrides,
+		inflight:       make(map[uint64]InflightRequest),
+		nextRequestID:  1,
+		// the bot is given until the first cycle is due before it is unhealthy
+		lastCycle: time.Now(),
+		forced:    make(chan struct{}, 1),
+	}
+}
+
+// Paused returns true if bids and swaps should not be sent
+func (s *BotState) Paused() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.paused
+}
+
+// SetPaused pauses or resumes sending bids and swaps
+func (s *BotState) SetPaused(paused bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.paused = paused
+}
+
+// Policy returns the bid policy used for the next cycle
+func (s *BotState) Policy() BidPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.policy
+}
+
+// SetPolicy replaces the bid policy if it is valid
+func (s *BotState) SetPolicy(policy BidPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("invalid bid policy: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.policy = policy
+	return nil
+}
+
+// PriceOverrides returns a copy of the prices that replace pricefeed prices
+func (s *BotState) PriceOverrides() map[string]sdk.Dec {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	overrides := make(map[string]sdk.Dec, len(s.priceOverrides))
+	for denom, price := range s.priceOverrides {
+		overrides[denom] = price
+	}
+
+	return overrides
+}
+
+// SetPriceOverride sets a price that replaces the pricefeed price of a denom
+func (s *BotState) SetPriceOverride(denom string, price sdk.Dec) error {
+	if price.IsNil() || !price.IsPositive() {
+		return fmt.Errorf("price must be positive, got %s", price)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.priceOverrides[denom] = price
+	return nil
+}
+
+// ClearPriceOverride returns a denom to its pricefeed price
+func (s *BotState) ClearPriceOverride(denom string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.priceOverrides, denom)
+}
+
+// Decisions returns the decisions of the last cycle
+func (s *BotState) Decisions() BidDecisions {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.decisions
+}
+
+// SetDecisions stores the decisions of the latest cycle
+func (s *BotState) SetDecisions(decisions BidDecisions) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.decisions = decisions
+}
+
+// AddInflight stores a request about to be sent to the signer and returns the
+// id to attach to it
+func (s *BotState) AddInflight(request InflightRequest) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	request.ID = s.nextRequestID
+	s.nextRequestID++
+	s.inflight[request.ID] = request
+
+	return request.ID
+}
+
+// RemoveInflight removes a request once the signer has responded to it
+func (s *BotState) RemoveInflight(id uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.inflight, id)
+}
+
+// Inflight returns the requests awaiting a response, oldest first
+func (s *BotState) Inflight() []InflightRequest {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	requests := make([]InflightRequest, 0, len(s.inflight))
+	for _, request := range s.inflight {
+		requests = append(requests, request)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].ID < requests[j].ID
+	})
+
+	return requests
+}
+
+// CycleCompleted records the time a bid cycle completed, including cycles
+// that ended on a node or price error, so a stale cycle only means the loop
+// itself is stuck
+func (s *BotState) CycleCompleted(at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastCycle = at
+}
+
+// LastCycle returns the time the last bid cycle completed, or the time the
+// state was created if no cycle has completed
+func (s *BotState) LastCycle() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.lastCycle
+}
+
+// ForceCycle starts a bid cycle without waiting for the interval, repeated
+// calls before the cycle starts run a single cycle
+func (s *BotState) ForceCycle() {
+	select {
+	case s.forced <- struct{}{}:
+	default:
+	}
+}
+
+// Forced receives when a cycle has been forced
+func (s *BotState) Forced() <-chan struct{} {
+	return s.forced
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	auctiontypes "github.com/kava-labs/kava/x/auction/types"
+	"github.com/rs/zerolog"
+)
+
+// VolatilityConfig sets how price history is sampled and how much lot values
+// are discounted for the time until an auction closes
+type VolatilityConfig struct {
+	// Samples is the number of price changes used to estimate volatility
+	Samples int
+	// SampleInterval is the number of blocks between price samples
+	SampleInterval int64
+	// Confidence is the number of standard deviations of price movement the
+	// lot value is discounted by
+	Confidence sdk.Dec
+	// MaxHaircut is the largest fraction of the lot value discounted
+	MaxHaircut sdk.Dec
+	// MaxHoldingPeriod caps the time until close, auctions without bids have
+	// no max end time and are held this long
+	MaxHoldingPeriod time.Duration
+}
+
+// Validate returns an error if the config cannot estimate volatility or
+// could discount a lot to nothing
+func (c VolatilityConfig) Validate() error {
+	if c.Samples < 2 {
+		return fmt.Errorf("samples must be at least 2, got %d", c.Samples)
+	}
+	if c.SampleInterval <= 0 {
+		return fmt.Errorf("sample interval must be positive, got %d", c.SampleInterval)
+	}
+	if c.Confidence.IsNil() || c.Confidence.IsNegative() {
+		return fmt.Errorf("confidence must not be negative, got %s", c.Confidence)
+	}
+	if c.MaxHaircut.IsNil() || c.MaxHaircut.IsNegative() || c.MaxHaircut.GTE(sdk.OneDec()) {
+		return fmt.Errorf("max haircut must be in [0, 1), got %s", c.MaxHaircut)
+	}
+	if c.MaxHoldingPeriod <= 0 {
+		return fmt.Errorf("max holding period must be positive, got %s", c.MaxHoldingPeriod)
+	}
+
+	return nil
+}
+
+// VolatilitySource provides the pricefeed history volatility is estimated from
+type VolatilitySource interface {
+	CurrentPrices(height int64) (map[string]sdk.Dec, error)
+	BlockTime(height int64) (time.Time, error)
+}
+
+// priceSample is the pricefeed state at a past height
+type priceSample struct {
+	Time   time.Time
+	Prices map[string]sdk.Dec
+}
+
+// VolatilityModel estimates the volatility of lot assets from recent prices
+// and discounts lot values over the time until each auction closes, so the
+// effective margin grows with volatility and time to close
+type VolatilityModel struct {
+	config VolatilityConfig
+	source VolatilitySource
+	// past prices do not change, so samples are kept until they leave the
+	// sampled range
+	samples map[int64]priceSample
+}
+
+func NewVolatilityModel(config VolatilityConfig, source VolatilitySource) *VolatilityModel {
+	return &VolatilityModel{
+		config:  config,
+		source:  source,
+		samples: make(map[int64]priceSample),
+	}
+}
+
+// Haircuts returns the fraction of the lot value to discount for every auction
+// with a lot asset that has a volatility estimate, by auction id
+func (m *VolatilityModel) Haircuts(logger zerolog.Logger, data *AuctionData) (map[uint64]sdk.Dec, error) {
+	volatilities, err := m.Estimate(data)
+	if err != nil {
+		return nil, err
+	}
+
+	haircuts := make(map[uint64]sdk.Dec)
+	for _, auction := range data.Auctions {
+		volatility, ok := volatilities[auction.GetLot().Denom]
+		if !ok {
+			continue
+		}
+
+		holding := holdingPeriod(auction, data.BlockTime, m.config.MaxHoldingPeriod)
+		haircut := lotHaircut(volatility, holding, m.config.Confidence, m.config.MaxHaircut)
+		haircuts[auction.GetID()] = haircut
+
+		logger.Debug().
+			Uint64("auction id", auction.GetID()).
+			Str("volatility", volatility.String()).
+			Dur("holding period", holding).
+			Str("haircut", haircut.String()).
+			Msg("discounting lot value")
+	}
+
+	return haircuts, nil
+}
+
+// Estimate returns the hourly volatility of each asset in data, as the
+// standard deviation of its price change over an hour
+//
+// Prices are sampled at heights aligned to the sample interval so samples can
+// be reused between cycles.
+func (m *VolatilityModel) Estimate(data *AuctionData) (map[string]sdk.Dec, error) {
+	latest := data.Height - data.Height%m.config.SampleInterval
+
+	var samples []priceSample
+	sampled := make(map[int64]bool)
+	for i := 0; i <= m.config.Samples; i++ {
+		height := latest - int64(i)*m.config.SampleInterval
+		if height < 1 {
+			break
+		}
+
+		sample, err := m.sample(height)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, sample)
+		sampled[height] = true
+	}
+
+	for height := range m.samples {
+		if !sampled[height] {
+			delete(m.samples, height)
+		}
+	}
+
+	volatilities := make(map[string]sdk.Dec)
+	for denom, info := range data.Assets {
+		if volatility, ok := hourlyVolatility(samples, info.SpotMarketID); ok {
+			volatilities[denom] = volatility
+		}
+	}
+
+	return volatilities, nil
+}
+
+func (m *VolatilityModel) sample(height int64) (priceSample, error) {
+	if sample, ok := m.samples[height]; ok {
+		return sample, nil
+	}
+
+	prices, err := m.source.CurrentPrices(height)
+	if err != nil {
+		return priceSample{}, err
+	}
+	blockTime, err := m.source.BlockTime(height)
+	if err != nil {
+		return priceSample{}, err
+	}
+
+	sample := priceSample{Time: blockTime, Prices: prices}
+	m.samples[height] = sample
+
+	return sample, nil
+}
+
+// hourlyVolatility estimates the standard deviation of a market's price
+// change over an hour from consecutive samples, newest first
+//
+// Price changes are assumed to have zero mean, so a steady trend is counted
+// as volatility rather than ignored.
+func hourlyVolatility(samples []priceSample, marketID string) (sdk.Dec, bool) {
+	sumSquares := sdk.ZeroDec()
+	var seconds int64
+	for i := 0; i+1 < len(samples); i++ {
+		newer, older := samples[i], samples[i+1]
+
+		price, ok := newer.Prices[marketID]
+		if !ok || !price.IsPositive() {
+			continue
+		}
+		previous, ok := older.Prices[marketID]
+		if !ok || !previous.IsPositive() {
+			continue
+		}
+		elapsed := int64(newer.Time.Sub(older.Time) / time.Second)
+		if elapsed <= 0 {
+			continue
+		}
+
+		change := price.Quo(previous).Sub(sdk.OneDec())
+		sumSquares = sumSquares.Add(change.Mul(change))
+		seconds += elapsed
+	}
+
+	if seconds == 0 {
+		return sdk.Dec{}, false
+	}
+
+	variance := sumSquares.MulInt64(hourSeconds).QuoInt64(seconds)
+	volatility, err := variance.ApproxSqrt()
+	if err != nil {
+		return sdk.Dec{}, false
+	}
+
+	return volatility, true
+}
+
+// holdingPeriod returns the longest an auction can run from the block time,
+// auctions without bids have no max end time and are capped at maxHolding
+func holdingPeriod(auction auctiontypes.Auction, blockTime time.Time, maxHolding time.Duration) time.Duration {
+	holding := auction.GetMaxEndTime().Sub(blockTime)
+	if holding < 0 {
+		return 0
+	}
+	if holding > maxHolding {
+		return maxHolding
+	}
+
+	return holding
+}
+
+// lotHaircut returns the fraction of the lot value at risk of being lost over
+// the holding period, at the given number of standard deviations
+func lotHaircut(hourlyVolatility sdk.Dec, holding time.Duration, confidence, maxHaircut sdk.Dec) sdk.Dec {
+	hours := sdk.NewDec(int64(holding / time.Second)).QuoInt64(hourSeconds)
+	scale, err := hours.ApproxSqrt()
+	if err != nil {
+		return maxHaircut
+	}
+
+	return sdk.MinDec(hourlyVolatility.Mul(scale).Mul(confidence), maxHaircut)
+}
+
+// discountLot returns asset info with the lot asset's price reduced by the
+// haircut, leaving data unchanged
+func discountLot(assets map[string]AssetInfo, lotDenom string, haircut sdk.Dec) map[string]AssetInfo {
+	info, ok := assets[lotDenom]
+	if !ok || !haircut.IsPositive() {
+		return assets
+	}
+
+	discounted := make(map[string]AssetInfo, len(assets))
+	for denom, asset := range assets {
+		discounted[denom] = asset
+	}
+	info.Price = info.Price.Mul(sdk.OneDec().Sub(haircut))
+	discounted[lotDenom] = info
+
+	return discounted
+}
+
+// GrpcVolatilitySource reads pricefeed history from a node, which requires an
+// archive node if samples reach past pruned heights
+type GrpcVolatilitySource struct {
+	*GrpcPriceGuardSource
+}
+
+var _ VolatilitySource = (*GrpcVolatilitySource)(nil)
+
+func NewGrpcVolatilitySource(client GrpcClient) *GrpcVolatilitySource {
+	return &GrpcVolatilitySource{NewGrpcPriceGuardSource(client)}
+}
+
+func (s *GrpcVolatilitySource) BlockTime(height int64) (time.Time, error) {
+	return GetBlockTimeAtHeight(s.client, height)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	auctiontypes "github.com/kava-labs/kava/x/auction/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+type mockVolatilitySource struct {
+	start   time.Time
+	prices  map[int64]map[string]sdk.Dec
+	fetched []int64
+}
+
+func (s *mockVolatilitySource) CurrentPrices(height int64) (map[string]sdk.Dec, error) {
+	s.fetched = append(s.fetched, height)
+	return s.prices[height], nil
+}
+
+func (s *mockVolatilitySource) BlockTime(height int64) (time.Time, error) {
+	return s.start.Add(time.Duration(height) * approxBlockSeconds * time.Second), nil
+}
+
+func TestVolatilityModel(t *testing.T) {
+	// an hour of blocks between samples
+	interval := int64(hourSeconds / approxBlockSeconds)
+	source := &mockVolatilitySource{
+		start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		prices: map[int64]map[string]sdk.Dec{
+			interval:     {"bnb:usd": d("100"), "usdx:usd": d("1")},
+			2 * interval: {"bnb:usd": d("110"), "usdx:usd": d("1")},
+			3 * interval: {"bnb:usd": d("99"), "usdx:usd": d("1")},
+		},
+	}
+	config := VolatilityConfig{
+		Samples:          2,
+		SampleInterval:   interval,
+		Confidence:       d("2"),
+		MaxHaircut:       d("0.5"),
+		MaxHoldingPeriod: 24 * time.Hour,
+	}
+	require.NoError(t, config.Validate())
+
+	blockTime := source.start.Add(time.Duration(3*interval+10) * approxBlockSeconds * time.Second)
+	auction := func(id uint64, maxEndTime time.Time) auctiontypes.Auction {
+		return &auctiontypes.CollateralAuction{
+			BaseAuction: auctiontypes.BaseAuction{
+				ID:         id,
+				Lot:        c("bnb", 1000e8),
+				Bid:        c("usdx", 0),
+				MaxEndTime: maxEndTime,
+			},
+			MaxBid: c("usdx", 220_000e6),
+		}
+	}
+	data := &AuctionData{
+		Height:    3*interval + 10,
+		BlockTime: blockTime,
+		Assets: map[string]AssetInfo{
+			"bnb":  {Price: d("99"), ConversionFactor: sdk.NewInt(1e8), SpotMarketID: "bnb:usd"},
+			"usdx": {Price: d("1"), ConversionFactor: sdk.NewInt(1e6), SpotMarketID: "usdx:usd"},
+		},
+		Auctions: []auctiontypes.Auction{
+			auction(1, blockTime.Add(4*time.Hour)),
+			auction(2, auctiontypes.DistantFuture),
+		},
+	}
+
+	model := NewVolatilityModel(config, source)
+
+	// price moved 10% each hour
+	volatilities, err := model.Estimate(data)
+	require.NoError(t, err)
+	require.Equal(t, d("0.1"), volatilities["bnb"])
+	require.True(t, volatilities["usdx"].IsZero())
+	require.Equal(t, []int64{3 * interval, 2 * interval, interval}, source.fetched)
+
+	haircuts, err := model.Haircuts(zerolog.Nop(), data)
+	require.NoError(t, err)
+	// two standard deviations over four hours
+	require.Equal(t, d("0.4"), haircuts[1])
+	// held for the max holding period, capped at the max haircut
+	require.Equal(t, d("0.5"), haircuts[2])
+
+	// past samples are reused
+	require.Len(t, source.fetched, 3)
+
+	t.Run("invalid config", func(t *testing.T) {
+		invalid := config
+		invalid.MaxHaircut = d("1")
+		require.ErrorContains(t, invalid.Validate(), "max haircut must be in [0, 1)")
+
+		invalid = config
+		invalid.Samples = 1
+		require.ErrorContains(t, invalid.Validate(), "samples must be at least 2")
+	})
+}
+
+func TestGetBidDecisionsWithHaircut(t *testing.T) {
+	data := AuctionData{
+		Height: 100,
+		Auctions: []auctiontypes.Auction{
+			&auctiontypes.CollateralAuction{
+				BaseAuction: auctiontypes.BaseAuction{
+					ID:  1,
+					Lot: c("bnb", 1000e8),
+					Bid: c("usdx", 0),
+				},
+				MaxBid:            c("usdx", 220_000e6),
+				CorrespondingDebt: c("debt", 200_000e6),
+			},
+		},
+		Assets: map[string]AssetInfo{
+			"usdx": {Price: d("1.00"), ConversionFactor: sdk.NewInt(1e6)},
+			"bnb":  {Price: d("200"), ConversionFactor: sdk.NewInt(1e8)},
+		},
+		BidIncrement: d("0.01"),
+		LotHaircuts:  map[uint64]sdk.Dec{1: d("0.1")},
+	}
+
+	decisions := GetBidDecisions(zerolog.Nop(), &data, sdk.AccAddress{}, NewBidPolicy(d("0.05")))
+	require.Len(t, decisions, 1)
+
+	// the lot is valued at 180000 usd rather than 200000
+	decision := decisions[0]
+	require.Equal(t, c("usdx", 171_000e6), *decision.Proposed)
+	require.Equal(t, d("200000"), decision.LotUSDValue)
+	require.Equal(t, d("0.1"), *decision.LotHaircut)
+	require.Equal(t, d("0.05"), *decision.ExpectedMargin)
+
+	// the haircut does not change the asset info used by later auctions
+	require.Equal(t, d("200"), data.Assets["bnb"].Price)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func GetCdpsToLiquidate(data *PositionData) Positions {
+	cdpsToLiquidate := Positions{}
+
+	for _, pos := range data.Positions {
+		// cdps of a collateral type without a price can not be valued
+		if !data.Priced(pos) {
+			continue
+		}
+
+		ratio, ok := GetCollateralizationRatio(data, pos)
+		if !ok {
+			continue
+		}
+
+		// the cdp module liquidates cdps that are not over the liquidation ratio
+		if ratio.LTE(data.Collaterals[pos.CollateralType].LiquidationRatio) {
+			cdpsToLiquidate = append(cdpsToLiquidate, pos)
+		}
+	}
+
+	return cdpsToLiquidate
+}
+
+// GetCollateralizationRatio returns the value of the collateral of a position
+// over its debt, the principal and accumulated fees, as computed by the cdp
+// module. It returns false if the position has no price or no debt.
+func GetCollateralizationRatio(data *PositionData, pos Position) (sdk.Dec, bool) {
+	collateral, ok := data.Collaterals[pos.CollateralType]
+	if !ok {
+		return sdk.Dec{}, false
+	}
+
+	debt := pos.Principal.Amount.Add(pos.AccumulatedFees.Amount)
+	if !debt.IsPositive() {
+		return sdk.Dec{}, false
+	}
+
+	// usd value of collateral, in whole units of the collateral denom
+	collateralValue := toBaseUnits(pos.Collateral.Amount, collateral.ConversionFactor).Mul(collateral.Price)
+	// debt is valued at one usd, in whole units of the debt denom
+	debtValue := toBaseUnits(debt, data.DebtConversionFactor)
+
+	return collateralValue.Quo(debtValue), true
+}
+
+// toBaseUnits converts an amount to whole units, multiplying it by
+// 10^(-conversionFactor)
+func toBaseUnits(amount sdk.Int, conversionFactor sdk.Int) sdk.Dec {
+	return sdk.NewDecFromInt(amount).Mul(sdk.NewDecFromIntWithPrec(sdk.OneInt(), conversionFactor.Int64()))
+}
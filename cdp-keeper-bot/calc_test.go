@@ -0,0 +1,115 @@
+package main
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/tendermint/crypto"
+)
+
+func testCollaterals() map[string]CollateralInfo {
+	return map[string]CollateralInfo{
+		"bnb-a": {
+			Price:            sdk.MustNewDecFromStr("400.0"),
+			LiquidationRatio: sdk.MustNewDecFromStr("1.5"),
+			ConversionFactor: sdk.NewInt(8),
+		},
+		"busd-a": {
+			Price:            sdk.MustNewDecFromStr("1.0"),
+			LiquidationRatio: sdk.MustNewDecFromStr("1.01"),
+			ConversionFactor: sdk.NewInt(8),
+		},
+	}
+}
+
+func testPosition(name string, collateralType string, collateral sdk.Coin, principal int64, fees int64) Position {
+	return Position{
+		Owner:           sdk.AccAddress(crypto.AddressHash([]byte(name))),
+		CollateralType:  collateralType,
+		Collateral:      collateral,
+		Principal:       sdk.NewInt64Coin("usdx", principal),
+		AccumulatedFees: sdk.NewInt64Coin("usdx", fees),
+	}
+}
+
+func TestGetCdpsToLiquidateNoData(t *testing.T) {
+	data := &PositionData{}
+	cdps := GetCdpsToLiquidate(data)
+	assert.Equal(t, Positions{}, cdps)
+}
+
+func TestGetCdpsToLiquidateMissingCollateral(t *testing.T) {
+	data := &PositionData{
+		// no priced collateral types
+		Collaterals:          map[string]CollateralInfo{},
+		DebtConversionFactor: sdk.NewInt(6),
+		Positions: Positions{
+			testPosition("owner1", "bnb-a", sdk.NewInt64Coin("bnb", 100000000), 1000000000, 0),
+		},
+		Unpriced: []string{"bnb-a"},
+	}
+
+	cdps := GetCdpsToLiquidate(data)
+	assert.Equal(t, Positions{}, cdps)
+}
+
+func TestGetCdpsToLiquidate(t *testing.T) {
+	data := &PositionData{
+		Collaterals:          testCollaterals(),
+		DebtConversionFactor: sdk.NewInt(6),
+		Positions: Positions{
+			// OK: 10 bnb worth 4000 against 1000 usdx
+			testPosition("safe", "bnb-a", sdk.NewInt64Coin("bnb", 1000000000), 1000000000, 0),
+			// LIQUIDATE: 1 bnb worth 400 against 300 usdx
+			testPosition("unsafe", "bnb-a", sdk.NewInt64Coin("bnb", 100000000), 290000000, 10000000),
+			// LIQUIDATE: 1 bnb worth 400 against 260 usdx principal is safe,
+			// but not with 10 usdx of fees
+			testPosition("fees", "bnb-a", sdk.NewInt64Coin("bnb", 100000000), 260000000, 10000000),
+			// LIQUIDATE: 3 bnb worth 1200 against 800 usdx is at the liquidation ratio
+			testPosition("at-ratio", "bnb-a", sdk.NewInt64Coin("bnb", 300000000), 800000000, 0),
+			// OK: no debt
+			testPosition("no-debt", "bnb-a", sdk.NewInt64Coin("bnb", 100000000), 0, 0),
+			// LIQUIDATE: no collateral against 10 usdx
+			testPosition("no-collateral", "bnb-a", sdk.NewInt64Coin("bnb", 0), 10000000, 0),
+			// OK: 100 busd against 98 usdx is over the busd-a liquidation ratio
+			testPosition("stable", "busd-a", sdk.NewInt64Coin("busd", 10000000000), 98000000, 0),
+			// LIQUIDATE: 100 busd against 100 usdx
+			testPosition("stable-unsafe", "busd-a", sdk.NewInt64Coin("busd", 10000000000), 99500000, 500000),
+			// OK: btcb-a has no price
+			testPosition("unpriced", "btcb-a", sdk.NewInt64Coin("btcb", 100), 1000000000, 0),
+		},
+		Unpriced: []string{"btcb-a"},
+	}
+
+	cdps := GetCdpsToLiquidate(data)
+	assert.Equal(t, Positions{
+		data.Positions[1],
+		data.Positions[2],
+		data.Positions[3],
+		data.Positions[5],
+		data.Positions[7],
+	}, cdps)
+}
+
+func TestGetCollateralizationRatio(t *testing.T) {
+	data := &PositionData{
+		Collaterals:          testCollaterals(),
+		DebtConversionFactor: sdk.NewInt(6),
+	}
+
+	ratio, ok := GetCollateralizationRatio(data, testPosition("owner", "bnb-a", sdk.NewInt64Coin("bnb", 100000000), 190000000, 10000000))
+	assert.True(t, ok)
+	assert.Equal(t, sdk.MustNewDecFromStr("2"), ratio)
+
+	ratio, ok = GetCollateralizationRatio(data, testPosition("owner", "bnb-a", sdk.NewInt64Coin("bnb", 0), 10000000, 0))
+	assert.True(t, ok)
+	assert.Equal(t, sdk.ZeroDec(), ratio)
+
+	// positions without debt or price have no ratio
+	_, ok = GetCollateralizationRatio(data, testPosition("owner", "bnb-a", sdk.NewInt64Coin("bnb", 100000000), 0, 0))
+	assert.False(t, ok)
+
+	_, ok = GetCollateralizationRatio(data, testPosition("owner", "btcb-a", sdk.NewInt64Coin("btcb", 100000000), 10000000, 0))
+	assert.False(t, ok)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	cdptypes "github.com/kava-labs/kava/x/cdp/types"
+	pricefeedtypes "github.com/kava-labs/kava/x/pricefeed/types"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	DefaultPageLimit = 1000
+)
+
+type InfoResponse struct {
+	ChainId      string
+	LatestHeight int64
+}
+
+type LiquidationClient interface {
+	GetInfo() (*InfoResponse, error)
+	GetPrices(height int64) (pricefeedtypes.CurrentPrices, error)
+	GetParams(height int64) (cdptypes.Params, error)
+	GetCdps(height int64, collateralType string) (cdptypes.CDPs, error)
+}
+
+// GrpcLiquidationClient implements LiquidationClient with the cdp and
+// pricefeed grpc query services
+type GrpcLiquidationClient struct {
+	tm        tmservice.ServiceClient
+	cdp       cdptypes.QueryClient
+	pricefeed pricefeedtypes.QueryClient
+	PageLimit int
+}
+
+var _ LiquidationClient = (*GrpcLiquidationClient)(nil)
+
+func NewGrpcLiquidationClient(
+	tm tmservice.ServiceClient,
+	cdp cdptypes.QueryClient,
+	pricefeed pricefeedtypes.QueryClient,
+) *GrpcLiquidationClient {
+	return &GrpcLiquidationClient{
+		tm:        tm,
+		cdp:       cdp,
+		pricefeed: pricefeed,
+		PageLimit: DefaultPageLimit,
+	}
+}
+
+func (c *GrpcLiquidationClient) GetInfo() (*InfoResponse, error) {
+	res, err := c.tm.GetLatestBlock(context.Background(), &tmservice.GetLatestBlockRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &InfoResponse{
+		ChainId:      res.Block.Header.ChainID,
+		LatestHeight: res.Block.Header.Height,
+	}, nil
+}
+
+func (c *GrpcLiquidationClient) GetPrices(height int64) (pricefeedtypes.CurrentPrices, error) {
+	res, err := c.pricefeed.Prices(ctxAtHeight(height), &pricefeedtypes.QueryPricesRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	var prices pricefeedtypes.CurrentPrices
+	for _, price := range res.Prices {
+		prices = append(prices, pricefeedtypes.NewCurrentPrice(price.MarketID, price.Price))
+	}
+
+	return prices, nil
+}
+
+func (c *GrpcLiquidationClient) GetParams(height int64) (cdptypes.Params, error) {
+	res, err := c.cdp.Params(ctxAtHeight(height), &cdptypes.QueryParamsRequest{})
+	if err != nil {
+		return cdptypes.Params{}, err
+	}
+
+	return res.Params, nil
+}
+
+// GetCdps returns every cdp of a collateral type
+//
+// The cdp module paginates by offset and returns no page response, so pages
+// follow by offset until a page is not full.
+func (c *GrpcLiquidationClient) GetCdps(height int64, collateralType string) (cdptypes.CDPs, error) {
+	var cdps cdptypes.CDPs
+
+	pagination := &query.PageRequest{Limit: uint64(c.PageLimit)}
+	for {
+		res, err := c.cdp.Cdps(ctxAtHeight(height), &cdptypes.QueryCdpsRequest{
+			CollateralType: collateralType,
+			Pagination:     pagination,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, cdpRes := range res.Cdps {
+			cdp, err := cdpFromResponse(cdpRes)
+			if err != nil {
+				return nil, err
+			}
+			cdps = append(cdps, cdp)
+		}
+
+		if uint64(len(res.Cdps)) < pagination.Limit {
+			return cdps, nil
+		}
+		pagination = &query.PageRequest{Offset: pagination.Offset + uint64(len(res.Cdps)), Limit: pagination.Limit}
+	}
+}
+
+func cdpFromResponse(res cdptypes.CDPResponse) (cdptypes.CDP, error) {
+	owner, err := sdk.AccAddressFromBech32(res.Owner)
+	if err != nil {
+		return cdptypes.CDP{}, fmt.Errorf("invalid owner %s of cdp %d: %w", res.Owner, res.ID, err)
+	}
+
+	interestFactor, err := sdk.NewDecFromStr(res.InterestFactor)
+	if err != nil {
+		return cdptypes.CDP{}, fmt.Errorf("invalid interest factor of cdp %d: %w", res.ID, err)
+	}
+
+	return cdptypes.NewCDPWithFees(
+		res.ID,
+		owner,
+		res.Collateral,
+		res.Type,
+		res.Principal,
+		res.AccumulatedFees,
+		res.FeesUpdated,
+		interestFactor,
+	), nil
+}
+
+func ctxAtHeight(height int64) context.Context {
+	heightStr := strconv.FormatInt(height, 10)
+	return metadata.AppendToOutgoingContext(context.Background(), grpctypes.GRPCBlockHeightHeader, heightStr)
+}
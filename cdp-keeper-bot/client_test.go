@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	cdptypes "github.com/kava-labs/kava/x/cdp/types"
+	pricefeedtypes "github.com/kava-labs/kava/x/pricefeed/types"
+	"github.com/stretchr/testify/assert"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type MockTmServiceClient struct {
+	tmservice.ServiceClient
+	Header tmproto.Header
+	Err    error
+}
+
+func (m *MockTmServiceClient) GetLatestBlock(ctx context.Context, in *tmservice.GetLatestBlockRequest, opts ...grpc.CallOption) (*tmservice.GetLatestBlockResponse, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return &tmservice.GetLatestBlockResponse{Block: &tmproto.Block{Header: m.Header}}, nil
+}
+
+type MockPricefeedQueryClient struct {
+	pricefeedtypes.QueryClient
+	t             *testing.T
+	Height        int64
+	CurrentPrices pricefeedtypes.CurrentPriceResponses
+	Err           error
+}
+
+func (m *MockPricefeedQueryClient) Prices(ctx context.Context, in *pricefeedtypes.QueryPricesRequest, opts ...grpc.CallOption) (*pricefeedtypes.QueryPricesResponse, error) {
+	assertQueryHeight(m.t, ctx, m.Height)
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return &pricefeedtypes.QueryPricesResponse{Prices: m.CurrentPrices}, nil
+}
+
+type MockCdpQueryClient struct {
+	cdptypes.QueryClient
+	t            *testing.T
+	Height       int64
+	CdpParams    cdptypes.Params
+	CdpResponses cdptypes.CDPResponses
+	Err          error
+}
+
+func (m *MockCdpQueryClient) Params(ctx context.Context, in *cdptypes.QueryParamsRequest, opts ...grpc.CallOption) (*cdptypes.QueryParamsResponse, error) {
+	assertQueryHeight(m.t, ctx, m.Height)
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return &cdptypes.QueryParamsResponse{Params: m.CdpParams}, nil
+}
+
+// Cdps paginates by offset and returns no page response as the cdp module does
+func (m *MockCdpQueryClient) Cdps(ctx context.Context, in *cdptypes.QueryCdpsRequest, opts ...grpc.CallOption) (*cdptypes.QueryCdpsResponse, error) {
+	assertQueryHeight(m.t, ctx, m.Height)
+	assert.Equal(m.t, "bnb-a", in.CollateralType)
+	assert.Empty(m.t, in.Owner)
+	assert.Empty(m.t, in.Pagination.Key)
+	if m.Err != nil {
+		return nil, m.Err
+	}
+
+	total := len(m.CdpResponses)
+	start := int(in.Pagination.Offset)
+	end := start + int(in.Pagination.Limit)
+	if end > total {
+		end = total
+	}
+	if start > total {
+		start = total
+	}
+
+	return &cdptypes.QueryCdpsResponse{Cdps: m.CdpResponses[start:end]}, nil
+}
+
+func assertQueryHeight(t *testing.T, ctx context.Context, height int64) {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, []string{sdk.NewInt(height).String()}, md.Get(grpctypes.GRPCBlockHeightHeader))
+}
+
+func TestGrpcGetInfo(t *testing.T) {
+	tm := &MockTmServiceClient{Header: tmproto.Header{ChainID: "kava-testnet", Height: 1001}}
+	client := NewGrpcLiquidationClient(tm, &MockCdpQueryClient{}, &MockPricefeedQueryClient{})
+
+	info, err := client.GetInfo()
+	assert.Nil(t, err)
+	assert.Equal(t, &InfoResponse{ChainId: "kava-testnet", LatestHeight: 1001}, info)
+
+	tm.Err = errors.New("grpc error")
+	_, err = client.GetInfo()
+	assert.Equal(t, tm.Err, err)
+}
+
+func TestGrpcGetPrices(t *testing.T) {
+	height := int64(1001)
+	pricefeed := &MockPricefeedQueryClient{
+		t:      t,
+		Height: height,
+		CurrentPrices: pricefeedtypes.CurrentPriceResponses{
+			{MarketID: "bnb:usd:30", Price: sdk.MustNewDecFromStr("400.0")},
+		},
+	}
+	client := NewGrpcLiquidationClient(&MockTmServiceClient{}, &MockCdpQueryClient{}, pricefeed)
+
+	prices, err := client.GetPrices(height)
+	assert.Nil(t, err)
+	assert.Equal(t, pricefeedtypes.CurrentPrices{
+		pricefeedtypes.NewCurrentPrice("bnb:usd:30", sdk.MustNewDecFromStr("400.0")),
+	}, prices)
+}
+
+func TestGrpcGetParams(t *testing.T) {
+	height := int64(1001)
+	cdp := &MockCdpQueryClient{t: t, Height: height, CdpParams: testParams()}
+	client := NewGrpcLiquidationClient(&MockTmServiceClient{}, cdp, &MockPricefeedQueryClient{})
+
+	params, err := client.GetParams(height)
+	assert.Nil(t, err)
+	assert.Equal(t, testParams(), params)
+
+	cdp.Err = errors.New("grpc error")
+	_, err = client.GetParams(height)
+	assert.Equal(t, cdp.Err, err)
+}
+
+func TestGrpcGetCdps(t *testing.T) {
+	height := int64(1001)
+
+	var cdps cdptypes.CDPs
+	var responses cdptypes.CDPResponses
+	for i, name := range []string{"owner1", "owner2", "owner3", "owner4", "owner5"} {
+		cdp := testCdp(uint64(i+1), name, "bnb-a", sdk.NewInt64Coin("bnb", 100000000), 200000000, 1000000)
+		cdps = append(cdps, cdp)
+		responses = append(responses, cdptypes.CDPResponse{
+			ID:              cdp.ID,
+			Owner:           cdp.Owner.String(),
+			Type:            cdp.Type,
+			Collateral:      cdp.Collateral,
+			Principal:       cdp.Principal,
+			AccumulatedFees: cdp.AccumulatedFees,
+			FeesUpdated:     cdp.FeesUpdated,
+			InterestFactor:  cdp.InterestFactor.String(),
+		})
+	}
+
+	cdp := &MockCdpQueryClient{t: t, Height: height, CdpResponses: responses}
+	client := NewGrpcLiquidationClient(&MockTmServiceClient{}, cdp, &MockPricefeedQueryClient{})
+
+	for _, pageLimit := range []int{10, 1, 2, 5} {
+		client.PageLimit = pageLimit
+
+		resp, err := client.GetCdps(height, "bnb-a")
+		assert.Nil(t, err)
+		assert.Equal(t, cdps, resp, "page limit %d", pageLimit)
+	}
+
+	cdp.CdpResponses[2].InterestFactor = "invalid"
+	_, err := client.GetCdps(height, "bnb-a")
+	assert.NotNil(t, err)
+	assert.Regexp(t, "invalid interest factor of cdp 3", err.Error())
+
+	cdp.Err = errors.New("grpc error")
+	resp, err := client.GetCdps(height, "bnb-a")
+	assert.Nil(t, resp)
+	assert.Equal(t, cdp.Err, err)
+}
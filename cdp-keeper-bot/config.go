@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	kavaRpcUrlEnvKey             = "KAVA_RPC_URL"
+	kavaGrpcUrlEnvKey            = "KAVA_GRPC_URL"
+	kavaLiqudationIntervalEnvKey = "KAVA_LIQUIDATION_INTERVAL"
+	kavaKeeperAddressEnvKey      = "KAVA_KEEPER_ADDRESS"
+	kavaSignerMnemonicEnvKey     = "KAVA_SIGNER_MNEMONIC"
+	feeBaseGasPriceEnvKey        = "FEE_BASE_GAS_PRICE"
+	feeEscalationRateEnvKey      = "FEE_ESCALATION_RATE"
+	feeMaxAmountEnvKey           = "FEE_MAX_AMOUNT"
+	feeCongestedTxsEnvKey        = "FEE_CONGESTED_MEMPOOL_TXS"
+	feeNodeMinPricesEnvKey       = "FEE_NODE_MIN_GAS_PRICES"
+)
+
+const (
+	defaultFeeDenom          = "ukava"
+	defaultFeeBaseGasPrice   = "0.05"
+	defaultFeeEscalationRate = "0.25"
+	defaultFeeMaxAmount      = "200000"
+)
+
+// ConfigLoader provides an interface for
+// loading config values from a provided key
+type ConfigLoader interface {
+	Get(key string) string
+}
+
+// Config provides application configuration
+type Config struct {
+	KavaRpcUrl              string
+	KavaGrpcUrl             string
+	KavaLiquidationInterval time.Duration
+	KavaKeeperAddress       sdk.AccAddress
+	KavaSignerMnemonic      string
+	Fee                     FeePolicyConfig
+	// FeeNodeMinGasPrices is set when the node's minimum gas prices are used
+	// if higher than the base gas price
+	FeeNodeMinGasPrices bool
+}
+
+// LoadConfig loads key values from a ConfigLoader
+// and returns a new Config
+func LoadConfig(loader ConfigLoader) (Config, error) {
+	grpcUrl := loader.Get(kavaGrpcUrlEnvKey)
+	if grpcUrl == "" {
+		return Config{}, fmt.Errorf("%s not set", kavaGrpcUrlEnvKey)
+	}
+
+	liquidationInterval, err := time.ParseDuration(loader.Get(kavaLiqudationIntervalEnvKey))
+	if err != nil {
+		liquidationInterval = time.Duration(10 * time.Minute)
+	}
+
+	keeperBech32Address := loader.Get(kavaKeeperAddressEnvKey)
+	if keeperBech32Address == "" {
+		return Config{}, fmt.Errorf("%s not set", kavaKeeperAddressEnvKey)
+	}
+
+	keeperAddress, err := sdk.AccAddressFromBech32(keeperBech32Address)
+	if err != nil {
+		return Config{}, err
+	}
+
+	signerMnemonic := loader.Get(kavaSignerMnemonicEnvKey)
+	if signerMnemonic == "" {
+		return Config{}, fmt.Errorf("%s not set", kavaSignerMnemonicEnvKey)
+	}
+
+	fee, err := loadFeePolicyConfig(loader)
+	if err != nil {
+		return Config{}, err
+	}
+
+	feeNodeMinGasPrices := false
+	if raw := loader.Get(feeNodeMinPricesEnvKey); raw != "" {
+		feeNodeMinGasPrices, err = strconv.ParseBool(raw)
+		if err != nil {
+			return Config{}, fmt.Errorf("%s invalid bool: %v", feeNodeMinPricesEnvKey, err)
+		}
+	}
+
+	// the rpc is only used to check mempool congestion
+	rpcUrl := loader.Get(kavaRpcUrlEnvKey)
+	if rpcUrl == "" && fee.CongestedMempoolTxs > 0 {
+		return Config{}, fmt.Errorf("%s not set, required by %s", kavaRpcUrlEnvKey, feeCongestedTxsEnvKey)
+	}
+
+	return Config{
+		KavaRpcUrl:              rpcUrl,
+		KavaGrpcUrl:             grpcUrl,
+		KavaLiquidationInterval: liquidationInterval,
+		KavaKeeperAddress:       keeperAddress,
+		KavaSignerMnemonic:      signerMnemonic,
+		Fee:                     fee,
+		FeeNodeMinGasPrices:     feeNodeMinGasPrices,
+	}, nil
+}
+
+// loadFeePolicyConfig loads the gas price, escalation and fee cap used to
+// price liquidation txs, using defaults for any that are not set
+func loadFeePolicyConfig(loader ConfigLoader) (FeePolicyConfig, error) {
+	getOrDefault := func(key, fallback string) string {
+		if value := loader.Get(key); value != "" {
+			return value
+		}
+		return fallback
+	}
+
+	baseGasPrice, err := math.LegacyNewDecFromStr(getOrDefault(feeBaseGasPriceEnvKey, defaultFeeBaseGasPrice))
+	if err != nil {
+		return FeePolicyConfig{}, fmt.Errorf("%s invalid decimal: %v", feeBaseGasPriceEnvKey, err)
+	}
+
+	escalationRate, err := math.LegacyNewDecFromStr(getOrDefault(feeEscalationRateEnvKey, defaultFeeEscalationRate))
+	if err != nil {
+		return FeePolicyConfig{}, fmt.Errorf("%s invalid decimal: %v", feeEscalationRateEnvKey, err)
+	}
+
+	maxFee, ok := math.NewIntFromString(getOrDefault(feeMaxAmountEnvKey, defaultFeeMaxAmount))
+	if !ok {
+		return FeePolicyConfig{}, fmt.Errorf("%s invalid integer", feeMaxAmountEnvKey)
+	}
+
+	// congestion is not checked unless a threshold is set
+	congestedMempoolTxs := 0
+	if raw := loader.Get(feeCongestedTxsEnvKey); raw != "" {
+		congestedMempoolTxs, err = strconv.Atoi(raw)
+		if err != nil {
+			return FeePolicyConfig{}, fmt.Errorf("%s invalid: %v", feeCongestedTxsEnvKey, err)
+		}
+	}
+
+	config := FeePolicyConfig{
+		Denom:               defaultFeeDenom,
+		BaseGasPrice:        baseGasPrice,
+		EscalationRate:      escalationRate,
+		MaxFee:              maxFee,
+		CongestedMempoolTxs: congestedMempoolTxs,
+	}
+	if err := config.Validate(); err != nil {
+		return FeePolicyConfig{}, fmt.Errorf("invalid fee policy: %w", err)
+	}
+
+	return config, nil
+}
+
+// EnvLoader loads keys from os environment
+type EnvLoader struct {
+}
+
+// Get retrieves key from environment
+func (l *EnvLoader) Get(key string) string {
+	return os.Getenv(key)
+}
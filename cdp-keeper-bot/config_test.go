@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/tendermint/crypto"
+)
+
+type testEnvLoader struct {
+	Env map[string]string
+}
+
+func (l *testEnvLoader) Get(key string) string {
+	return l.Env[key]
+}
+
+func testEnv() map[string]string {
+	return map[string]string{
+		"KAVA_GRPC_URL":        "https://grpc.testnet.kava.io:443",
+		"KAVA_KEEPER_ADDRESS":  sdk.AccAddress(crypto.AddressHash([]byte("keeper"))).String(),
+		"KAVA_SIGNER_MNEMONIC": "arrive guide way exit polar print kitchen hair series custom siege afraid shrug crew fashion mind script divorce pattern trust project regular robust safe",
+	}
+}
+
+func TestConfigLoading(t *testing.T) {
+	loader := &testEnvLoader{Env: testEnv()}
+
+	config, err := LoadConfig(loader)
+	assert.NoError(t, err)
+	assert.Equal(t, Config{
+		KavaGrpcUrl:             loader.Env["KAVA_GRPC_URL"],
+		KavaLiquidationInterval: 10 * time.Minute,
+		KavaKeeperAddress:       sdk.AccAddress(crypto.AddressHash([]byte("keeper"))),
+		KavaSignerMnemonic:      loader.Env["KAVA_SIGNER_MNEMONIC"],
+		Fee: FeePolicyConfig{
+			Denom:          "ukava",
+			BaseGasPrice:   math.LegacyMustNewDecFromStr("0.05"),
+			EscalationRate: math.LegacyMustNewDecFromStr("0.25"),
+			MaxFee:         math.NewInt(200000),
+		},
+	}, config)
+
+	loader.Env["KAVA_LIQUIDATION_INTERVAL"] = "5m"
+	config, err = LoadConfig(loader)
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, config.KavaLiquidationInterval)
+}
+
+func TestConfigMissingKeys(t *testing.T) {
+	for _, key := range []string{"KAVA_GRPC_URL", "KAVA_KEEPER_ADDRESS", "KAVA_SIGNER_MNEMONIC"} {
+		env := testEnv()
+		delete(env, key)
+
+		_, err := LoadConfig(&testEnvLoader{Env: env})
+		assert.EqualError(t, err, key+" not set")
+	}
+
+	env := testEnv()
+	env["KAVA_KEEPER_ADDRESS"] = "invalid"
+	_, err := LoadConfig(&testEnvLoader{Env: env})
+	assert.Error(t, err)
+}
+
+func TestConfigFeePolicy(t *testing.T) {
+	env := testEnv()
+	env["FEE_BASE_GAS_PRICE"] = "0.1"
+	env["FEE_NODE_MIN_GAS_PRICES"] = "true"
+	config, err := LoadConfig(&testEnvLoader{Env: env})
+	assert.NoError(t, err)
+	assert.Equal(t, math.LegacyMustNewDecFromStr("0.1"), config.Fee.BaseGasPrice)
+	assert.True(t, config.FeeNodeMinGasPrices)
+
+	// congestion checks query the rpc mempool
+	env["FEE_CONGESTED_MEMPOOL_TXS"] = "1000"
+	_, err = LoadConfig(&testEnvLoader{Env: env})
+	assert.EqualError(t, err, "KAVA_RPC_URL not set, required by FEE_CONGESTED_MEMPOOL_TXS")
+	env["KAVA_RPC_URL"] = "https://rpc.testnet.kava.io:443"
+	config, err = LoadConfig(&testEnvLoader{Env: env})
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, config.Fee.CongestedMempoolTxs)
+
+	env["FEE_MAX_AMOUNT"] = "0"
+	_, err = LoadConfig(&testEnvLoader{Env: env})
+	assert.Regexp(t, "max fee must be positive", err.Error())
+
+	env["FEE_MAX_AMOUNT"] = "200000"
+	env["FEE_NODE_MIN_GAS_PRICES"] = "yes"
+	_, err = LoadConfig(&testEnvLoader{Env: env})
+	assert.Regexp(t, "FEE_NODE_MIN_GAS_PRICES invalid bool", err.Error())
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"sort"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+)
+
+type Position struct {
+	ID              uint64
+	Owner           sdk.AccAddress
+	CollateralType  string
+	Collateral      sdk.Coin
+	Principal       sdk.Coin
+	AccumulatedFees sdk.Coin
+}
+
+type Positions []Position
+
+// CollateralInfo is the liquidation params of a collateral type with the
+// price of its liquidation market
+type CollateralInfo struct {
+	Price            sdk.Dec
+	LiquidationRatio sdk.Dec
+	// ConversionFactor is the number of decimals of the collateral denom
+	ConversionFactor sdk.Int
+}
+
+type PositionData struct {
+	Height      int64
+	Collaterals map[string]CollateralInfo
+	// DebtConversionFactor is the number of decimals of the debt denom
+	DebtConversionFactor sdk.Int
+	Positions            Positions
+	// Unpriced are the collateral types without a liquidation price, their
+	// positions can not be valued
+	Unpriced []string
+}
+
+// Priced returns true if the collateral of a position has a price
+func (d *PositionData) Priced(pos Position) bool {
+	_, ok := d.Collaterals[pos.CollateralType]
+	return ok
+}
+
+func GetPositionData(logger zerolog.Logger, client LiquidationClient) (*PositionData, error) {
+	// fetch chain info to get height
+	info, err := client.GetInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	// use height to get consistent state across queries
+	height := info.LatestHeight
+
+	logger.Debug().Int64("height", height).Msg("getting cdp params")
+	params, err := client.GetParams(height)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debug().Int64("height", height).Msg("getting price data")
+	prices, err := client.GetPrices(height)
+	if err != nil {
+		return nil, err
+	}
+
+	// map price data
+	priceData := make(map[string]sdk.Dec)
+	for _, price := range prices {
+		priceData[price.MarketID] = price.Price
+	}
+
+	// loop collateral params and create CollateralInfo, cdps are liquidated
+	// at the price of the liquidation market
+	collaterals := make(map[string]CollateralInfo)
+	var unpriced []string
+	for _, param := range params.CollateralParams {
+		price, ok := priceData[param.LiquidationMarketID]
+		if !ok || !price.IsPositive() {
+			unpriced = append(unpriced, param.Type)
+			continue
+		}
+
+		collaterals[param.Type] = CollateralInfo{
+			Price:            price,
+			LiquidationRatio: param.LiquidationRatio,
+			ConversionFactor: param.ConversionFactor,
+		}
+	}
+	sort.Strings(unpriced)
+
+	var positions Positions
+	for _, param := range params.CollateralParams {
+		// cdps of unpriced collateral types are skipped, the types are reported in Unpriced
+		if _, ok := collaterals[param.Type]; !ok {
+			continue
+		}
+
+		logger.Debug().Int64("height", height).Str("collateral_type", param.Type).Msg("getting cdps")
+		cdps, err := client.GetCdps(height, param.Type)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, cdp := range cdps {
+			positions = append(positions, Position{
+				ID:              cdp.ID,
+				Owner:           cdp.Owner,
+				CollateralType:  cdp.Type,
+				Collateral:      cdp.Collateral,
+				Principal:       cdp.Principal,
+				AccumulatedFees: cdp.AccumulatedFees,
+			})
+		}
+	}
+
+	logger.Debug().
+		Int64("height", height).
+		Int("positions", len(positions)).
+		Msg("positions fetched")
+
+	return &PositionData{
+		Height:               height,
+		Collaterals:          collaterals,
+		DebtConversionFactor: params.DebtParam.ConversionFactor,
+		Positions:            positions,
+		Unpriced:             unpriced,
+	}, nil
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	cdptypes "github.com/kava-labs/kava/x/cdp/types"
+	pricefeedtypes "github.com/kava-labs/kava/x/pricefeed/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/tendermint/crypto"
+)
+
+type MockClient struct {
+	t              *testing.T
+	ExpectedHeight int64
+	InfoErr        error
+	Prices         pricefeedtypes.CurrentPrices
+	PricesErr      error
+	Params         cdptypes.Params
+	ParamsErr      error
+	Cdps           map[string]cdptypes.CDPs
+	CdpsErr        error
+}
+
+func (m MockClient) GetInfo() (*InfoResponse, error) {
+	return &InfoResponse{
+		ChainId:      "kava-testnet",
+		LatestHeight: m.ExpectedHeight,
+	}, m.InfoErr
+}
+
+func (m MockClient) GetPrices(height int64) (pricefeedtypes.CurrentPrices, error) {
+	m.checkHeight(height)
+	return m.Prices, m.PricesErr
+}
+
+func (m MockClient) GetParams(height int64) (cdptypes.Params, error) {
+	m.checkHeight(height)
+	return m.Params, m.ParamsErr
+}
+
+func (m MockClient) GetCdps(height int64, collateralType string) (cdptypes.CDPs, error) {
+	m.checkHeight(height)
+	if _, ok := m.Cdps[collateralType]; !ok {
+		m.t.Fatalf("unexpected cdp query for %s", collateralType)
+	}
+	return m.Cdps[collateralType], m.CdpsErr
+}
+
+func (m MockClient) checkHeight(height int64) {
+	if m.ExpectedHeight != height {
+		m.t.Fatalf("unexpected height %d", height)
+	}
+}
+
+func testParams() cdptypes.Params {
+	return cdptypes.Params{
+		CollateralParams: cdptypes.CollateralParams{
+			{
+				Denom:               "bnb",
+				Type:                "bnb-a",
+				LiquidationRatio:    sdk.MustNewDecFromStr("1.5"),
+				SpotMarketID:        "bnb:usd",
+				LiquidationMarketID: "bnb:usd:30",
+				ConversionFactor:    sdk.NewInt(8),
+			},
+			{
+				Denom:               "busd",
+				Type:                "busd-a",
+				LiquidationRatio:    sdk.MustNewDecFromStr("1.01"),
+				SpotMarketID:        "busd:usd",
+				LiquidationMarketID: "busd:usd:30",
+				ConversionFactor:    sdk.NewInt(8),
+			},
+		},
+		DebtParam: cdptypes.DebtParam{
+			Denom:            "usdx",
+			ReferenceAsset:   "usd",
+			ConversionFactor: sdk.NewInt(6),
+		},
+	}
+}
+
+func testCdp(id uint64, name string, collateralType string, collateral sdk.Coin, principal int64, fees int64) cdptypes.CDP {
+	return cdptypes.NewCDPWithFees(
+		id,
+		sdk.AccAddress(crypto.AddressHash([]byte(name))),
+		collateral,
+		collateralType,
+		sdk.NewInt64Coin("usdx", principal),
+		sdk.NewInt64Coin("usdx", fees),
+		time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC),
+		sdk.OneDec(),
+	)
+}
+
+func TestGetPositionDataClientErrors(t *testing.T) {
+	tests := []MockClient{
+		{
+			t:              t,
+			ExpectedHeight: int64(1001),
+			InfoErr:        errors.New("error in info"),
+		},
+		{
+			t:              t,
+			ExpectedHeight: int64(1001),
+			ParamsErr:      errors.New("error in params"),
+		},
+		{
+			t:              t,
+			ExpectedHeight: int64(1001),
+			Params:         testParams(),
+			PricesErr:      errors.New("error in prices"),
+		},
+		{
+			t:              t,
+			ExpectedHeight: int64(1001),
+			Params:         testParams(),
+			Prices: pricefeedtypes.CurrentPrices{
+				{MarketID: "bnb:usd:30", Price: sdk.MustNewDecFromStr("400.0")},
+			},
+			Cdps:    map[string]cdptypes.CDPs{"bnb-a": nil},
+			CdpsErr: errors.New("error in cdps"),
+		},
+	}
+
+	for _, client := range tests {
+		data, err := GetPositionData(zerolog.Nop(), client)
+		assert.Nil(t, data)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestGetPositionDataAllData(t *testing.T) {
+	client := MockClient{
+		t:              t,
+		ExpectedHeight: int64(1001),
+		Params:         testParams(),
+		Prices: pricefeedtypes.CurrentPrices{
+			// spot prices are not used to liquidate
+			{MarketID: "bnb:usd", Price: sdk.MustNewDecFromStr("350.0")},
+			{MarketID: "bnb:usd:30", Price: sdk.MustNewDecFromStr("400.0")},
+			{MarketID: "busd:usd:30", Price: sdk.MustNewDecFromStr("1.0")},
+		},
+		Cdps: map[string]cdptypes.CDPs{
+			"bnb-a": {
+				testCdp(1, "owner1", "bnb-a", sdk.NewInt64Coin("bnb", 100000000), 290000000, 10000000),
+				testCdp(2, "owner2", "bnb-a", sdk.NewInt64Coin("bnb", 1000000000), 1000000000, 0),
+			},
+			"busd-a": {
+				testCdp(3, "owner1", "busd-a", sdk.NewInt64Coin("busd", 10000000000), 98000000, 0),
+			},
+		},
+	}
+
+	data, err := GetPositionData(zerolog.Nop(), client)
+	assert.Nil(t, err)
+
+	assert.Equal(t, &PositionData{
+		Height: 1001,
+		Collaterals: map[string]CollateralInfo{
+			"bnb-a": {
+				Price:            sdk.MustNewDecFromStr("400.0"),
+				LiquidationRatio: sdk.MustNewDecFromStr("1.5"),
+				ConversionFactor: sdk.NewInt(8),
+			},
+			"busd-a": {
+				Price:            sdk.MustNewDecFromStr("1.0"),
+				LiquidationRatio: sdk.MustNewDecFromStr("1.01"),
+				ConversionFactor: sdk.NewInt(8),
+			},
+		},
+		DebtConversionFactor: sdk.NewInt(6),
+		Positions: Positions{
+			{
+				ID:              1,
+				Owner:           sdk.AccAddress(crypto.AddressHash([]byte("owner1"))),
+				CollateralType:  "bnb-a",
+				Collateral:      sdk.NewInt64Coin("bnb", 100000000),
+				Principal:       sdk.NewInt64Coin("usdx", 290000000),
+				AccumulatedFees: sdk.NewInt64Coin("usdx", 10000000),
+			},
+			{
+				ID:              2,
+				Owner:           sdk.AccAddress(crypto.AddressHash([]byte("owner2"))),
+				CollateralType:  "bnb-a",
+				Collateral:      sdk.NewInt64Coin("bnb", 1000000000),
+				Principal:       sdk.NewInt64Coin("usdx", 1000000000),
+				AccumulatedFees: sdk.NewInt64Coin("usdx", 0),
+			},
+			{
+				ID:              3,
+				Owner:           sdk.AccAddress(crypto.AddressHash([]byte("owner1"))),
+				CollateralType:  "busd-a",
+				Collateral:      sdk.NewInt64Coin("busd", 10000000000),
+				Principal:       sdk.NewInt64Coin("usdx", 98000000),
+				AccumulatedFees: sdk.NewInt64Coin("usdx", 0),
+			},
+		},
+	}, data)
+
+	assert.Equal(t, Positions{data.Positions[0]}, GetCdpsToLiquidate(data))
+}
+
+func TestGetPositionDataUnpricedCollateral(t *testing.T) {
+	client := MockClient{
+		t:              t,
+		ExpectedHeight: int64(1001),
+		Params:         testParams(),
+		Prices: pricefeedtypes.CurrentPrices{
+			// only the spot price of bnb is set
+			{MarketID: "bnb:usd", Price: sdk.MustNewDecFromStr("400.0")},
+			{MarketID: "busd:usd:30", Price: sdk.MustNewDecFromStr("1.0")},
+		},
+		// bnb-a cdps are not queried
+		Cdps: map[string]cdptypes.CDPs{
+			"busd-a": {
+				testCdp(3, "owner1", "busd-a", sdk.NewInt64Coin("busd", 10000000000), 99500000, 500000),
+			},
+		},
+	}
+
+	data, err := GetPositionData(zerolog.Nop(), client)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"bnb-a"}, data.Unpriced)
+	assert.Len(t, data.Collaterals, 1)
+	assert.Len(t, data.Positions, 1)
+	assert.True(t, data.Priced(data.Positions[0]))
+	assert.Equal(t, data.Positions, GetCdpsToLiquidate(data))
+
+	// zero prices are not used
+	client.Prices = append(client.Prices, pricefeedtypes.CurrentPrice{MarketID: "bnb:usd:30", Price: sdk.ZeroDec()})
+	data, err = GetPositionData(zerolog.Nop(), client)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"bnb-a"}, data.Unpriced)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeePolicyRetries(t *testing.T) {
+	policy, err := NewFeePolicy(FeePolicyConfig{
+		Denom:          "ukava",
+		BaseGasPrice:   math.LegacyMustNewDecFromStr("0.05"),
+		EscalationRate: math.LegacyMustNewDecFromStr("0.25"),
+		MaxFee:         math.NewInt(70000),
+	})
+	assert.NoError(t, err)
+	retries := NewFeeRetries()
+
+	pos := Position{ID: 7}
+	fee := func() sdk.Coins {
+		return policy.Fee(liquidationGasLimit, retries.Retries(cdpKey(pos)))
+	}
+	assert.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("ukava", 50000)), fee())
+
+	// failed liquidations are sent again with escalated fees, up to the max
+	retries.Failed(cdpKey(pos))
+	assert.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("ukava", 62500)), fee())
+	retries.Failed(cdpKey(pos))
+	assert.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("ukava", 70000)), fee())
+
+	// other cdps are not escalated
+	assert.Equal(t, 0, retries.Retries(cdpKey(Position{ID: 8})))
+
+	retries.Succeeded(cdpKey(pos))
+	assert.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("ukava", 50000)), fee())
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	kavagrpc "github.com/kava-labs/go-tools/grpc"
+	"github.com/kava-labs/go-tools/signing"
+	"github.com/kava-labs/kava/app"
+	cdptypes "github.com/kava-labs/kava/x/cdp/types"
+	pricefeedtypes "github.com/kava-labs/kava/x/pricefeed/types"
+	"github.com/rs/zerolog"
+	rpchttpclient "github.com/tendermint/tendermint/rpc/client/http"
+)
+
+// liquidationGasLimit is the gas limit of a liquidation, which seizes the
+// collateral of the cdp and starts its auctions
+const liquidationGasLimit = uint64(1000000)
+
+func main() {
+	app.SetSDKConfig()
+	encodingConfig := app.MakeEncodingConfig()
+	logger := zerolog.New(os.Stderr)
+
+	config, err := LoadConfig(&EnvLoader{})
+	if err != nil {
+		logger.Fatal().Err(err).Send()
+	}
+
+	conn, err := kavagrpc.NewGrpcConnection(config.KavaGrpcUrl)
+	if err != nil {
+		logger.Fatal().Err(err).Send()
+	}
+	defer conn.Close()
+
+	// the rpc is optional, it is only set for mempool congestion checks
+	var http *rpchttpclient.HTTP
+	if config.KavaRpcUrl != "" {
+		http, err = rpchttpclient.New(config.KavaRpcUrl, "/websocket")
+		if err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+	}
+
+	tmClient := tmservice.NewServiceClient(conn)
+	liquidationClient := NewGrpcLiquidationClient(
+		tmClient,
+		cdptypes.NewQueryClient(conn),
+		pricefeedtypes.NewQueryClient(conn),
+	)
+
+	nodeInfoResponse, err := tmClient.GetNodeInfo(context.Background(), &tmservice.GetNodeInfoRequest{})
+	if err != nil {
+		logger.Fatal().Err(err).Send()
+	}
+
+	txClient := txtypes.NewServiceClient(conn)
+	authClient := authtypes.NewQueryClient(conn)
+
+	hdPath := hd.CreateHDPath(app.Bip44CoinType, 0, 0)
+	privKeyBytes, err := hd.Secp256k1.Derive()(config.KavaSignerMnemonic, "", hdPath.String())
+	if err != nil {
+		panic(err)
+	}
+	privKey := &secp256k1.PrivKey{Key: privKeyBytes}
+
+	signer := signing.NewSigner(
+		nodeInfoResponse.DefaultNodeInfo.Network,
+		signing.EncodingConfigAdapter{EncodingConfig: encodingConfig},
+		authClient,
+		txClient,
+		privKey,
+		10,
+		logger,
+	)
+
+	// fees escalate for cdps whose liquidation failed and while the mempool
+	// is congested
+	feePolicy, err := NewFeePolicy(config.Fee)
+	if err != nil {
+		logger.Fatal().Err(err).Send()
+	}
+	feeRetries := NewFeeRetries()
+
+	// channels to communicate with signer
+	requests := make(chan signing.MsgRequest)
+
+	// signer starts it's own go routines and returns
+	responses, err := signer.Run(requests)
+	if err != nil {
+		logger.Fatal().Err(err).Send()
+	}
+
+	// log responses, if responses are not read, requests will block
+	go func() {
+		for {
+			// response is not returned until the msg is committed to a block
+			response := <-responses
+			pos, _ := response.Request.Data.(Position)
+
+			// error will be set if response is not Code 0 (success) or Code 19 (already in mempool)
+			if response.Err != nil {
+				feeRetries.Failed(cdpKey(pos))
+				logger.Warn().
+					Err(response.Err).
+					Uint32("code", response.Result.Code).
+					Uint64("cdp_id", pos.ID).
+					Str("owner", pos.Owner.String()).
+					Msg("liquidation failed to broadcast")
+				continue
+			}
+
+			feeRetries.Succeeded(cdpKey(pos))
+
+			// code and result are from broadcast, not deliver tx
+			logger.Info().
+				Str("tx_hash", response.Result.TxHash).
+				Uint64("cdp_id", pos.ID).
+				Str("owner", pos.Owner.String()).
+				Msg("liquidation committed")
+		}
+	}()
+
+	for {
+		// fetch collateral and cdp data using client
+		data, err := GetPositionData(logger, liquidationClient)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to fetch cdp data")
+			time.Sleep(config.KavaLiquidationInterval)
+			continue
+		}
+
+		if len(data.Unpriced) > 0 {
+			logger.Warn().
+				Strs("unpriced", data.Unpriced).
+				Msg("collateral types without a liquidation price, skipping their cdps")
+		}
+
+		// calculate cdps to liquidate from collateral and cdp data
+		cdpsToLiquidate := GetCdpsToLiquidate(data)
+		logger.Info().
+			Int64("height", data.Height).
+			Int("positions", len(data.Positions)).
+			Int("liquidatable", len(cdpsToLiquidate)).
+			Msg("cdps checked")
+
+		// failed updates keep the previous values, fees are still priced from
+		// the base gas price if the node does not support the queries
+		if config.FeeNodeMinGasPrices {
+			if err := feePolicy.UpdateMinGasPrices(context.Background(), conn); err != nil {
+				logger.Warn().Err(err).Msg("failed to update node minimum gas prices")
+			}
+		}
+		if http != nil {
+			if err := feePolicy.UpdateCongestion(context.Background(), http); err != nil {
+				logger.Warn().Err(err).Msg("failed to update mempool congestion")
+			}
+		}
+
+		// create liquidation transactions
+		msgs := CreateLiquidationMsgs(config.KavaKeeperAddress, cdpsToLiquidate)
+		for i := range msgs {
+			pos := cdpsToLiquidate[i]
+			retries := feeRetries.Retries(cdpKey(pos))
+			logger.Info().
+				Uint64("cdp_id", pos.ID).
+				Str("owner", pos.Owner.String()).
+				Str("collateral_type", pos.CollateralType).
+				Int("retries", retries).
+				Msg("sending liquidation")
+
+			requests <- signing.MsgRequest{
+				Msgs:      []sdk.Msg{&msgs[i]},
+				GasLimit:  liquidationGasLimit,
+				FeeAmount: feePolicy.Fee(liquidationGasLimit, retries),
+				Memo:      "",
+				Data:      pos,
+			}
+		}
+
+		// wait for next interval
+		time.Sleep(config.KavaLiquidationInterval)
+	}
+}
+
+// cdpKey identifies a cdp in the fee retries
+func cdpKey(pos Position) string {
+	return strconv.FormatUint(pos.ID, 10)
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	cdptypes "github.com/kava-labs/kava/x/cdp/types"
+)
+
+func CreateLiquidationMsgs(keeper sdk.AccAddress, positions Positions) []cdptypes.MsgLiquidate {
+	msgs := make([]cdptypes.MsgLiquidate, len(positions))
+
+	for index, pos := range positions {
+		msgs[index] = cdptypes.NewMsgLiquidate(keeper, pos.Owner, pos.CollateralType)
+	}
+
+	return msgs
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	cdptypes "github.com/kava-labs/kava/x/cdp/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/tendermint/crypto"
+)
+
+func TestCreateLiquidationMsgs(t *testing.T) {
+	type testLiquidationMsgs struct {
+		keeper       sdk.AccAddress
+		positions    Positions
+		expectedMsgs []cdptypes.MsgLiquidate
+	}
+
+	keeper := sdk.AccAddress(crypto.AddressHash([]byte("keeper----------")))
+
+	tests := []testLiquidationMsgs{
+		{
+			keeper:       keeper,
+			positions:    Positions{},
+			expectedMsgs: []cdptypes.MsgLiquidate{},
+		},
+		{
+			keeper: keeper,
+			positions: Positions{
+				{ID: 1, Owner: sdk.AccAddress(crypto.AddressHash([]byte("owner1----------"))), CollateralType: "bnb-a"},
+				{ID: 2, Owner: sdk.AccAddress(crypto.AddressHash([]byte("owner1----------"))), CollateralType: "busd-a"},
+				{ID: 3, Owner: sdk.AccAddress(crypto.AddressHash([]byte("owner2----------"))), CollateralType: "bnb-a"},
+			},
+			expectedMsgs: []cdptypes.MsgLiquidate{
+				{
+					Keeper:         keeper.String(),
+					Borrower:       sdk.AccAddress(crypto.AddressHash([]byte("owner1----------"))).String(),
+					CollateralType: "bnb-a",
+				},
+				{
+					Keeper:         keeper.String(),
+					Borrower:       sdk.AccAddress(crypto.AddressHash([]byte("owner1----------"))).String(),
+					CollateralType: "busd-a",
+				},
+				{
+					Keeper:         keeper.String(),
+					Borrower:       sdk.AccAddress(crypto.AddressHash([]byte("owner2----------"))).String(),
+					CollateralType: "bnb-a",
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		msgs := CreateLiquidationMsgs(tc.keeper, tc.positions)
+		assert.Equal(t, tc.expectedMsgs, msgs)
+	}
+}
@@ -0,0 +1,200 @@
+// Package feepolicy prices tx fees for the bots, escalating the gas price for
+// retried requests and while the mempool is congested
+//
+// It depends only on cosmossdk.io/math so it can be shared by modules built
+// against different cosmos-sdk versions, callers fetch the node minimum gas
+// price and mempool size with their own clients.
+package feepolicy
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"cosmossdk.io/math"
+)
+
+// denomRegex matches the cosmos-sdk default denom format
+var denomRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9/:._-]{2,127}$`)
+
+// Config sets how fees are priced for signed txs
+type Config struct {
+	// Denom is the denom fees are paid in
+	Denom string
+	// BaseGasPrice is the gas price used when the node minimum is lower and
+	// there is no congestion or retry
+	BaseGasPrice math.LegacyDec
+	// EscalationRate is the fraction the gas price increases by for each
+	// retry of a request, and while the mempool is congested
+	EscalationRate math.LegacyDec
+	// MaxFee caps the fee of a single tx, regardless of escalation
+	MaxFee math.Int
+	// CongestedMempoolTxs is the number of unconfirmed txs at which the
+	// mempool is treated as congested, zero disables congestion checks
+	CongestedMempoolTxs int
+}
+
+// Validate returns an error if the gas price, escalation rate or max fee are
+// not usable
+func (c Config) Validate() error {
+	if !denomRegex.MatchString(c.Denom) {
+		return fmt.Errorf("invalid fee denom: %q", c.Denom)
+	}
+	if c.BaseGasPrice.IsNil() || c.BaseGasPrice.IsNegative() {
+		return fmt.Errorf("base gas price must not be negative, got %s", c.BaseGasPrice)
+	}
+	if c.EscalationRate.IsNil() || c.EscalationRate.IsNegative() {
+		return fmt.Errorf("escalation rate must not be negative, got %s", c.EscalationRate)
+	}
+	if c.MaxFee.IsNil() || !c.MaxFee.IsPositive() {
+		return fmt.Errorf("max fee must be positive, got %s", c.MaxFee)
+	}
+	if c.CongestedMempoolTxs < 0 {
+		return fmt.Errorf("congested mempool txs must not be negative, got %d", c.CongestedMempoolTxs)
+	}
+
+	return nil
+}
+
+// Policy prices tx fees from a base gas price, raised to the node's minimum
+// gas price if that is higher, and escalated for retried requests and while
+// the mempool is congested
+//
+// Policy is safe for concurrent use, node and mempool state are set by the
+// caller so the fee can be calculated without blocking on the network.
+type Policy struct {
+	config Config
+
+	mu          sync.RWMutex
+	minGasPrice math.LegacyDec
+	congested   bool
+}
+
+func New(config Config) (*Policy, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &Policy{
+		config:      config,
+		minGasPrice: math.LegacyZeroDec(),
+	}, nil
+}
+
+// Denom returns the denom fees are paid in
+func (p *Policy) Denom() string {
+	return p.config.Denom
+}
+
+// SetMinGasPrice sets the node's minimum gas price in the fee denom
+func (p *Policy) SetMinGasPrice(price math.LegacyDec) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.minGasPrice = price
+}
+
+// SetCongested sets if the mempool is congested
+func (p *Policy) SetCongested(congested bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.congested = congested
+}
+
+// ChecksCongestion returns true if a congestion threshold is set
+func (p *Policy) ChecksCongestion() bool {
+	return p.config.CongestedMempoolTxs > 0
+}
+
+// SetUnconfirmedTxs sets the mempool as congested if the number of
+// unconfirmed txs is at the congestion threshold, it does nothing if
+// congestion checks are disabled
+func (p *Policy) SetUnconfirmedTxs(total int) {
+	if !p.ChecksCongestion() {
+		return
+	}
+
+	p.SetCongested(total >= p.config.CongestedMempoolTxs)
+}
+
+// GasPrice returns the gas price for a request that has been retried the
+// given number of times
+func (p *Policy) GasPrice(retries int) math.LegacyDec {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	gasPrice := math.LegacyMaxDec(p.config.BaseGasPrice, p.minGasPrice)
+
+	escalations := retries
+	if p.congested {
+		escalations++
+	}
+	if escalations > 0 {
+		gasPrice = gasPrice.Mul(math.LegacyOneDec().Add(p.config.EscalationRate).Power(uint64(escalations)))
+	}
+
+	return gasPrice
+}
+
+// FeeAmount returns the fee in the fee denom for a tx with the gas limit that
+// has been retried the given number of times, capped at the max fee
+func (p *Policy) FeeAmount(gasLimit uint64, retries int) math.Int {
+	// ceil so the fee stays over the gas price on rounding
+	amount := p.GasPrice(retries).MulInt(math.NewIntFromUint64(gasLimit)).Ceil().TruncateInt()
+	if amount.GT(p.config.MaxFee) {
+		amount = p.config.MaxFee
+	}
+
+	return amount
+}
+
+// Retries counts the failed attempts of requests by key, so requests sent
+// again pay escalated fees
+//
+// Retries is safe for concurrent use.
+type Retries struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func NewRetries() *Retries {
+	return &Retries{
+		counts: make(map[string]int),
+	}
+}
+
+// Failed records a failed attempt for each key
+func (r *Retries) Failed(keys ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, key := range keys {
+		r.counts[key]++
+	}
+}
+
+// Succeeded clears the failed attempts of each key
+func (r *Retries) Succeeded(keys ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, key := range keys {
+		delete(r.counts, key)
+	}
+}
+
+// Retries returns the most failed attempts of any of the keys
+func (r *Retries) Retries(keys ...string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	retries := 0
+	for _, key := range keys {
+		if r.counts[key] > retries {
+			retries = r.counts[key]
+		}
+	}
+
+	return retries
+}
@@ -0,0 +1,91 @@
+package feepolicy
+
+import (
+	"testing"
+
+	"cosmossdk.io/math"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig() Config {
+	return Config{
+		Denom:               "ukava",
+		BaseGasPrice:        math.LegacyMustNewDecFromStr("0.05"),
+		EscalationRate:      math.LegacyMustNewDecFromStr("0.5"),
+		MaxFee:              math.NewInt(100_000),
+		CongestedMempoolTxs: 1000,
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	require.NoError(t, testConfig().Validate())
+
+	config := testConfig()
+	config.Denom = ""
+	require.Error(t, config.Validate())
+
+	config = testConfig()
+	config.BaseGasPrice = math.LegacyMustNewDecFromStr("-0.01")
+	require.Error(t, config.Validate())
+
+	config = testConfig()
+	config.EscalationRate = math.LegacyDec{}
+	require.Error(t, config.Validate())
+
+	config = testConfig()
+	config.MaxFee = math.ZeroInt()
+	require.Error(t, config.Validate())
+
+	config = testConfig()
+	config.CongestedMempoolTxs = -1
+	require.Error(t, config.Validate())
+}
+
+func TestPolicy(t *testing.T) {
+	policy, err := New(testConfig())
+	require.NoError(t, err)
+
+	require.Equal(t, math.NewInt(15_000), policy.FeeAmount(300_000, 0))
+
+	// each retry escalates the gas price
+	require.Equal(t, math.LegacyMustNewDecFromStr("0.075"), policy.GasPrice(1))
+	require.Equal(t, math.NewInt(33_750), policy.FeeAmount(300_000, 2))
+
+	// fees are capped
+	require.Equal(t, math.NewInt(100_000), policy.FeeAmount(3_000_000, 1))
+
+	// the node minimum is used when higher than the base price
+	policy.SetMinGasPrice(math.LegacyMustNewDecFromStr("0.1"))
+	require.Equal(t, math.LegacyMustNewDecFromStr("0.1"), policy.GasPrice(0))
+	policy.SetMinGasPrice(math.LegacyZeroDec())
+	require.Equal(t, math.LegacyMustNewDecFromStr("0.05"), policy.GasPrice(0))
+
+	// congestion escalates every request
+	policy.SetUnconfirmedTxs(1000)
+	require.Equal(t, math.LegacyMustNewDecFromStr("0.075"), policy.GasPrice(0))
+	require.Equal(t, math.LegacyMustNewDecFromStr("0.1125"), policy.GasPrice(1))
+	policy.SetUnconfirmedTxs(999)
+	require.Equal(t, math.LegacyMustNewDecFromStr("0.05"), policy.GasPrice(0))
+
+	// congestion is not checked without a threshold
+	config := testConfig()
+	config.CongestedMempoolTxs = 0
+	policy, err = New(config)
+	require.NoError(t, err)
+	require.False(t, policy.ChecksCongestion())
+	policy.SetUnconfirmedTxs(1_000_000)
+	require.Equal(t, math.LegacyMustNewDecFromStr("0.05"), policy.GasPrice(0))
+}
+
+func TestRetries(t *testing.T) {
+	retries := NewRetries()
+	require.Equal(t, 0, retries.Retries("1", "2"))
+
+	retries.Failed("1", "2")
+	retries.Failed("2")
+	require.Equal(t, 1, retries.Retries("1"))
+	require.Equal(t, 2, retries.Retries("1", "2"))
+
+	retries.Succeeded("2")
+	require.Equal(t, 1, retries.Retries("1", "2"))
+}
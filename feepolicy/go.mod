@@ -0,0 +1,16 @@
+module github.com/kava-labs/go-tools/feepolicy
+
+go 1.21
+
+require (
+	cosmossdk.io/math v1.3.0
+	github.com/stretchr/testify v1.8.4
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/stretchr/objx v0.5.0 // indirect
+	golang.org/x/exp v0.0.0-20221205204356-47842c84f3db // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,90 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// NodeConfigMethod returns the node's minimum gas prices, it is called without
+// generated types as the node service is newer than this module's cosmos-sdk
+const NodeConfigMethod = "/cosmos.base.node.v1beta1.Service/Config"
+
+// MinimumGasPrices fetches the minimum gas prices from the node config service
+func MinimumGasPrices(ctx context.Context, conn grpc.ClientConnInterface) (sdk.DecCoins, error) {
+	req := []byte{}
+	var res []byte
+	if err := conn.Invoke(ctx, NodeConfigMethod, &req, &res, grpc.ForceCodec(rawCodec{})); err != nil {
+		return nil, fmt.Errorf("failed to fetch node config: %w", err)
+	}
+
+	rawPrices, err := parseMinimumGasPrice(res)
+	if err != nil {
+		return nil, fmt.Errorf("invalid node config response: %w", err)
+	}
+
+	prices, err := sdk.ParseDecCoins(rawPrices)
+	if err != nil {
+		return nil, fmt.Errorf("invalid node minimum gas price %q: %w", rawPrices, err)
+	}
+
+	return prices, nil
+}
+
+// rawCodec sends and receives encoded protobuf messages as bytes
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	bz, ok := v.(*[]byte)
+	if !ok {
+		return nil, fmt.Errorf("raw codec cannot marshal %T", v)
+	}
+	return *bz, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	bz, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("raw codec cannot unmarshal into %T", v)
+	}
+	*bz = append((*bz)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
+
+// parseMinimumGasPrice decodes the minimum_gas_price field of an encoded
+// cosmos.base.node.v1beta1.ConfigResponse
+func parseMinimumGasPrice(bz []byte) (string, error) {
+	minGasPrice := ""
+	for len(bz) > 0 {
+		num, typ, n := protowire.ConsumeTag(bz)
+		if n < 0 {
+			return "", protowire.ParseError(n)
+		}
+		bz = bz[n:]
+
+		if num == 1 && typ == protowire.BytesType {
+			value, n := protowire.ConsumeString(bz)
+			if n < 0 {
+				return "", protowire.ParseError(n)
+			}
+			minGasPrice = value
+			bz = bz[n:]
+			continue
+		}
+
+		n = protowire.ConsumeFieldValue(num, typ, bz)
+		if n < 0 {
+			return "", protowire.ParseError(n)
+		}
+		bz = bz[n:]
+	}
+
+	return minGasPrice, nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// LiquidationOrder sets which borrowers are liquidated first
+type LiquidationOrder string
+
+const (
+	// ShortfallOrder liquidates the largest USD value borrowed over the
+	// borrow limit first
+	ShortfallOrder LiquidationOrder = "shortfall"
+	// RewardOrder liquidates the largest USD value keeper reward first
+	RewardOrder LiquidationOrder = "reward"
+)
+
+// BatchConfig sets the order of liquidations and how many are sent per tx
+type BatchConfig struct {
+	Order LiquidationOrder
+	// Size is the maximum number of liquidations in a tx
+	Size int
+}
+
+// Validate returns an error if the order is unknown or batches are empty
+func (c BatchConfig) Validate() error {
+	if c.Order != ShortfallOrder && c.Order != RewardOrder {
+		return fmt.Errorf("order must be %s or %s, got %s", ShortfallOrder, RewardOrder, c.Order)
+	}
+	if c.Size <= 0 {
+		return fmt.Errorf("batch size must be positive, got %d", c.Size)
+	}
+
+	return nil
+}
+
+// SortBorrowers returns the borrowers ordered largest first by USD shortfall
+// or keeper reward, keeping the order of ties and borrowers without a position
+// last
+func SortBorrowers(data *PositionData, borrowers Borrowers, order LiquidationOrder) Borrowers {
+	priorities := make(map[string]sdk.Dec, len(data.Positions))
+	for _, pos := range data.Positions {
+		switch order {
+		case RewardOrder:
+			// without a fee the estimate can not fail
+			estimate, _ := EstimateLiquidation(data.Assets, pos, nil)
+			priorities[pos.Address.String()] = estimate.RewardUSD
+		default:
+			borrowable, borrowed := GetPositionUSDValues(data.Assets, pos)
+			priorities[pos.Address.String()] = borrowed.Sub(borrowable)
+		}
+	}
+
+	sorted := make(Borrowers, len(borrowers))
+	copy(sorted, borrowers)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		left, leftFound := priorities[sorted[i].String()]
+		right, rightFound := priorities[sorted[j].String()]
+		if !leftFound || !rightFound {
+			return leftFound && !rightFound
+		}
+
+		return left.GT(right)
+	})
+
+	return sorted
+}
+
+// BatchBorrowers splits borrowers in order into batches of at most size
+func BatchBorrowers(borrowers Borrowers, size int) []Borrowers {
+	var batches []Borrowers
+
+	for start := 0; start < len(borrowers); start += size {
+		end := start + size
+		if end > len(borrowers) {
+			end = len(borrowers)
+		}
+
+		batches = append(batches, borrowers[start:end])
+	}
+
+	return batches
+}
+
+// SplitBatch halves a batch
+//
+// A failing liquidation reverts every liquidation in its tx, so a failed batch
+// is split and resent until the failing liquidation is sent on its own.
+func SplitBatch(batch Borrowers) (Borrowers, Borrowers) {
+	middle := (len(batch) + 1) / 2
+	return batch[:middle], batch[middle:]
+}
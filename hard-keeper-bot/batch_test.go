@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/tendermint/crypto"
+)
+
+func TestSortBorrowers(t *testing.T) {
+	data := &PositionData{
+		Assets: map[string]AssetInfo{
+			"busd": {
+				Price:                  sdk.MustNewDecFromStr("1.0"),
+				LoanToValueRatio:       sdk.MustNewDecFromStr("0.5"),
+				ConversionFactor:       sdk.NewInt(100000000),
+				KeeperRewardPercentage: sdk.MustNewDecFromStr("0.05"),
+			},
+		},
+		Positions: []Position{
+			{
+				// 10 busd shortfall, 1 busd reward
+				Address:         sdk.AccAddress(crypto.AddressHash([]byte("small-shortfall"))),
+				BorrowedAmount:  sdk.NewCoins(sdk.NewInt64Coin("busd", 2000000000)),
+				DepositedAmount: sdk.NewCoins(sdk.NewInt64Coin("busd", 2000000000)),
+			},
+			{
+				// 30 busd shortfall, 0.5 busd reward
+				Address:         sdk.AccAddress(crypto.AddressHash([]byte("large-shortfall"))),
+				BorrowedAmount:  sdk.NewCoins(sdk.NewInt64Coin("busd", 3500000000)),
+				DepositedAmount: sdk.NewCoins(sdk.NewInt64Coin("busd", 1000000000)),
+			},
+			{
+				// 20 busd shortfall, 5 busd reward
+				Address:         sdk.AccAddress(crypto.AddressHash([]byte("large-reward"))),
+				BorrowedAmount:  sdk.NewCoins(sdk.NewInt64Coin("busd", 7000000000)),
+				DepositedAmount: sdk.NewCoins(sdk.NewInt64Coin("busd", 10000000000)),
+			},
+		},
+	}
+	smallShortfall, largeShortfall, largeReward := data.Positions[0].Address, data.Positions[1].Address, data.Positions[2].Address
+	unknown := sdk.AccAddress(crypto.AddressHash([]byte("unknown")))
+
+	borrowers := Borrowers{unknown, smallShortfall, largeShortfall, largeReward}
+
+	assert.Equal(t,
+		Borrowers{largeShortfall, largeReward, smallShortfall, unknown},
+		SortBorrowers(data, borrowers, ShortfallOrder),
+	)
+	assert.Equal(t,
+		Borrowers{largeReward, smallShortfall, largeShortfall, unknown},
+		SortBorrowers(data, borrowers, RewardOrder),
+	)
+
+	// the borrowers are not sorted in place
+	assert.Equal(t, Borrowers{unknown, smallShortfall, largeShortfall, largeReward}, borrowers)
+}
+
+func TestBatchBorrowers(t *testing.T) {
+	var borrowers Borrowers
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		borrowers = append(borrowers, sdk.AccAddress(crypto.AddressHash([]byte(name))))
+	}
+
+	assert.Empty(t, BatchBorrowers(Borrowers{}, 2))
+	assert.Equal(t, []Borrowers{borrowers[:2], borrowers[2:4], borrowers[4:]}, BatchBorrowers(borrowers, 2))
+	assert.Equal(t, []Borrowers{borrowers}, BatchBorrowers(borrowers, 10))
+
+	left, right := SplitBatch(borrowers)
+	assert.Equal(t, borrowers[:3], left)
+	assert.Equal(t, borrowers[3:], right)
+
+	left, right = SplitBatch(borrowers[:2])
+	assert.Equal(t, borrowers[:1], left)
+	assert.Equal(t, borrowers[1:2], right)
+}
+
+func TestBatchConfigValidate(t *testing.T) {
+	assert.NoError(t, BatchConfig{Order: ShortfallOrder, Size: 1}.Validate())
+	assert.NoError(t, BatchConfig{Order: RewardOrder, Size: 10}.Validate())
+	assert.Error(t, BatchConfig{Order: "", Size: 1}.Validate())
+	assert.Error(t, BatchConfig{Order: RewardOrder, Size: 0}.Validate())
+}
@@ -11,36 +11,12 @@ func GetBorrowersToLiquidate(data *PositionData) Borrowers {
 	borrowersToLiquidate := Borrowers{}
 
 	for _, pos := range data.Positions {
-		totalBorrowableUSDAmount := sdk.ZeroDec()
-		totalBorrowedUSDAmount := sdk.ZeroDec()
-
-		for _, deposit := range pos.DepositedAmount {
-			asset, ok := assets[deposit.Denom]
-			if !ok {
-				// if no asset, no market -- don't count towards borrowable amount
-				continue
-			}
-
-			// usd value of deposit
-			USDAmount := sdk.NewDecFromInt(deposit.Amount).Quo(sdk.NewDecFromInt(asset.ConversionFactor)).Mul(asset.Price)
-			// borrowable usd value from deposit
-			borrowableUSDAmount := USDAmount.Mul(asset.LoanToValueRatio)
-			// add to total borrowable amount
-			totalBorrowableUSDAmount = totalBorrowableUSDAmount.Add(borrowableUSDAmount)
+		// positions holding an asset without a price can not be valued
+		if !data.Priced(pos) {
+			continue
 		}
 
-		for _, borrow := range pos.BorrowedAmount {
-			asset, ok := assets[borrow.Denom]
-			if !ok {
-				// if no asset, no market -- don't count towards borrowed amount
-				continue
-			}
-
-			// usd value of borrow
-			USDAmount := sdk.NewDecFromInt(borrow.Amount).Quo(sdk.NewDecFromInt(asset.ConversionFactor)).Mul(asset.Price)
-			// add to total borrowed amount
-			totalBorrowedUSDAmount = totalBorrowedUSDAmount.Add(USDAmount)
-		}
+		totalBorrowableUSDAmount, totalBorrowedUSDAmount := GetPositionUSDValues(assets, pos)
 
 		// liquidate borrower if the have borrowed are over limit
 		if totalBorrowedUSDAmount.GT(totalBorrowableUSDAmount) {
@@ -50,3 +26,40 @@ func GetBorrowersToLiquidate(data *PositionData) Borrowers {
 
 	return borrowersToLiquidate
 }
+
+// GetPositionUSDValues returns the USD value that can be borrowed against the
+// deposits of a position, and the USD value of its borrows
+func GetPositionUSDValues(assets map[string]AssetInfo, pos Position) (sdk.Dec, sdk.Dec) {
+	totalBorrowableUSDAmount := sdk.ZeroDec()
+	totalBorrowedUSDAmount := sdk.ZeroDec()
+
+	for _, deposit := range pos.DepositedAmount {
+		asset, ok := assets[deposit.Denom]
+		if !ok {
+			// if no asset, no market -- don't count towards borrowable amount
+			continue
+		}
+
+		// usd value of deposit
+		USDAmount := sdk.NewDecFromInt(deposit.Amount).Quo(sdk.NewDecFromInt(asset.ConversionFactor)).Mul(asset.Price)
+		// borrowable usd value from deposit
+		borrowableUSDAmount := USDAmount.Mul(asset.LoanToValueRatio)
+		// add to total borrowable amount
+		totalBorrowableUSDAmount = totalBorrowableUSDAmount.Add(borrowableUSDAmount)
+	}
+
+	for _, borrow := range pos.BorrowedAmount {
+		asset, ok := assets[borrow.Denom]
+		if !ok {
+			// if no asset, no market -- don't count towards borrowed amount
+			continue
+		}
+
+		// usd value of borrow
+		USDAmount := sdk.NewDecFromInt(borrow.Amount).Quo(sdk.NewDecFromInt(asset.ConversionFactor)).Mul(asset.Price)
+		// add to total borrowed amount
+		totalBorrowedUSDAmount = totalBorrowedUSDAmount.Add(USDAmount)
+	}
+
+	return totalBorrowableUSDAmount, totalBorrowedUSDAmount
+}
@@ -21,6 +21,11 @@ type InfoResponse struct {
 	LatestHeight int64
 }
 
+// LiquidationClient queries the state used to find positions to liquidate.
+//
+// Borrows and deposits are returned synced to the queried height by both the
+// grpc queries and the legacy querier, so their amounts include interest
+// accrued since the position was last updated on chain.
 type LiquidationClient interface {
 	GetInfo() (*InfoResponse, error)
 	GetPrices(height int64) (pricefeedtypes.CurrentPrices, error)
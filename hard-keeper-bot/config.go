@@ -3,17 +3,63 @@ package main
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
+	"cosmossdk.io/math"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
 const (
 	kavaRpcUrlEnvKey             = "KAVA_RPC_URL"
 	kavaGrpcUrlEnvKey            = "KAVA_GRPC_URL"
+	kavaQueryClientEnvKey        = "KAVA_QUERY_CLIENT"
 	kavaLiqudationIntervalEnvKey = "KAVA_LIQUIDATION_INTERVAL"
 	kavaKeeperAddressEnvKey      = "KAVA_KEEPER_ADDRESS"
 	kavaSignerMnemonicEnvKey     = "KAVA_SIGNER_MNEMONIC"
+	feeBaseGasPriceEnvKey        = "FEE_BASE_GAS_PRICE"
+	feeEscalationRateEnvKey      = "FEE_ESCALATION_RATE"
+	feeMaxAmountEnvKey           = "FEE_MAX_AMOUNT"
+	feeCongestedTxsEnvKey        = "FEE_CONGESTED_MEMPOOL_TXS"
+	feeNodeMinPricesEnvKey       = "FEE_NODE_MIN_GAS_PRICES"
+	cooldownMaxFailuresEnvKey    = "LIQUIDATION_MAX_FAILURES"
+	cooldownEnvKey               = "LIQUIDATION_COOLDOWN"
+	cooldownMaxEnvKey            = "LIQUIDATION_MAX_COOLDOWN"
+	profitMinUsdEnvKey           = "PROFIT_MIN_USD"
+	protocolSafetyEnvKey         = "PROTOCOL_SAFETY_MODE"
+	riskPriceShocksEnvKey        = "RISK_PRICE_SHOCKS"
+	riskOutputDirEnvKey          = "RISK_OUTPUT_DIR"
+	liquidationOrderEnvKey       = "LIQUIDATION_ORDER"
+	liquidationBatchSizeEnvKey   = "LIQUIDATION_BATCH_SIZE"
+	healthCheckListenAddrEnvKey  = "HEALTH_CHECK_LISTEN_ADDR"
+	healthMaxScanAgeEnvKey       = "HEALTH_MAX_SCAN_AGE"
+)
+
+const (
+	defaultFeeDenom          = "ukava"
+	defaultFeeBaseGasPrice   = "0.05"
+	defaultFeeEscalationRate = "0.25"
+	defaultFeeMaxAmount      = "200000"
+	defaultMaxFailures       = 2
+	defaultCooldown          = 30 * time.Minute
+	defaultMaxCooldown       = 6 * time.Hour
+	defaultMinProfit         = "0"
+	defaultRiskOutputDir     = "."
+	defaultBatchSize         = 5
+	defaultHealthListenAddr  = ":8080"
+	// the current prices, then drops of all but stable assets
+	defaultRiskPriceShocks = "0;-0.1,busd=0,usdx=0;-0.25,busd=0,usdx=0;-0.5,busd=0,usdx=0"
+)
+
+// QueryClient selects the LiquidationClient used to query positions
+type QueryClient string
+
+const (
+	// GrpcQueryClient queries the hard and pricefeed grpc services
+	GrpcQueryClient QueryClient = "grpc"
+	// RpcQueryClient queries the legacy abci querier routes, which are not
+	// available on newer chain versions
+	RpcQueryClient QueryClient = "rpc"
 )
 
 // ConfigLoader provides an interface for
@@ -26,24 +72,36 @@ type ConfigLoader interface {
 type Config struct {
 	KavaRpcUrl              string
 	KavaGrpcUrl             string
+	KavaQueryClient         QueryClient
 	KavaLiquidationInterval time.Duration
 	KavaKeeperAddress       sdk.AccAddress
 	KavaSignerMnemonic      string
+	Fee                     FeePolicyConfig
+	// FeeNodeMinGasPrices is set when the node's minimum gas prices are used
+	// if higher than the base gas price
+	FeeNodeMinGasPrices   bool
+	Cooldown              CooldownConfig
+	Profit                ProfitConfig
+	Batch                 BatchConfig
+	HealthCheckListenAddr string
+	// HealthMaxScanAge is the age of the latest scan that fails the health
+	// check, three liquidation intervals unless set
+	HealthMaxScanAge time.Duration
 }
 
 // LoadConfig loads key values from a ConfigLoader
 // and returns a new Config
 func LoadConfig(loader ConfigLoader) (Config, error) {
-	rpcUrl := loader.Get(kavaRpcUrlEnvKey)
-	if rpcUrl == "" {
-		return Config{}, fmt.Errorf("%s not set", kavaRpcUrlEnvKey)
-	}
-
 	grpcUrl := loader.Get(kavaGrpcUrlEnvKey)
 	if grpcUrl == "" {
 		return Config{}, fmt.Errorf("%s not set", kavaGrpcUrlEnvKey)
 	}
 
+	queryClient, err := loadQueryClient(loader)
+	if err != nil {
+		return Config{}, err
+	}
+
 	liquidationInterval, err := time.ParseDuration(loader.Get(kavaLiqudationIntervalEnvKey))
 	if err != nil {
 		liquidationInterval = time.Duration(10 * time.Minute)
@@ -64,15 +122,282 @@ func LoadConfig(loader ConfigLoader) (Config, error) {
 		return Config{}, fmt.Errorf("%s not set", kavaSignerMnemonicEnvKey)
 	}
 
+	fee, err := loadFeePolicyConfig(loader)
+	if err != nil {
+		return Config{}, err
+	}
+
+	feeNodeMinGasPrices := false
+	if raw := loader.Get(feeNodeMinPricesEnvKey); raw != "" {
+		feeNodeMinGasPrices, err = strconv.ParseBool(raw)
+		if err != nil {
+			return Config{}, fmt.Errorf("%s invalid bool: %v", feeNodeMinPricesEnvKey, err)
+		}
+	}
+
+	// the rpc is only used by the rpc query client, to check mempool
+	// congestion and to watch price updates
+	rpcUrl := loader.Get(kavaRpcUrlEnvKey)
+	if rpcUrl == "" && queryClient == RpcQueryClient {
+		return Config{}, fmt.Errorf("%s not set, required by the %s query client", kavaRpcUrlEnvKey, RpcQueryClient)
+	}
+	if rpcUrl == "" && fee.CongestedMempoolTxs > 0 {
+		return Config{}, fmt.Errorf("%s not set, required by %s", kavaRpcUrlEnvKey, feeCongestedTxsEnvKey)
+	}
+
+	cooldown, err := loadCooldownConfig(loader)
+	if err != nil {
+		return Config{}, err
+	}
+
+	profit, err := loadProfitConfig(loader)
+	if err != nil {
+		return Config{}, err
+	}
+
+	batch, err := loadBatchConfig(loader)
+	if err != nil {
+		return Config{}, err
+	}
+
+	healthCheckListenAddr := loader.Get(healthCheckListenAddrEnvKey)
+	if healthCheckListenAddr == "" {
+		healthCheckListenAddr = defaultHealthListenAddr
+	}
+
+	healthMaxScanAge := 3 * liquidationInterval
+	if raw := loader.Get(healthMaxScanAgeEnvKey); raw != "" {
+		healthMaxScanAge, err = time.ParseDuration(raw)
+		if err != nil {
+			return Config{}, fmt.Errorf("%s invalid: %v", healthMaxScanAgeEnvKey, err)
+		}
+	}
+
 	return Config{
 		KavaRpcUrl:              rpcUrl,
 		KavaGrpcUrl:             grpcUrl,
+		KavaQueryClient:         queryClient,
 		KavaLiquidationInterval: liquidationInterval,
 		KavaKeeperAddress:       keeperAddress,
 		KavaSignerMnemonic:      signerMnemonic,
+		Fee:                     fee,
+		FeeNodeMinGasPrices:     feeNodeMinGasPrices,
+		Cooldown:                cooldown,
+		Profit:                  profit,
+		Batch:                   batch,
+		HealthCheckListenAddr:   healthCheckListenAddr,
+		HealthMaxScanAge:        healthMaxScanAge,
 	}, nil
 }
 
+// loadFeePolicyConfig loads the gas price, escalation and fee cap used to
+// price liquidation txs, using defaults for any that are not set
+func loadFeePolicyConfig(loader ConfigLoader) (FeePolicyConfig, error) {
+	getOrDefault := func(key, fallback string) string {
+		if value := loader.Get(key); value != "" {
+			return value
+		}
+		return fallback
+	}
+
+	baseGasPrice, err := math.LegacyNewDecFromStr(getOrDefault(feeBaseGasPriceEnvKey, defaultFeeBaseGasPrice))
+	if err != nil {
+		return FeePolicyConfig{}, fmt.Errorf("%s invalid decimal: %v", feeBaseGasPriceEnvKey, err)
+	}
+
+	escalationRate, err := math.LegacyNewDecFromStr(getOrDefault(feeEscalationRateEnvKey, defaultFeeEscalationRate))
+	if err != nil {
+		return FeePolicyConfig{}, fmt.Errorf("%s invalid decimal: %v", feeEscalationRateEnvKey, err)
+	}
+
+	maxFee, ok := math.NewIntFromString(getOrDefault(feeMaxAmountEnvKey, defaultFeeMaxAmount))
+	if !ok {
+		return FeePolicyConfig{}, fmt.Errorf("%s invalid integer", feeMaxAmountEnvKey)
+	}
+
+	// congestion is not checked unless a threshold is set
+	congestedMempoolTxs := 0
+	if raw := loader.Get(feeCongestedTxsEnvKey); raw != "" {
+		congestedMempoolTxs, err = strconv.Atoi(raw)
+		if err != nil {
+			return FeePolicyConfig{}, fmt.Errorf("%s invalid: %v", feeCongestedTxsEnvKey, err)
+		}
+	}
+
+	config := FeePolicyConfig{
+		Denom:               defaultFeeDenom,
+		BaseGasPrice:        baseGasPrice,
+		EscalationRate:      escalationRate,
+		MaxFee:              maxFee,
+		CongestedMempoolTxs: congestedMempoolTxs,
+	}
+	if err := config.Validate(); err != nil {
+		return FeePolicyConfig{}, fmt.Errorf("invalid fee policy: %w", err)
+	}
+
+	return config, nil
+}
+
+// RiskConfig provides configuration of the at risk position report
+type RiskConfig struct {
+	KavaRpcUrl      string
+	KavaGrpcUrl     string
+	KavaQueryClient QueryClient
+	PriceShocks     []PriceShock
+	OutputDir       string
+}
+
+// LoadRiskConfig loads key values from a ConfigLoader and returns a new
+// RiskConfig, the report does not sign so needs no keeper or mnemonic
+func LoadRiskConfig(loader ConfigLoader) (RiskConfig, error) {
+	grpcUrl := loader.Get(kavaGrpcUrlEnvKey)
+	if grpcUrl == "" {
+		return RiskConfig{}, fmt.Errorf("%s not set", kavaGrpcUrlEnvKey)
+	}
+
+	queryClient, err := loadQueryClient(loader)
+	if err != nil {
+		return RiskConfig{}, err
+	}
+
+	rpcUrl := loader.Get(kavaRpcUrlEnvKey)
+	if rpcUrl == "" && queryClient == RpcQueryClient {
+		return RiskConfig{}, fmt.Errorf("%s not set, required by the %s query client", kavaRpcUrlEnvKey, RpcQueryClient)
+	}
+
+	rawShocks := loader.Get(riskPriceShocksEnvKey)
+	if rawShocks == "" {
+		rawShocks = defaultRiskPriceShocks
+	}
+	shocks, err := ParsePriceShocks(rawShocks)
+	if err != nil {
+		return RiskConfig{}, fmt.Errorf("%s invalid: %w", riskPriceShocksEnvKey, err)
+	}
+
+	outputDir := loader.Get(riskOutputDirEnvKey)
+	if outputDir == "" {
+		outputDir = defaultRiskOutputDir
+	}
+
+	return RiskConfig{
+		KavaRpcUrl:      rpcUrl,
+		KavaGrpcUrl:     grpcUrl,
+		KavaQueryClient: queryClient,
+		PriceShocks:     shocks,
+		OutputDir:       outputDir,
+	}, nil
+}
+
+// loadQueryClient loads the query client, grpc unless set
+func loadQueryClient(loader ConfigLoader) (QueryClient, error) {
+	queryClient := QueryClient(loader.Get(kavaQueryClientEnvKey))
+	switch queryClient {
+	case "":
+		return GrpcQueryClient, nil
+	case GrpcQueryClient, RpcQueryClient:
+		return queryClient, nil
+	default:
+		return "", fmt.Errorf("%s must be %s or %s, got %s", kavaQueryClientEnvKey, GrpcQueryClient, RpcQueryClient, queryClient)
+	}
+}
+
+// loadCooldownConfig loads when borrowers with failing liquidations are put on
+// cooldown, using defaults for any that are not set
+func loadCooldownConfig(loader ConfigLoader) (CooldownConfig, error) {
+	config := CooldownConfig{
+		MaxFailures: defaultMaxFailures,
+		Cooldown:    defaultCooldown,
+		MaxCooldown: defaultMaxCooldown,
+	}
+
+	var err error
+	if raw := loader.Get(cooldownMaxFailuresEnvKey); raw != "" {
+		config.MaxFailures, err = strconv.Atoi(raw)
+		if err != nil {
+			return CooldownConfig{}, fmt.Errorf("%s invalid: %v", cooldownMaxFailuresEnvKey, err)
+		}
+	}
+
+	if raw := loader.Get(cooldownEnvKey); raw != "" {
+		config.Cooldown, err = time.ParseDuration(raw)
+		if err != nil {
+			return CooldownConfig{}, fmt.Errorf("%s invalid: %v", cooldownEnvKey, err)
+		}
+	}
+
+	if raw := loader.Get(cooldownMaxEnvKey); raw != "" {
+		config.MaxCooldown, err = time.ParseDuration(raw)
+		if err != nil {
+			return CooldownConfig{}, fmt.Errorf("%s invalid: %v", cooldownMaxEnvKey, err)
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return CooldownConfig{}, fmt.Errorf("invalid liquidation cooldown: %w", err)
+	}
+
+	return config, nil
+}
+
+// loadProfitConfig loads the minimum profit of liquidations and whether
+// protocol safety mode liquidates regardless of profit
+func loadProfitConfig(loader ConfigLoader) (ProfitConfig, error) {
+	rawMinProfit := loader.Get(profitMinUsdEnvKey)
+	if rawMinProfit == "" {
+		rawMinProfit = defaultMinProfit
+	}
+
+	minProfit, err := sdk.NewDecFromStr(rawMinProfit)
+	if err != nil {
+		return ProfitConfig{}, fmt.Errorf("%s invalid decimal: %v", profitMinUsdEnvKey, err)
+	}
+
+	protocolSafety := false
+	if raw := loader.Get(protocolSafetyEnvKey); raw != "" {
+		protocolSafety, err = strconv.ParseBool(raw)
+		if err != nil {
+			return ProfitConfig{}, fmt.Errorf("%s invalid: %v", protocolSafetyEnvKey, err)
+		}
+	}
+
+	config := ProfitConfig{
+		MinProfit:      minProfit,
+		ProtocolSafety: protocolSafety,
+	}
+	if err := config.Validate(); err != nil {
+		return ProfitConfig{}, fmt.Errorf("invalid profit config: %w", err)
+	}
+
+	return config, nil
+}
+
+// loadBatchConfig loads the order of liquidations and how many are sent per
+// tx, largest shortfall first in batches of defaultBatchSize unless set
+func loadBatchConfig(loader ConfigLoader) (BatchConfig, error) {
+	config := BatchConfig{
+		Order: ShortfallOrder,
+		Size:  defaultBatchSize,
+	}
+
+	if raw := loader.Get(liquidationOrderEnvKey); raw != "" {
+		config.Order = LiquidationOrder(raw)
+	}
+
+	if raw := loader.Get(liquidationBatchSizeEnvKey); raw != "" {
+		var err error
+		config.Size, err = strconv.Atoi(raw)
+		if err != nil {
+			return BatchConfig{}, fmt.Errorf("%s invalid: %v", liquidationBatchSizeEnvKey, err)
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return BatchConfig{}, fmt.Errorf("invalid liquidation batch: %w", err)
+	}
+
+	return config, nil
+}
+
 // EnvLoader loads keys from os environment
 type EnvLoader struct {
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"cosmossdk.io/math"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/tendermint/tendermint/crypto"
@@ -50,6 +51,10 @@ func TestConfigLoading(t *testing.T) {
 		t.Fatalf("bad value %s for KavaGrpcUrl", defaultConfig.KavaGrpcUrl)
 	}
 
+	if defaultConfig.KavaQueryClient != GrpcQueryClient {
+		t.Fatalf("default query client is not grpc")
+	}
+
 	if defaultConfig.KavaLiquidationInterval != time.Duration(10*time.Minute) {
 		t.Fatalf("default liquidation interval is not 10m")
 	}
@@ -62,6 +67,38 @@ func TestConfigLoading(t *testing.T) {
 		t.Fatalf("bad value %s for KavaSignerMnemonic", defaultConfig.KavaSignerMnemonic)
 	}
 
+	if !defaultConfig.Fee.BaseGasPrice.Equal(math.LegacyMustNewDecFromStr("0.05")) {
+		t.Fatalf("default base gas price is not 0.05")
+	}
+
+	if defaultConfig.Fee.CongestedMempoolTxs != 0 {
+		t.Fatalf("congestion checks are enabled by default")
+	}
+
+	if defaultConfig.FeeNodeMinGasPrices {
+		t.Fatalf("node minimum gas prices are used by default")
+	}
+
+	if defaultConfig.Cooldown != (CooldownConfig{MaxFailures: 2, Cooldown: 30 * time.Minute, MaxCooldown: 6 * time.Hour}) {
+		t.Fatalf("bad default cooldown %+v", defaultConfig.Cooldown)
+	}
+
+	if !defaultConfig.Profit.MinProfit.IsZero() || defaultConfig.Profit.ProtocolSafety {
+		t.Fatalf("liquidations must cover their fee by default")
+	}
+
+	if defaultConfig.Batch != (BatchConfig{Order: ShortfallOrder, Size: 5}) {
+		t.Fatalf("bad default batch %+v", defaultConfig.Batch)
+	}
+
+	if defaultConfig.HealthCheckListenAddr != ":8080" {
+		t.Fatalf("bad default health check listen addr %s", defaultConfig.HealthCheckListenAddr)
+	}
+
+	if defaultConfig.HealthMaxScanAge != 30*time.Minute {
+		t.Fatalf("default max scan age is not three liquidation intervals")
+	}
+
 	loader = &testEnvLoader{
 		t: t,
 		Env: map[string]string{
@@ -69,6 +106,12 @@ func TestConfigLoading(t *testing.T) {
 			"KAVA_GRPC_URL":             "https://grpc.testnet.kava.io:443",
 			"KAVA_KEEPER_ADDRESS":       sdk.AccAddress(crypto.AddressHash([]byte("keeper"))).String(),
 			"KAVA_LIQUIDATION_INTERVAL": "30m",
+			"PROFIT_MIN_USD":            "2.5",
+			"PROTOCOL_SAFETY_MODE":      "true",
+			"LIQUIDATION_ORDER":         "reward",
+			"LIQUIDATION_BATCH_SIZE":    "20",
+			"HEALTH_CHECK_LISTEN_ADDR":  ":9090",
+			"HEALTH_MAX_SCAN_AGE":       "45m",
 			"KAVA_SIGNER_MNEMONIC":      "arrive guide way exit polar print kitchen hair series custom siege afraid shrug crew fashion mind script divorce pattern trust project regular robust safe",
 		},
 	}
@@ -81,6 +124,122 @@ func TestConfigLoading(t *testing.T) {
 	if config.KavaLiquidationInterval != time.Duration(30*time.Minute) {
 		t.Fatalf("default liquidation interval is not 30m")
 	}
+
+	if !config.Profit.MinProfit.Equal(sdk.MustNewDecFromStr("2.5")) {
+		t.Fatalf("bad value %s for min profit", config.Profit.MinProfit)
+	}
+
+	if !config.Profit.ProtocolSafety {
+		t.Fatalf("protocol safety mode is not enabled")
+	}
+
+	if config.Batch != (BatchConfig{Order: RewardOrder, Size: 20}) {
+		t.Fatalf("bad value %+v for batch", config.Batch)
+	}
+
+	if config.HealthCheckListenAddr != ":9090" || config.HealthMaxScanAge != 45*time.Minute {
+		t.Fatalf("bad values %s and %s for health checks", config.HealthCheckListenAddr, config.HealthMaxScanAge)
+	}
+}
+
+func TestQueryClientConfig(t *testing.T) {
+	env := map[string]string{
+		"KAVA_GRPC_URL":        "https://grpc.testnet.kava.io:443",
+		"KAVA_KEEPER_ADDRESS":  sdk.AccAddress(crypto.AddressHash([]byte("keeper"))).String(),
+		"KAVA_SIGNER_MNEMONIC": "arrive guide way exit polar print kitchen hair series custom siege afraid shrug crew fashion mind script divorce pattern trust project regular robust safe",
+	}
+
+	// the rpc is not required by the grpc query client
+	config, err := LoadConfig(&testEnvLoader{t: t, Env: env})
+	assert.Nil(t, err)
+	assert.Equal(t, GrpcQueryClient, config.KavaQueryClient)
+	assert.Equal(t, "", config.KavaRpcUrl)
+
+	env["KAVA_QUERY_CLIENT"] = "rpc"
+	_, err = LoadConfig(&testEnvLoader{t: t, Env: env})
+	assert.NotNil(t, err)
+	assert.Regexp(t, "KAVA_RPC_URL not set, required by the rpc query client", err.Error())
+
+	env["KAVA_RPC_URL"] = "https://rpc.testnet.kava.io:443"
+	config, err = LoadConfig(&testEnvLoader{t: t, Env: env})
+	assert.Nil(t, err)
+	assert.Equal(t, RpcQueryClient, config.KavaQueryClient)
+
+	env["KAVA_QUERY_CLIENT"] = "rest"
+	_, err = LoadConfig(&testEnvLoader{t: t, Env: env})
+	assert.NotNil(t, err)
+	assert.Regexp(t, "KAVA_QUERY_CLIENT must be grpc or rpc, got rest", err.Error())
+
+	// congestion checks query the rpc mempool
+	delete(env, "KAVA_QUERY_CLIENT")
+	delete(env, "KAVA_RPC_URL")
+	env["FEE_CONGESTED_MEMPOOL_TXS"] = "1000"
+	_, err = LoadConfig(&testEnvLoader{t: t, Env: env})
+	assert.NotNil(t, err)
+	assert.Regexp(t, "KAVA_RPC_URL not set, required by FEE_CONGESTED_MEMPOOL_TXS", err.Error())
+}
+
+func TestInvalidFeePolicy(t *testing.T) {
+	loader := &testEnvLoader{
+		t: t,
+		Env: map[string]string{
+			"KAVA_RPC_URL":         "https://rpc.testnet.kava.io:443",
+			"KAVA_GRPC_URL":        "https://grpc.testnet.kava.io:443",
+			"KAVA_KEEPER_ADDRESS":  sdk.AccAddress(crypto.AddressHash([]byte("keeper"))).String(),
+			"KAVA_SIGNER_MNEMONIC": "arrive guide way exit polar print kitchen hair series custom siege afraid shrug crew fashion mind script divorce pattern trust project regular robust safe",
+			"FEE_MAX_AMOUNT":       "0",
+		},
+	}
+
+	_, err := LoadConfig(loader)
+	assert.NotNil(t, err)
+	assert.Regexp(t, "max fee must be positive", err.Error())
+
+	loader.Env["FEE_MAX_AMOUNT"] = "200000"
+	loader.Env["FEE_NODE_MIN_GAS_PRICES"] = "yes"
+	_, err = LoadConfig(loader)
+	assert.NotNil(t, err)
+	assert.Regexp(t, "FEE_NODE_MIN_GAS_PRICES invalid bool", err.Error())
+}
+
+func TestInvalidCooldown(t *testing.T) {
+	loader := &testEnvLoader{
+		t: t,
+		Env: map[string]string{
+			"KAVA_RPC_URL":         "https://rpc.testnet.kava.io:443",
+			"KAVA_GRPC_URL":        "https://grpc.testnet.kava.io:443",
+			"KAVA_KEEPER_ADDRESS":  sdk.AccAddress(crypto.AddressHash([]byte("keeper"))).String(),
+			"KAVA_SIGNER_MNEMONIC": "arrive guide way exit polar print kitchen hair series custom siege afraid shrug crew fashion mind script divorce pattern trust project regular robust safe",
+			"LIQUIDATION_COOLDOWN": "8h",
+		},
+	}
+
+	_, err := LoadConfig(loader)
+	assert.NotNil(t, err)
+	assert.Regexp(t, "max cooldown 6h0m0s must be at least cooldown 8h0m0s", err.Error())
+}
+
+func TestInvalidBatch(t *testing.T) {
+	loader := &testEnvLoader{
+		t: t,
+		Env: map[string]string{
+			"KAVA_RPC_URL":         "https://rpc.testnet.kava.io:443",
+			"KAVA_GRPC_URL":        "https://grpc.testnet.kava.io:443",
+			"KAVA_KEEPER_ADDRESS":  sdk.AccAddress(crypto.AddressHash([]byte("keeper"))).String(),
+			"KAVA_SIGNER_MNEMONIC": "arrive guide way exit polar print kitchen hair series custom siege afraid shrug crew fashion mind script divorce pattern trust project regular robust safe",
+			"LIQUIDATION_ORDER":    "oldest",
+		},
+	}
+
+	_, err := LoadConfig(loader)
+	assert.NotNil(t, err)
+	assert.Regexp(t, "order must be shortfall or reward, got oldest", err.Error())
+
+	loader.Env["LIQUIDATION_ORDER"] = "shortfall"
+	loader.Env["LIQUIDATION_BATCH_SIZE"] = "0"
+	_, err = LoadConfig(loader)
+	assert.NotNil(t, err)
+	assert.Regexp(t, "batch size must be positive, got 0", err.Error())
 }
 
 func TestInvalidKeeperAddress(t *testing.T) {
@@ -99,6 +258,35 @@ func TestInvalidKeeperAddress(t *testing.T) {
 	assert.Regexp(t, "decoding bech32 failed", err.Error())
 }
 
+func TestRiskConfigLoading(t *testing.T) {
+	// the risk report does not sign, so no keeper or mnemonic is required
+	loader := &testEnvLoader{
+		t: t,
+		Env: map[string]string{
+			"KAVA_GRPC_URL": "https://grpc.testnet.kava.io:443",
+		},
+	}
+
+	config, err := LoadRiskConfig(loader)
+	assert.Nil(t, err)
+	assert.Equal(t, GrpcQueryClient, config.KavaQueryClient)
+	assert.Equal(t, ".", config.OutputDir)
+	assert.Len(t, config.PriceShocks, 4)
+
+	loader.Env["RISK_PRICE_SHOCKS"] = "-0.3,usdx=0"
+	loader.Env["RISK_OUTPUT_DIR"] = "/tmp/reports"
+	config, err = LoadRiskConfig(loader)
+	assert.Nil(t, err)
+	assert.Equal(t, "/tmp/reports", config.OutputDir)
+	assert.Len(t, config.PriceShocks, 1)
+	assert.Equal(t, sdk.MustNewDecFromStr("-0.3"), config.PriceShocks[0].Shock("bnb"))
+
+	loader.Env["RISK_PRICE_SHOCKS"] = "-1.5"
+	_, err = LoadRiskConfig(loader)
+	assert.NotNil(t, err)
+	assert.Regexp(t, "RISK_PRICE_SHOCKS invalid", err.Error())
+}
+
 func TestEnvLoader(t *testing.T) {
 	testKey := "KAVA_CONFIG_VAR_TEST_1"
 	testValue := "KAVA_CONFIG_VAR_TEST_1 value test"
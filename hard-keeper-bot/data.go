@@ -1,9 +1,12 @@
 package main
 
 import (
-	"fmt"
+	"sort"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	hardtypes "github.com/kava-labs/kava/x/hard/types"
+	pricefeedtypes "github.com/kava-labs/kava/x/pricefeed/types"
+	"github.com/rs/zerolog"
 )
 
 type Position struct {
@@ -13,17 +16,46 @@ type Position struct {
 }
 
 type AssetInfo struct {
-	Price            sdk.Dec
-	LoanToValueRatio sdk.Dec
-	ConversionFactor sdk.Int
+	Price                  sdk.Dec
+	LoanToValueRatio       sdk.Dec
+	ConversionFactor       sdk.Int
+	KeeperRewardPercentage sdk.Dec
 }
 
 type PositionData struct {
+	Height    int64
 	Assets    map[string]AssetInfo
 	Positions []Position
+	// Unpriced are the denoms of markets without a price, positions holding
+	// them can not be valued
+	Unpriced []string
 }
 
-func GetPositionData(client LiquidationClient) (*PositionData, error) {
+// Priced returns false if the health of the position depends on an asset
+// without a price
+func (d *PositionData) Priced(pos Position) bool {
+	for _, denom := range d.Unpriced {
+		if pos.DepositedAmount.AmountOf(denom).IsPositive() || pos.BorrowedAmount.AmountOf(denom).IsPositive() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// UnpricedPositions returns the number of positions that can not be valued
+func (d *PositionData) UnpricedPositions() int {
+	count := 0
+	for _, pos := range d.Positions {
+		if !d.Priced(pos) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func GetPositionData(logger zerolog.Logger, client LiquidationClient) (*PositionData, error) {
 	// fetch chain info to get height
 	info, err := client.GetInfo()
 	if err != nil {
@@ -33,52 +65,36 @@ func GetPositionData(client LiquidationClient) (*PositionData, error) {
 	// use height to get consistent state from rpc client
 	height := info.LatestHeight
 
-	fmt.Printf("getting market data at %d\n", height)
+	logger.Debug().Int64("height", height).Msg("getting market data")
 	markets, err := client.GetMarkets(height)
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Printf("getting price data at %d\n", height)
+	logger.Debug().Int64("height", height).Msg("getting price data")
 	prices, err := client.GetPrices(height)
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Printf("getting borrow data at %d\n", height)
+	logger.Debug().Int64("height", height).Msg("getting borrow data")
 	borrows, err := client.GetBorrows(height)
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Printf("getting deposit data at %d\n", height)
+	logger.Debug().Int64("height", height).Msg("getting deposit data")
 	deposits, err := client.GetDeposits(height)
 	if err != nil {
 		return nil, err
 	}
 
-	// map price data
-	priceData := make(map[string]sdk.Dec)
-	for _, price := range prices {
-		priceData[price.MarketID] = price.Price
-	}
+	// a market without a price does not stop the positions that do not
+	// depend on it from being checked
+	assetInfo, unpriced := getAssetInfo(markets, prices)
 
-	// loop markets and create AssetInfo
-	assetInfo := make(map[string]AssetInfo)
-	for _, market := range markets {
-		price, ok := priceData[market.SpotMarketID]
-		if !ok {
-			return nil, fmt.Errorf("no price for market id %s", market.SpotMarketID)
-		}
-
-		assetInfo[market.Denom] = AssetInfo{
-			Price:            price,
-			LoanToValueRatio: market.BorrowLimit.LoanToValue,
-			ConversionFactor: market.ConversionFactor,
-		}
-	}
-
-	// loop deposits and map into lookup table by address
+	// loop deposits and map into lookup table by address, the queries return
+	// balances synced to the height so they include accrued interest
 	depositData := make(map[string]sdk.Coins)
 	for _, deposit := range deposits {
 		depositData[deposit.Depositor.String()] = deposit.Amount
@@ -103,10 +119,86 @@ func GetPositionData(client LiquidationClient) (*PositionData, error) {
 		}
 	}
 
-	fmt.Printf("%d positions fetched\n", len(positions))
+	data := &PositionData{
+		Height:    height,
+		Assets:    assetInfo,
+		Positions: positions,
+		Unpriced:  unpriced,
+	}
+
+	logger.Debug().
+		Int64("height", height).
+		Int("positions", len(positions)).
+		Strs("unpriced", unpriced).
+		Int("skipped_positions", data.UnpricedPositions()).
+		Msg("positions fetched")
+
+	return data, nil
+}
+
+// RefreshPrices returns the positions of data with the markets and prices at
+// the latest height
+//
+// Positions are not fetched again, so balances are as of the height of data
+// without the interest accrued since.
+func RefreshPrices(logger zerolog.Logger, client LiquidationClient, data *PositionData) (*PositionData, error) {
+	info, err := client.GetInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	height := info.LatestHeight
+
+	logger.Debug().Int64("height", height).Msg("getting market data")
+	markets, err := client.GetMarkets(height)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debug().Int64("height", height).Msg("getting price data")
+	prices, err := client.GetPrices(height)
+	if err != nil {
+		return nil, err
+	}
+
+	assetInfo, unpriced := getAssetInfo(markets, prices)
 
 	return &PositionData{
+		Height:    height,
 		Assets:    assetInfo,
-		Positions: positions,
+		Positions: data.Positions,
+		Unpriced:  unpriced,
 	}, nil
 }
+
+// getAssetInfo returns the AssetInfo of each market by denom, and the sorted
+// denoms of markets without a positive price, which are left out
+func getAssetInfo(markets hardtypes.MoneyMarkets, prices pricefeedtypes.CurrentPrices) (map[string]AssetInfo, []string) {
+	// map price data
+	priceData := make(map[string]sdk.Dec)
+	for _, price := range prices {
+		priceData[price.MarketID] = price.Price
+	}
+
+	// loop markets and create AssetInfo
+	assetInfo := make(map[string]AssetInfo)
+	var unpriced []string
+	for _, market := range markets {
+		// the pricefeed zeroes the price of markets without valid prices
+		price, ok := priceData[market.SpotMarketID]
+		if !ok || price.IsNil() || !price.IsPositive() {
+			unpriced = append(unpriced, market.Denom)
+			continue
+		}
+
+		assetInfo[market.Denom] = AssetInfo{
+			Price:                  price,
+			LoanToValueRatio:       market.BorrowLimit.LoanToValue,
+			ConversionFactor:       market.ConversionFactor,
+			KeeperRewardPercentage: market.KeeperRewardPercentage,
+		}
+	}
+	sort.Strings(unpriced)
+
+	return assetInfo, unpriced
+}
@@ -7,6 +7,7 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	hardtypes "github.com/kava-labs/kava/x/hard/types"
 	pricefeedtypes "github.com/kava-labs/kava/x/pricefeed/types"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/tendermint/tendermint/crypto"
 )
@@ -108,7 +109,7 @@ func TestGetPositionDataClientErrors(t *testing.T) {
 	}
 
 	for _, tc := range tests {
-		_, err := GetPositionData(tc.client)
+		_, err := GetPositionData(zerolog.Nop(), tc.client)
 		assert.NotNil(t, err)
 
 		c := tc.client
@@ -196,7 +197,13 @@ func TestGetLiquidationMissingData(t *testing.T) {
 					},
 				},
 			}, // market but no prices
-			expectedErr: errors.New("no price for market id busd:usd"),
+			expectedData: PositionData{
+				Assets:    make(map[string]AssetInfo),
+				Positions: []Position{},
+				// the market is marked unpriced instead of failing
+				Unpriced: []string{"busd"},
+			},
+			expectedErr: nil,
 		},
 		{
 			client: MockClient{
@@ -258,9 +265,11 @@ func TestGetLiquidationMissingData(t *testing.T) {
 	}
 
 	for _, tc := range tests {
-		data, err := GetPositionData(tc.client)
+		data, err := GetPositionData(zerolog.Nop(), tc.client)
 		assert.Equal(t, tc.expectedErr, err)
 		if err == nil {
+			// data is fetched at the latest height
+			tc.expectedData.Height = tc.client.ExpectedHeight
 			assert.Equal(t, &tc.expectedData, data)
 		} else {
 			// error means nil return, no data
@@ -328,9 +337,10 @@ func TestGetLiquidationAllData(t *testing.T) {
 			expectedData: PositionData{
 				Assets: map[string]AssetInfo{
 					"busd": {
-						Price:            sdk.MustNewDecFromStr("1.0"),
-						LoanToValueRatio: sdk.MustNewDecFromStr("0.5"),
-						ConversionFactor: sdk.NewInt(10000000),
+						Price:                  sdk.MustNewDecFromStr("1.0"),
+						LoanToValueRatio:       sdk.MustNewDecFromStr("0.5"),
+						ConversionFactor:       sdk.NewInt(10000000),
+						KeeperRewardPercentage: sdk.MustNewDecFromStr("0.02"),
 					},
 				},
 				Positions: []Position{
@@ -440,14 +450,16 @@ func TestGetLiquidationAllData(t *testing.T) {
 			expectedData: PositionData{
 				Assets: map[string]AssetInfo{
 					"busd": {
-						Price:            sdk.MustNewDecFromStr("1.0"),
-						LoanToValueRatio: sdk.MustNewDecFromStr("0.5"),
-						ConversionFactor: sdk.NewInt(10000000),
+						Price:                  sdk.MustNewDecFromStr("1.0"),
+						LoanToValueRatio:       sdk.MustNewDecFromStr("0.5"),
+						ConversionFactor:       sdk.NewInt(10000000),
+						KeeperRewardPercentage: sdk.MustNewDecFromStr("0.02"),
 					},
 					"btcb": {
-						Price:            sdk.MustNewDecFromStr("49000.125634"),
-						LoanToValueRatio: sdk.MustNewDecFromStr("0.4"),
-						ConversionFactor: sdk.NewInt(10000000),
+						Price:                  sdk.MustNewDecFromStr("49000.125634"),
+						LoanToValueRatio:       sdk.MustNewDecFromStr("0.4"),
+						ConversionFactor:       sdk.NewInt(10000000),
+						KeeperRewardPercentage: sdk.MustNewDecFromStr("0.02"),
 					},
 				},
 				Positions: []Position{
@@ -472,8 +484,169 @@ func TestGetLiquidationAllData(t *testing.T) {
 	}
 
 	for _, tc := range tests {
-		data, err := GetPositionData(tc.client)
+		data, err := GetPositionData(zerolog.Nop(), tc.client)
 		assert.Nil(t, err)
+		tc.expectedData.Height = tc.client.ExpectedHeight
 		assert.Equal(t, &tc.expectedData, data)
 	}
 }
+
+func TestRefreshPrices(t *testing.T) {
+	borrower := sdk.AccAddress(crypto.AddressHash([]byte("borrower")))
+
+	markets := hardtypes.MoneyMarkets{
+		{
+			Denom:        "bnb",
+			SpotMarketID: "bnb:usd",
+			BorrowLimit: hardtypes.BorrowLimit{
+				HasMaxLimit:  false,
+				MaximumLimit: sdk.MustNewDecFromStr("0.0"),
+				LoanToValue:  sdk.MustNewDecFromStr("0.5"),
+			},
+			ConversionFactor:       sdk.NewInt(100000000),
+			KeeperRewardPercentage: sdk.MustNewDecFromStr("0.05"),
+		},
+	}
+	cached := &PositionData{
+		Height: 1000,
+		Assets: map[string]AssetInfo{
+			"bnb": {
+				Price:                  sdk.MustNewDecFromStr("100.0"),
+				LoanToValueRatio:       sdk.MustNewDecFromStr("0.5"),
+				ConversionFactor:       sdk.NewInt(100000000),
+				KeeperRewardPercentage: sdk.MustNewDecFromStr("0.05"),
+			},
+		},
+		Positions: []Position{
+			{
+				// borrow limit of 50 usd against 45 borrowed
+				Address:         borrower,
+				BorrowedAmount:  sdk.NewCoins(sdk.NewInt64Coin("bnb", 45000000)),
+				DepositedAmount: sdk.NewCoins(sdk.NewInt64Coin("bnb", 100000000)),
+			},
+		},
+	}
+
+	// positions are not fetched again
+	client := MockClient{
+		t:              t,
+		ExpectedHeight: int64(1005),
+		Prices: pricefeedtypes.CurrentPrices{
+			{MarketID: "bnb:usd", Price: sdk.MustNewDecFromStr("80.0")},
+		},
+		Markets:    markets,
+		BorrowsErr: errors.New("borrows fetched"),
+	}
+
+	data, err := RefreshPrices(zerolog.Nop(), client, cached)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1005), data.Height)
+	assert.Equal(t, sdk.MustNewDecFromStr("80.0"), data.Assets["bnb"].Price)
+	assert.Equal(t, cached.Positions, data.Positions)
+	// the cached data is not changed
+	assert.Equal(t, sdk.MustNewDecFromStr("100.0"), cached.Assets["bnb"].Price)
+
+	client.Prices = pricefeedtypes.CurrentPrices{}
+	data, err = RefreshPrices(zerolog.Nop(), client, cached)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"bnb"}, data.Unpriced)
+	assert.Equal(t, 1, data.UnpricedPositions())
+	assert.Equal(t, Borrowers{}, GetBorrowersToLiquidate(data))
+}
+
+func TestGetPositionDataUnpricedMarkets(t *testing.T) {
+	busdBorrower := sdk.AccAddress(crypto.AddressHash([]byte("busd-borrower")))
+	bnbDepositor := sdk.AccAddress(crypto.AddressHash([]byte("bnb-depositor")))
+	btcbBorrower := sdk.AccAddress(crypto.AddressHash([]byte("btcb-borrower")))
+
+	market := func(denom, marketID string) hardtypes.MoneyMarket {
+		return hardtypes.MoneyMarket{
+			Denom:        denom,
+			SpotMarketID: marketID,
+			BorrowLimit: hardtypes.BorrowLimit{
+				HasMaxLimit:  false,
+				MaximumLimit: sdk.MustNewDecFromStr("0.0"),
+				LoanToValue:  sdk.MustNewDecFromStr("0.5"),
+			},
+			ConversionFactor:       sdk.NewInt(100000000),
+			KeeperRewardPercentage: sdk.MustNewDecFromStr("0.05"),
+		}
+	}
+
+	client := MockClient{
+		t:              t,
+		ExpectedHeight: int64(1001),
+		Prices: pricefeedtypes.CurrentPrices{
+			{MarketID: "busd:usd", Price: sdk.MustNewDecFromStr("1.0")},
+			// the pricefeed zeroes markets without valid prices
+			{MarketID: "btcb:usd", Price: sdk.ZeroDec()},
+		},
+		Markets: hardtypes.MoneyMarkets{
+			market("busd", "busd:usd"),
+			market("bnb", "bnb:usd"),
+			market("btcb", "btcb:usd"),
+		},
+		Borrows: hardtypes.Borrows{
+			{
+				// over the limit and valued only in busd
+				Borrower: busdBorrower,
+				Amount:   sdk.NewCoins(sdk.NewInt64Coin("busd", 6000000000)),
+			},
+			{
+				// over the limit without the unpriced bnb deposit
+				Borrower: bnbDepositor,
+				Amount:   sdk.NewCoins(sdk.NewInt64Coin("busd", 6000000000)),
+			},
+			{
+				Borrower: btcbBorrower,
+				Amount:   sdk.NewCoins(sdk.NewInt64Coin("btcb", 100000000)),
+			},
+		},
+		Deposits: hardtypes.Deposits{
+			{
+				Depositor: busdBorrower,
+				Amount:    sdk.NewCoins(sdk.NewInt64Coin("busd", 10000000000)),
+			},
+			{
+				Depositor: bnbDepositor,
+				Amount:    sdk.NewCoins(sdk.NewInt64Coin("busd", 10000000000), sdk.NewInt64Coin("bnb", 100000000)),
+			},
+			{
+				Depositor: btcbBorrower,
+				Amount:    sdk.NewCoins(sdk.NewInt64Coin("busd", 10000000000)),
+			},
+		},
+	}
+
+	data, err := GetPositionData(zerolog.Nop(), client)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"bnb", "btcb"}, data.Unpriced)
+	assert.Equal(t, []string{"busd"}, func() []string {
+		var denoms []string
+		for denom := range data.Assets {
+			denoms = append(denoms, denom)
+		}
+		return denoms
+	}())
+	assert.Len(t, data.Positions, 3)
+
+	// positions holding an unpriced asset are skipped, whether deposited or
+	// borrowed
+	assert.True(t, data.Priced(data.Positions[0]))
+	assert.False(t, data.Priced(data.Positions[1]))
+	assert.False(t, data.Priced(data.Positions[2]))
+	assert.Equal(t, 2, data.UnpricedPositions())
+	assert.Equal(t, Borrowers{busdBorrower}, GetBorrowersToLiquidate(data))
+
+	// every market priced is not degraded
+	client.Prices = append(client.Prices[:1],
+		pricefeedtypes.CurrentPrice{MarketID: "bnb:usd", Price: sdk.MustNewDecFromStr("300.0")},
+		pricefeedtypes.CurrentPrice{MarketID: "btcb:usd", Price: sdk.MustNewDecFromStr("40000.0")},
+	)
+	data, err = GetPositionData(zerolog.Nop(), client)
+	assert.Nil(t, err)
+	assert.Empty(t, data.Unpriced)
+	assert.Equal(t, 0, data.UnpricedPositions())
+	// the bnb deposit covers the borrow, the btcb borrow is over the limit
+	assert.Equal(t, Borrowers{busdBorrower, btcbBorrower}, GetBorrowersToLiquidate(data))
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pricefeedtypes "github.com/kava-labs/kava/x/pricefeed/types"
+	"github.com/rs/zerolog"
+	rpchttpclient "github.com/tendermint/tendermint/rpc/client/http"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+const eventSubscriber = "hard-keeper-bot"
+
+// prices are updated by the pricefeed end blocker, which emits an event for
+// each market whose price changed
+var priceUpdateQuery = fmt.Sprintf(
+	"tm.event='NewBlockHeader' AND %s.%s EXISTS",
+	pricefeedtypes.EventTypeMarketPriceUpdated, pricefeedtypes.AttributeMarketID,
+)
+
+// PriceEventWatcher subscribes to pricefeed price updates and triggers a
+// liquidation check when a market price changes
+type PriceEventWatcher struct {
+	client   *rpchttpclient.HTTP
+	logger   zerolog.Logger
+	triggers chan struct{}
+}
+
+func NewPriceEventWatcher(client *rpchttpclient.HTTP, logger zerolog.Logger) *PriceEventWatcher {
+	return &PriceEventWatcher{
+		client: client,
+		logger: logger,
+		// buffer a single trigger so updates during a check coalesce into one
+		triggers: make(chan struct{}, 1),
+	}
+}
+
+// Triggers returns a channel that receives when prices were updated
+func (w *PriceEventWatcher) Triggers() <-chan struct{} {
+	return w.triggers
+}
+
+// Run starts the websocket client and subscribes to price updates, updates
+// are handled in the background until the context is canceled
+func (w *PriceEventWatcher) Run(ctx context.Context) error {
+	if err := w.client.Start(); err != nil {
+		return fmt.Errorf("failed to start rpc client: %w", err)
+	}
+
+	events, err := w.client.Subscribe(ctx, eventSubscriber, priceUpdateQuery, 100)
+	if err != nil {
+		_ = w.client.Stop()
+		return fmt.Errorf("failed to subscribe to %s: %w", priceUpdateQuery, err)
+	}
+
+	go func() {
+		defer func() { _ = w.client.Stop() }()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-events:
+				w.handleEvent(event)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// handleEvent triggers a check if the event updated a market price
+func (w *PriceEventWatcher) handleEvent(event ctypes.ResultEvent) {
+	key := fmt.Sprintf("%s.%s", pricefeedtypes.EventTypeMarketPriceUpdated, pricefeedtypes.AttributeMarketID)
+	markets := event.Events[key]
+	if len(markets) == 0 {
+		return
+	}
+
+	select {
+	case w.triggers <- struct{}{}:
+		w.logger.Debug().Strs("markets", markets).Msg("prices updated, triggering liquidation check")
+	default:
+		// a check is already pending
+	}
+}
+
+// waitForCheck blocks until prices are updated or the wait elapses, a nil
+// channel is never received from
+func waitForCheck(priceUpdates <-chan struct{}, wait time.Duration) {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-priceUpdates:
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	rpchttpclient "github.com/tendermint/tendermint/rpc/client/http"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+func TestPriceEventWatcher(t *testing.T) {
+	client, err := rpchttpclient.New("http://localhost:26657", "/websocket")
+	assert.NoError(t, err)
+
+	watcher := NewPriceEventWatcher(client, zerolog.Nop())
+	triggered := func() bool {
+		select {
+		case <-watcher.Triggers():
+			return true
+		default:
+			return false
+		}
+	}
+
+	// blocks without price changes do not trigger
+	watcher.handleEvent(ctypes.ResultEvent{Events: map[string][]string{"tm.event": {"NewBlockHeader"}}})
+	assert.False(t, triggered())
+
+	// updates before a check coalesce into one trigger
+	priceUpdate := ctypes.ResultEvent{Events: map[string][]string{
+		"market_price_updated.market_id":    {"bnb:usd", "btcb:usd"},
+		"market_price_updated.market_price": {"300.0", "40000.0"},
+	}}
+	watcher.handleEvent(priceUpdate)
+	watcher.handleEvent(priceUpdate)
+	assert.True(t, triggered())
+	assert.False(t, triggered())
+}
+
+func TestWaitForCheck(t *testing.T) {
+	priceUpdates := make(chan struct{}, 1)
+	priceUpdates <- struct{}{}
+
+	start := time.Now()
+	waitForCheck(priceUpdates, time.Minute)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+
+	// without price updates checks wait for the next scan
+	start = time.Now()
+	waitForCheck(nil, 10*time.Millisecond)
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(10*time.Millisecond))
+}
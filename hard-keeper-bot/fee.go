@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/kava-labs/go-tools/feepolicy"
+	kavagrpc "github.com/kava-labs/go-tools/grpc"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"google.golang.org/grpc"
+)
+
+// FeePolicyConfig sets how fees are priced for liquidation txs
+type FeePolicyConfig = feepolicy.Config
+
+// FeeRetries counts the failed liquidation attempts of each borrower, so
+// liquidations sent again pay escalated fees
+type FeeRetries = feepolicy.Retries
+
+func NewFeeRetries() *FeeRetries {
+	return feepolicy.NewRetries()
+}
+
+// MempoolClient reports the number of unconfirmed txs in a node's mempool,
+// it is satisfied by the tendermint rpc client
+type MempoolClient interface {
+	NumUnconfirmedTxs(ctx context.Context) (*ctypes.ResultUnconfirmedTxs, error)
+}
+
+// FeePolicy prices liquidation fees with the shared fee policy, updating it
+// from the node and mempool with this module's clients
+type FeePolicy struct {
+	*feepolicy.Policy
+}
+
+func NewFeePolicy(config FeePolicyConfig) (*FeePolicy, error) {
+	policy, err := feepolicy.New(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FeePolicy{Policy: policy}, nil
+}
+
+// UpdateMinGasPrices fetches the minimum gas prices from the node config
+// service, only the price in the fee denom is used
+func (p *FeePolicy) UpdateMinGasPrices(ctx context.Context, conn grpc.ClientConnInterface) error {
+	prices, err := kavagrpc.MinimumGasPrices(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	price := prices.AmountOf(p.Denom())
+	p.SetMinGasPrice(math.LegacyNewDecFromBigIntWithPrec(price.BigInt(), sdk.Precision))
+	return nil
+}
+
+// UpdateCongestion checks the number of unconfirmed txs against the
+// congestion threshold, it does nothing if congestion checks are disabled
+func (p *FeePolicy) UpdateCongestion(ctx context.Context, client MempoolClient) error {
+	if !p.ChecksCongestion() {
+		return nil
+	}
+
+	res, err := client.NumUnconfirmedTxs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch unconfirmed txs: %w", err)
+	}
+
+	p.SetUnconfirmedTxs(res.Total)
+	return nil
+}
+
+// Fee returns the fee for a tx with the gas limit that has been retried the
+// given number of times, capped at the max fee
+func (p *FeePolicy) Fee(gasLimit uint64, retries int) sdk.Coins {
+	amount := p.FeeAmount(gasLimit, retries)
+	return sdk.NewCoins(sdk.NewCoin(p.Denom(), sdk.NewIntFromBigInt(amount.BigInt())))
+}
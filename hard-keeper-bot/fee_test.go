@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	kavagrpc "github.com/kava-labs/go-tools/grpc"
+	"github.com/stretchr/testify/assert"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+type mockMempoolClient struct {
+	total int
+}
+
+func (c mockMempoolClient) NumUnconfirmedTxs(ctx context.Context) (*ctypes.ResultUnconfirmedTxs, error) {
+	return &ctypes.ResultUnconfirmedTxs{Total: c.total}, nil
+}
+
+// mockNodeConn answers the node config service with an encoded response
+type mockNodeConn struct {
+	grpc.ClientConnInterface
+	t   *testing.T
+	res []byte
+	err error
+}
+
+func (c mockNodeConn) Invoke(ctx context.Context, method string, args interface{}, reply interface{}, opts ...grpc.CallOption) error {
+	assert.Equal(c.t, kavagrpc.NodeConfigMethod, method)
+	if c.err != nil {
+		return c.err
+	}
+	*reply.(*[]byte) = c.res
+	return nil
+}
+
+// encodeConfigResponse encodes a node ConfigResponse with the minimum gas
+// price, after an unknown field the decoder must skip
+func encodeConfigResponse(minGasPrice string) []byte {
+	var bz []byte
+	bz = protowire.AppendTag(bz, 2, protowire.VarintType)
+	bz = protowire.AppendVarint(bz, 1)
+	bz = protowire.AppendTag(bz, 1, protowire.BytesType)
+	bz = protowire.AppendString(bz, minGasPrice)
+	return bz
+}
+
+func testFeePolicyConfig() FeePolicyConfig {
+	return FeePolicyConfig{
+		Denom:               "ukava",
+		BaseGasPrice:        math.LegacyMustNewDecFromStr("0.05"),
+		EscalationRate:      math.LegacyMustNewDecFromStr("0.5"),
+		MaxFee:              math.NewInt(100000),
+		CongestedMempoolTxs: 1000,
+	}
+}
+
+func TestFeePolicy(t *testing.T) {
+	policy, err := NewFeePolicy(testFeePolicyConfig())
+	assert.NoError(t, err)
+
+	// the previously fixed fee
+	assert.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("ukava", 50000)), policy.Fee(1000000, 0))
+	assert.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("ukava", 75000)), policy.Fee(1000000, 1))
+	// capped at the max fee
+	assert.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("ukava", 100000)), policy.Fee(1000000, 2))
+
+	assert.NoError(t, policy.UpdateCongestion(context.Background(), mockMempoolClient{total: 1500}))
+	assert.Equal(t, math.LegacyMustNewDecFromStr("0.075"), policy.GasPrice(0))
+	assert.NoError(t, policy.UpdateCongestion(context.Background(), mockMempoolClient{total: 10}))
+	assert.Equal(t, math.LegacyMustNewDecFromStr("0.05"), policy.GasPrice(0))
+
+	config := testFeePolicyConfig()
+	config.MaxFee = math.ZeroInt()
+	_, err = NewFeePolicy(config)
+	assert.Error(t, err)
+}
+
+func TestFeePolicyMinGasPrices(t *testing.T) {
+	policy, err := NewFeePolicy(testFeePolicyConfig())
+	assert.NoError(t, err)
+
+	// only the price in the fee denom is used
+	conn := mockNodeConn{t: t, res: encodeConfigResponse("0.1ukava,1uatom")}
+	assert.NoError(t, policy.UpdateMinGasPrices(context.Background(), conn))
+	assert.Equal(t, math.LegacyMustNewDecFromStr("0.1"), policy.GasPrice(0))
+	assert.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("ukava", 100000)), policy.Fee(1000000, 0))
+
+	// the base price is used when the node has no minimum
+	conn.res = encodeConfigResponse("")
+	assert.NoError(t, policy.UpdateMinGasPrices(context.Background(), conn))
+	assert.Equal(t, math.LegacyMustNewDecFromStr("0.05"), policy.GasPrice(0))
+
+	// failures keep the previous price
+	conn.res = encodeConfigResponse("invalid")
+	assert.Error(t, policy.UpdateMinGasPrices(context.Background(), conn))
+	conn.res = []byte{0x0a, 0x05}
+	assert.Error(t, policy.UpdateMinGasPrices(context.Background(), conn))
+	conn.err = errors.New("unimplemented")
+	assert.Error(t, policy.UpdateMinGasPrices(context.Background(), conn))
+	assert.Equal(t, math.LegacyMustNewDecFromStr("0.05"), policy.GasPrice(0))
+}
+
+func TestFeeRetries(t *testing.T) {
+	retries := NewFeeRetries()
+	assert.Equal(t, 0, retries.Retries("borrower"))
+
+	retries.Failed("borrower")
+	retries.Failed("borrower")
+	assert.Equal(t, 2, retries.Retries("borrower"))
+
+	retries.Succeeded("borrower")
+	assert.Equal(t, 0, retries.Retries("borrower"))
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	hardtypes "github.com/kava-labs/kava/x/hard/types"
+	pricefeedtypes "github.com/kava-labs/kava/x/pricefeed/types"
+	"google.golang.org/grpc/metadata"
+)
+
+// GrpcLiquidationClient implements LiquidationClient with the hard and
+// pricefeed grpc query services
+type GrpcLiquidationClient struct {
+	tm        tmservice.ServiceClient
+	hard      hardtypes.QueryClient
+	pricefeed pricefeedtypes.QueryClient
+	PageLimit int
+}
+
+var _ LiquidationClient = (*GrpcLiquidationClient)(nil)
+
+func NewGrpcLiquidationClient(
+	tm tmservice.ServiceClient,
+	hard hardtypes.QueryClient,
+	pricefeed pricefeedtypes.QueryClient,
+) *GrpcLiquidationClient {
+	return &GrpcLiquidationClient{
+		tm:        tm,
+		hard:      hard,
+		pricefeed: pricefeed,
+		PageLimit: DefaultPageLimit,
+	}
+}
+
+func (c *GrpcLiquidationClient) GetInfo() (*InfoResponse, error) {
+	res, err := c.tm.GetLatestBlock(context.Background(), &tmservice.GetLatestBlockRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &InfoResponse{
+		ChainId:      res.Block.Header.ChainID,
+		LatestHeight: res.Block.Header.Height,
+	}, nil
+}
+
+func (c *GrpcLiquidationClient) GetPrices(height int64) (pricefeedtypes.CurrentPrices, error) {
+	res, err := c.pricefeed.Prices(ctxAtHeight(height), &pricefeedtypes.QueryPricesRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	var prices pricefeedtypes.CurrentPrices
+	for _, price := range res.Prices {
+		prices = append(prices, pricefeedtypes.NewCurrentPrice(price.MarketID, price.Price))
+	}
+
+	return prices, nil
+}
+
+func (c *GrpcLiquidationClient) GetMarkets(height int64) (hardtypes.MoneyMarkets, error) {
+	res, err := c.hard.Params(ctxAtHeight(height), &hardtypes.QueryParamsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Params.MoneyMarkets, nil
+}
+
+func (c *GrpcLiquidationClient) GetBorrows(height int64) (hardtypes.Borrows, error) {
+	var borrows hardtypes.Borrows
+
+	pagination := &query.PageRequest{Limit: uint64(c.PageLimit)}
+	for pagination != nil {
+		res, err := c.hard.Borrows(ctxAtHeight(height), &hardtypes.QueryBorrowsRequest{
+			Pagination: pagination,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, borrowRes := range res.Borrows {
+			borrow, err := borrowFromResponse(borrowRes)
+			if err != nil {
+				return nil, err
+			}
+			borrows = append(borrows, borrow)
+		}
+
+		pagination = nextPageRequest(pagination, res.Pagination, len(res.Borrows))
+	}
+
+	return borrows, nil
+}
+
+func (c *GrpcLiquidationClient) GetDeposits(height int64) (hardtypes.Deposits, error) {
+	var deposits hardtypes.Deposits
+
+	pagination := &query.PageRequest{Limit: uint64(c.PageLimit)}
+	for pagination != nil {
+		res, err := c.hard.Deposits(ctxAtHeight(height), &hardtypes.QueryDepositsRequest{
+			Pagination: pagination,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, depositRes := range res.Deposits {
+			deposit, err := depositFromResponse(depositRes)
+			if err != nil {
+				return nil, err
+			}
+			deposits = append(deposits, deposit)
+		}
+
+		pagination = nextPageRequest(pagination, res.Pagination, len(res.Deposits))
+	}
+
+	return deposits, nil
+}
+
+// nextPageRequest returns the request for the page after current, or nil if
+// current was the last page
+//
+// Pages follow the next key when the server returns one. The hard module
+// paginates by offset and returns no page response, so without one pages
+// follow by offset until a page is not full.
+func nextPageRequest(current *query.PageRequest, res *query.PageResponse, results int) *query.PageRequest {
+	if res != nil {
+		if len(res.NextKey) == 0 {
+			return nil
+		}
+		return &query.PageRequest{Key: res.NextKey, Limit: current.Limit}
+	}
+
+	if uint64(results) < current.Limit {
+		return nil
+	}
+	return &query.PageRequest{Offset: current.Offset + uint64(results), Limit: current.Limit}
+}
+
+func borrowFromResponse(res hardtypes.BorrowResponse) (hardtypes.Borrow, error) {
+	borrower, err := sdk.AccAddressFromBech32(res.Borrower)
+	if err != nil {
+		return hardtypes.Borrow{}, fmt.Errorf("invalid borrower %s: %w", res.Borrower, err)
+	}
+
+	var index hardtypes.BorrowInterestFactors
+	for _, factor := range res.Index {
+		value, err := sdk.NewDecFromStr(factor.Value)
+		if err != nil {
+			return hardtypes.Borrow{}, fmt.Errorf("invalid %s borrow index for %s: %w", factor.Denom, res.Borrower, err)
+		}
+		index = append(index, hardtypes.NewBorrowInterestFactor(factor.Denom, value))
+	}
+
+	return hardtypes.NewBorrow(borrower, res.Amount, index), nil
+}
+
+func depositFromResponse(res hardtypes.DepositResponse) (hardtypes.Deposit, error) {
+	depositor, err := sdk.AccAddressFromBech32(res.Depositor)
+	if err != nil {
+		return hardtypes.Deposit{}, fmt.Errorf("invalid depositor %s: %w", res.Depositor, err)
+	}
+
+	var index hardtypes.SupplyInterestFactors
+	for _, factor := range res.Index {
+		value, err := sdk.NewDecFromStr(factor.Value)
+		if err != nil {
+			return hardtypes.Deposit{}, fmt.Errorf("invalid %s supply index for %s: %w", factor.Denom, res.Depositor, err)
+		}
+		index = append(index, hardtypes.NewSupplyInterestFactor(factor.Denom, value))
+	}
+
+	return hardtypes.NewDeposit(depositor, res.Amount, index), nil
+}
+
+func ctxAtHeight(height int64) context.Context {
+	heightStr := strconv.FormatInt(height, 10)
+	return metadata.AppendToOutgoingContext(context.Background(), grpctypes.GRPCBlockHeightHeader, heightStr)
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	hardtypes "github.com/kava-labs/kava/x/hard/types"
+	pricefeedtypes "github.com/kava-labs/kava/x/pricefeed/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/tendermint/crypto"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type MockTmServiceClient struct {
+	tmservice.ServiceClient
+	Header tmproto.Header
+	Err    error
+}
+
+func (m *MockTmServiceClient) GetLatestBlock(ctx context.Context, in *tmservice.GetLatestBlockRequest, opts ...grpc.CallOption) (*tmservice.GetLatestBlockResponse, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return &tmservice.GetLatestBlockResponse{Block: &tmproto.Block{Header: m.Header}}, nil
+}
+
+type MockPricefeedQueryClient struct {
+	pricefeedtypes.QueryClient
+	t             *testing.T
+	Height        int64
+	CurrentPrices pricefeedtypes.CurrentPriceResponses
+	Err           error
+}
+
+func (m *MockPricefeedQueryClient) Prices(ctx context.Context, in *pricefeedtypes.QueryPricesRequest, opts ...grpc.CallOption) (*pricefeedtypes.QueryPricesResponse, error) {
+	assertQueryHeight(m.t, ctx, m.Height)
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return &pricefeedtypes.QueryPricesResponse{Prices: m.CurrentPrices}, nil
+}
+
+type MockHardQueryClient struct {
+	hardtypes.QueryClient
+	t                *testing.T
+	Height           int64
+	HardParams       hardtypes.Params
+	BorrowResponses  hardtypes.BorrowResponses
+	DepositResponses hardtypes.DepositResponses
+	// KeyPagination returns next keys rather than paginating by offset only
+	// as the hard module does
+	KeyPagination bool
+	Err           error
+}
+
+func (m *MockHardQueryClient) Params(ctx context.Context, in *hardtypes.QueryParamsRequest, opts ...grpc.CallOption) (*hardtypes.QueryParamsResponse, error) {
+	assertQueryHeight(m.t, ctx, m.Height)
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return &hardtypes.QueryParamsResponse{Params: m.HardParams}, nil
+}
+
+func (m *MockHardQueryClient) Borrows(ctx context.Context, in *hardtypes.QueryBorrowsRequest, opts ...grpc.CallOption) (*hardtypes.QueryBorrowsResponse, error) {
+	assertQueryHeight(m.t, ctx, m.Height)
+	// owner and denom are not set -- we fetch all borrows
+	assert.Equal(m.t, "", in.Owner)
+	assert.Equal(m.t, "", in.Denom)
+	if m.Err != nil {
+		return nil, m.Err
+	}
+
+	start, end, page := m.paginate(in.Pagination, len(m.BorrowResponses))
+	return &hardtypes.QueryBorrowsResponse{Borrows: m.BorrowResponses[start:end], Pagination: page}, nil
+}
+
+func (m *MockHardQueryClient) Deposits(ctx context.Context, in *hardtypes.QueryDepositsRequest, opts ...grpc.CallOption) (*hardtypes.QueryDepositsResponse, error) {
+	assertQueryHeight(m.t, ctx, m.Height)
+	// owner and denom are not set -- we fetch all deposits
+	assert.Equal(m.t, "", in.Owner)
+	assert.Equal(m.t, "", in.Denom)
+	if m.Err != nil {
+		return nil, m.Err
+	}
+
+	start, end, page := m.paginate(in.Pagination, len(m.DepositResponses))
+	return &hardtypes.QueryDepositsResponse{Deposits: m.DepositResponses[start:end], Pagination: page}, nil
+}
+
+func (m *MockHardQueryClient) paginate(req *query.PageRequest, total int) (int, int, *query.PageResponse) {
+	start := int(req.Offset)
+	if len(req.Key) > 0 {
+		assert.Zero(m.t, req.Offset, "offset set with key")
+		start = int(binary.BigEndian.Uint64(req.Key))
+	}
+
+	end := start + int(req.Limit)
+	if end > total {
+		end = total
+	}
+	if start > total {
+		start = total
+	}
+
+	if !m.KeyPagination {
+		assert.Empty(m.t, req.Key, "key set without key pagination")
+		return start, end, nil
+	}
+
+	page := &query.PageResponse{}
+	if end < total {
+		page.NextKey = make([]byte, 8)
+		binary.BigEndian.PutUint64(page.NextKey, uint64(end))
+	}
+	return start, end, page
+}
+
+func assertQueryHeight(t *testing.T, ctx context.Context, height int64) {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, []string{sdk.NewInt(height).String()}, md.Get(grpctypes.GRPCBlockHeightHeader))
+}
+
+func TestGrpcGetInfo(t *testing.T) {
+	tm := &MockTmServiceClient{Header: tmproto.Header{ChainID: "kava-9", Height: 100}}
+	client := NewGrpcLiquidationClient(tm, &MockHardQueryClient{}, &MockPricefeedQueryClient{})
+
+	info, err := client.GetInfo()
+	assert.Nil(t, err)
+	assert.Equal(t, "kava-9", info.ChainId)
+	assert.Equal(t, int64(100), info.LatestHeight)
+
+	tm.Err = errors.New("error getting block")
+	info, err = client.GetInfo()
+	assert.Nil(t, info)
+	assert.Equal(t, tm.Err, err)
+}
+
+func TestGrpcGetPrices(t *testing.T) {
+	height := int64(1001)
+	pricefeed := &MockPricefeedQueryClient{
+		t:      t,
+		Height: height,
+		CurrentPrices: pricefeedtypes.CurrentPriceResponses{
+			pricefeedtypes.NewCurrentPriceResponse("busd:usd", sdk.MustNewDecFromStr("1.004")),
+			pricefeedtypes.NewCurrentPriceResponse("busd:usd:30", sdk.MustNewDecFromStr("1.003")),
+		},
+	}
+	client := NewGrpcLiquidationClient(&MockTmServiceClient{}, &MockHardQueryClient{}, pricefeed)
+
+	prices, err := client.GetPrices(height)
+	assert.Nil(t, err)
+	assert.Equal(t, pricefeedtypes.CurrentPrices{
+		{MarketID: "busd:usd", Price: sdk.MustNewDecFromStr("1.004")},
+		{MarketID: "busd:usd:30", Price: sdk.MustNewDecFromStr("1.003")},
+	}, prices)
+
+	pricefeed.Err = errors.New("grpc error")
+	prices, err = client.GetPrices(height)
+	assert.Nil(t, prices)
+	assert.Equal(t, pricefeed.Err, err)
+}
+
+func TestGrpcGetMarkets(t *testing.T) {
+	height := int64(1001)
+	hard := &MockHardQueryClient{
+		t:      t,
+		Height: height,
+		HardParams: hardtypes.Params{
+			MoneyMarkets: hardtypes.MoneyMarkets{
+				{
+					Denom:                  "busd",
+					SpotMarketID:           "busd:usd",
+					ConversionFactor:       sdk.NewInt(100000000),
+					KeeperRewardPercentage: sdk.MustNewDecFromStr("0.02"),
+				},
+			},
+		},
+	}
+	client := NewGrpcLiquidationClient(&MockTmServiceClient{}, hard, &MockPricefeedQueryClient{})
+
+	markets, err := client.GetMarkets(height)
+	assert.Nil(t, err)
+	assert.Equal(t, hard.HardParams.MoneyMarkets, markets)
+
+	hard.Err = errors.New("grpc error")
+	markets, err = client.GetMarkets(height)
+	assert.Nil(t, markets)
+	assert.Equal(t, hard.Err, err)
+}
+
+func TestGrpcGetBorrows(t *testing.T) {
+	height := int64(1001)
+
+	mockCoins := sdk.Coins{{Amount: sdk.NewInt(100000), Denom: "btcb"}, {Amount: sdk.NewInt(100000), Denom: "busd"}}
+	mockIndex := hardtypes.BorrowInterestFactors{{Denom: "busd", Value: sdk.MustNewDecFromStr("1.002")}, {Denom: "btcb", Value: sdk.MustNewDecFromStr("1.004")}}
+
+	var borrows hardtypes.Borrows
+	for _, name := range []string{"borrower1", "borrower2", "borrower3", "borrower4", "borrower5"} {
+		borrows = append(borrows, hardtypes.NewBorrow(sdk.AccAddress(crypto.AddressHash([]byte(name))), mockCoins, mockIndex))
+	}
+
+	hard := &MockHardQueryClient{t: t, Height: height, BorrowResponses: borrows.ToResponse()}
+	client := NewGrpcLiquidationClient(&MockTmServiceClient{}, hard, &MockPricefeedQueryClient{})
+
+	for _, keyPagination := range []bool{false, true} {
+		hard.KeyPagination = keyPagination
+
+		for _, pageLimit := range []int{10, 1, 2, 5} {
+			client.PageLimit = pageLimit
+
+			resp, err := client.GetBorrows(height)
+			assert.Nil(t, err)
+			assert.Equal(t, borrows, resp, "page limit %d, key pagination %t", pageLimit, keyPagination)
+		}
+	}
+
+	hard.BorrowResponses[2].Index[0].Value = "invalid"
+	_, err := client.GetBorrows(height)
+	assert.NotNil(t, err)
+	assert.Regexp(t, "invalid busd borrow index", err.Error())
+
+	hard.Err = errors.New("grpc error")
+	resp, err := client.GetBorrows(height)
+	assert.Nil(t, resp)
+	assert.Equal(t, hard.Err, err)
+}
+
+func TestGrpcGetDeposits(t *testing.T) {
+	height := int64(1001)
+
+	mockCoins := sdk.Coins{{Amount: sdk.NewInt(100000), Denom: "btcb"}, {Amount: sdk.NewInt(100000), Denom: "busd"}}
+	mockIndex := hardtypes.SupplyInterestFactors{{Denom: "busd", Value: sdk.MustNewDecFromStr("1.002")}, {Denom: "btcb", Value: sdk.MustNewDecFromStr("1.004")}}
+
+	var deposits hardtypes.Deposits
+	for _, name := range []string{"depositor1", "depositor2", "depositor3", "depositor4", "depositor5"} {
+		deposits = append(deposits, hardtypes.NewDeposit(sdk.AccAddress(crypto.AddressHash([]byte(name))), mockCoins, mockIndex))
+	}
+
+	hard := &MockHardQueryClient{t: t, Height: height, DepositResponses: deposits.ToResponse()}
+	client := NewGrpcLiquidationClient(&MockTmServiceClient{}, hard, &MockPricefeedQueryClient{})
+
+	for _, keyPagination := range []bool{false, true} {
+		hard.KeyPagination = keyPagination
+
+		for _, pageLimit := range []int{10, 1, 2, 5} {
+			client.PageLimit = pageLimit
+
+			resp, err := client.GetDeposits(height)
+			assert.Nil(t, err)
+			assert.Equal(t, deposits, resp, "page limit %d, key pagination %t", pageLimit, keyPagination)
+		}
+	}
+
+	hard.DepositResponses[0].Depositor = "kava1invalid"
+	_, err := client.GetDeposits(height)
+	assert.NotNil(t, err)
+	assert.Regexp(t, "invalid depositor kava1invalid", err.Error())
+
+	hard.Err = errors.New("grpc error")
+	resp, err := client.GetDeposits(height)
+	assert.Nil(t, resp)
+	assert.Equal(t, hard.Err, err)
+}
+
+func TestGrpcPositionDataUsesSyncedBalances(t *testing.T) {
+	height := int64(1001)
+	overLimit := sdk.AccAddress(crypto.AddressHash([]byte("over-limit")))
+	underLimit := sdk.AccAddress(crypto.AddressHash([]byte("under-limit")))
+
+	// the queries sync balances to the height, both borrowed 790 busd at an
+	// index of 1.0 that has since accrued to 1.0139
+	index := hardtypes.BorrowInterestFactorResponses{{Denom: "busd", Value: "1.013924050632911392"}}
+	deposit := sdk.NewCoins(sdk.NewInt64Coin("busd", 1000e8))
+
+	hard := &MockHardQueryClient{
+		t:      t,
+		Height: height,
+		HardParams: hardtypes.Params{
+			MoneyMarkets: hardtypes.MoneyMarkets{
+				{
+					Denom:        "busd",
+					SpotMarketID: "busd:usd",
+					BorrowLimit: hardtypes.BorrowLimit{
+						MaximumLimit: sdk.ZeroDec(),
+						LoanToValue:  sdk.MustNewDecFromStr("0.8"),
+					},
+					ConversionFactor:       sdk.NewInt(1e8),
+					KeeperRewardPercentage: sdk.MustNewDecFromStr("0.02"),
+				},
+			},
+		},
+		BorrowResponses: hardtypes.BorrowResponses{
+			// accrued interest takes the borrow over the 800 busd limit
+			{Borrower: overLimit.String(), Amount: sdk.NewCoins(sdk.NewInt64Coin("busd", 801e8)), Index: index},
+			// under the limit, it would be over if the index were applied again
+			{Borrower: underLimit.String(), Amount: sdk.NewCoins(sdk.NewInt64Coin("busd", 799e8)), Index: index},
+		},
+		DepositResponses: hardtypes.DepositResponses{
+			{Depositor: overLimit.String(), Amount: deposit},
+			{Depositor: underLimit.String(), Amount: deposit},
+		},
+	}
+	pricefeed := &MockPricefeedQueryClient{
+		t:      t,
+		Height: height,
+		CurrentPrices: pricefeedtypes.CurrentPriceResponses{
+			pricefeedtypes.NewCurrentPriceResponse("busd:usd", sdk.OneDec()),
+		},
+	}
+	client := NewGrpcLiquidationClient(&MockTmServiceClient{Header: tmproto.Header{Height: height}}, hard, pricefeed)
+
+	data, err := GetPositionData(zerolog.Nop(), client)
+	assert.NoError(t, err)
+	assert.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("busd", 801e8)), data.Positions[0].BorrowedAmount)
+	assert.Equal(t, deposit, data.Positions[0].DepositedAmount)
+	assert.Equal(t, Borrowers{overLimit}, GetBorrowersToLiquidate(data))
+}
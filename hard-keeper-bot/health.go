@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alexliesenfeld/health"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog"
+)
+
+// PositionHealth is the health of a position in the latest scan, the USD
+// values are not set for positions holding an asset without a price
+type PositionHealth struct {
+	Borrower string `json:"borrower"`
+	Priced   bool   `json:"priced"`
+	// HealthFactor is the borrow limit over the borrowed value, positions
+	// under 1 can be liquidated
+	HealthFactor   *sdk.Dec `json:"health_factor,omitempty"`
+	BorrowedUSD    *sdk.Dec `json:"borrowed_usd,omitempty"`
+	BorrowLimitUSD *sdk.Dec `json:"borrow_limit_usd,omitempty"`
+	Liquidatable   bool     `json:"liquidatable"`
+}
+
+// GetPositionHealth returns the health of each position
+func GetPositionHealth(data *PositionData) []PositionHealth {
+	positions := make([]PositionHealth, len(data.Positions))
+
+	for i, pos := range data.Positions {
+		positions[i] = PositionHealth{Borrower: pos.Address.String()}
+		if !data.Priced(pos) {
+			continue
+		}
+
+		borrowLimit, borrowed := GetPositionUSDValues(data.Assets, pos)
+		positions[i].Priced = true
+		positions[i].BorrowedUSD = &borrowed
+		positions[i].BorrowLimitUSD = &borrowLimit
+		positions[i].Liquidatable = borrowed.GT(borrowLimit)
+		if borrowed.IsPositive() {
+			healthFactor := borrowLimit.Quo(borrowed)
+			positions[i].HealthFactor = &healthFactor
+		}
+	}
+
+	return positions
+}
+
+// PositionsSnapshot is the health of every position at a height
+type PositionsSnapshot struct {
+	Height    int64            `json:"height"`
+	ScannedAt time.Time        `json:"scanned_at"`
+	Unpriced  []string         `json:"unpriced"`
+	Positions []PositionHealth `json:"positions"`
+}
+
+// ScanState holds the latest scan for the http server
+//
+// ScanState is safe for concurrent use.
+type ScanState struct {
+	mu       sync.RWMutex
+	snapshot PositionsSnapshot
+}
+
+func NewScanState() *ScanState {
+	return &ScanState{
+		snapshot: PositionsSnapshot{Unpriced: []string{}, Positions: []PositionHealth{}},
+	}
+}
+
+// Update replaces the snapshot with the positions of a completed scan
+func (s *ScanState) Update(data *PositionData, scannedAt time.Time) {
+	unpriced := append([]string{}, data.Unpriced...)
+	snapshot := PositionsSnapshot{
+		Height:    data.Height,
+		ScannedAt: scannedAt,
+		Unpriced:  unpriced,
+		Positions: GetPositionHealth(data),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshot = snapshot
+}
+
+// Snapshot returns the latest scan
+func (s *ScanState) Snapshot() PositionsSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.snapshot
+}
+
+// reportOnly keeps a failing check up, its error is reported without failing
+// the health check as the bot keeps liquidating what it can
+func reportOnly(next health.InterceptorFunc) health.InterceptorFunc {
+	return func(ctx context.Context, name string, state health.CheckState) health.CheckState {
+		state = next(ctx, name, state)
+		if state.Status == health.StatusDown {
+			state.Status = health.StatusUp
+		}
+
+		return state
+	}
+}
+
+// NewPositionsHandler responds with the health of every position in the
+// latest scan
+func NewPositionsHandler(state *ScanState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, http.StatusOK, state.Snapshot())
+	}
+}
+
+func writeJson(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// checkScanAge returns an error if there is no scan or the latest completed
+// too long ago
+func checkScanAge(snapshot PositionsSnapshot, now time.Time, maxAge time.Duration) error {
+	if snapshot.ScannedAt.IsZero() {
+		return fmt.Errorf("no scan completed")
+	}
+	if age := now.Sub(snapshot.ScannedAt); age > maxAge {
+		return fmt.Errorf("last scan completed %s ago, max %s", age.Round(time.Second), maxAge)
+	}
+
+	return nil
+}
+
+// checkPrices returns an error if markets were without a price in the latest
+// scan
+func checkPrices(snapshot PositionsSnapshot) error {
+	if len(snapshot.Unpriced) == 0 {
+		return nil
+	}
+
+	skipped := 0
+	for _, pos := range snapshot.Positions {
+		if !pos.Priced {
+			skipped++
+		}
+	}
+
+	return fmt.Errorf("no price for %s, skipping %d positions", strings.Join(snapshot.Unpriced, ", "), skipped)
+}
+
+// startHealthCheckService serves /health, /positions and prometheus /metrics
+func startHealthCheckService(
+	logger zerolog.Logger,
+	listenAddr string,
+	checks []health.CheckerOption,
+	state *ScanState,
+	gatherer prometheus.Gatherer,
+) {
+	options := append([]health.CheckerOption{
+		health.WithCacheDuration(1 * time.Second),
+		health.WithTimeout(10 * time.Second),
+		// Runs when health status changes
+		health.WithStatusListener(func(ctx context.Context, state health.CheckerState) {
+			logger.
+				Debug().
+				Str("status", string(state.Status)).
+				Msg("health status changed")
+		}),
+	}, checks...)
+
+	r := chi.NewRouter()
+	r.Get("/health", health.NewHandler(health.NewChecker(options...)))
+	r.Get("/positions", NewPositionsHandler(state))
+	r.Handle("/metrics", promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+
+	server := &http.Server{
+		Addr:    listenAddr,
+		Handler: r,
+	}
+
+	go func() {
+		logger.
+			Info().
+			Msgf("healthcheck server listening on %s", server.Addr)
+
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatal().Err(err).Msg("failed to start healthcheck server")
+		}
+	}()
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alexliesenfeld/health"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/tendermint/crypto"
+)
+
+func testHealthData() *PositionData {
+	return &PositionData{
+		Height: 1001,
+		Assets: map[string]AssetInfo{
+			"busd": {
+				Price:                  sdk.MustNewDecFromStr("1.0"),
+				LoanToValueRatio:       sdk.MustNewDecFromStr("0.5"),
+				ConversionFactor:       sdk.NewInt(100000000),
+				KeeperRewardPercentage: sdk.MustNewDecFromStr("0.05"),
+			},
+		},
+		Positions: []Position{
+			{
+				// borrow limit of 50 busd against 25 borrowed
+				Address:         sdk.AccAddress(crypto.AddressHash([]byte("healthy"))),
+				BorrowedAmount:  sdk.NewCoins(sdk.NewInt64Coin("busd", 2500000000)),
+				DepositedAmount: sdk.NewCoins(sdk.NewInt64Coin("busd", 10000000000)),
+			},
+			{
+				// borrow limit of 50 busd against 80 borrowed
+				Address:         sdk.AccAddress(crypto.AddressHash([]byte("liquidatable"))),
+				BorrowedAmount:  sdk.NewCoins(sdk.NewInt64Coin("busd", 8000000000)),
+				DepositedAmount: sdk.NewCoins(sdk.NewInt64Coin("busd", 10000000000)),
+			},
+			{
+				Address:         sdk.AccAddress(crypto.AddressHash([]byte("unpriced"))),
+				BorrowedAmount:  sdk.NewCoins(sdk.NewInt64Coin("busd", 2500000000)),
+				DepositedAmount: sdk.NewCoins(sdk.NewInt64Coin("bnb", 100000000)),
+			},
+		},
+		Unpriced: []string{"bnb"},
+	}
+}
+
+func TestGetPositionHealth(t *testing.T) {
+	data := testHealthData()
+	positions := GetPositionHealth(data)
+	assert.Len(t, positions, 3)
+
+	healthFactor := sdk.MustNewDecFromStr("2")
+	borrowed := sdk.MustNewDecFromStr("25")
+	borrowLimit := sdk.MustNewDecFromStr("50")
+	assert.Equal(t, PositionHealth{
+		Borrower:       data.Positions[0].Address.String(),
+		Priced:         true,
+		HealthFactor:   &healthFactor,
+		BorrowedUSD:    &borrowed,
+		BorrowLimitUSD: &borrowLimit,
+		Liquidatable:   false,
+	}, positions[0])
+
+	assert.True(t, positions[1].Liquidatable)
+	assert.Equal(t, sdk.MustNewDecFromStr("0.625"), *positions[1].HealthFactor)
+
+	// unpriced positions can not be valued
+	assert.Equal(t, PositionHealth{Borrower: data.Positions[2].Address.String()}, positions[2])
+}
+
+func TestPositionsHandler(t *testing.T) {
+	state := NewScanState()
+
+	get := func() PositionsSnapshot {
+		recorder := httptest.NewRecorder()
+		NewPositionsHandler(state)(recorder, httptest.NewRequest(http.MethodGet, "/positions", nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+		var snapshot PositionsSnapshot
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &snapshot))
+		return snapshot
+	}
+
+	// empty before the first scan
+	snapshot := get()
+	assert.Empty(t, snapshot.Positions)
+	assert.True(t, snapshot.ScannedAt.IsZero())
+
+	scannedAt := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	state.Update(testHealthData(), scannedAt)
+
+	snapshot = get()
+	assert.Equal(t, int64(1001), snapshot.Height)
+	assert.Equal(t, scannedAt, snapshot.ScannedAt)
+	assert.Equal(t, []string{"bnb"}, snapshot.Unpriced)
+	assert.Equal(t, GetPositionHealth(testHealthData()), snapshot.Positions)
+}
+
+func TestHealthHandler(t *testing.T) {
+	var failing, unpriced error
+	checker := health.NewChecker(
+		health.WithDisabledCache(),
+		health.WithCheck(health.Check{Name: "kava grpc", Check: func(ctx context.Context) error { return failing }}),
+		health.WithCheck(health.Check{
+			Name:         "market prices",
+			Check:        func(ctx context.Context) error { return unpriced },
+			Interceptors: []health.Interceptor{reportOnly},
+		}),
+	)
+
+	get := func() (int, health.CheckerResult) {
+		recorder := httptest.NewRecorder()
+		health.NewHandler(checker)(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+		var response health.CheckerResult
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		return recorder.Code, response
+	}
+
+	code, response := get()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, health.StatusUp, response.Status)
+
+	// unpriced markets are reported without failing
+	unpriced = errors.New("no price for bnb, skipping 1 positions")
+	code, response = get()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, health.StatusUp, response.Status)
+	assert.Equal(t, health.StatusUp, response.Details["market prices"].Status)
+	assert.EqualError(t, response.Details["market prices"].Error, unpriced.Error())
+
+	failing = errors.New("connection refused")
+	code, response = get()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, health.StatusDown, response.Status)
+	assert.Equal(t, health.StatusDown, response.Details["kava grpc"].Status)
+	assert.EqualError(t, response.Details["kava grpc"].Error, "connection refused")
+}
+
+func TestHealthChecks(t *testing.T) {
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.EqualError(t, checkScanAge(PositionsSnapshot{}, now, time.Hour), "no scan completed")
+	assert.NoError(t, checkScanAge(PositionsSnapshot{ScannedAt: now.Add(-time.Minute)}, now, time.Hour))
+	assert.EqualError(t,
+		checkScanAge(PositionsSnapshot{ScannedAt: now.Add(-2 * time.Hour)}, now, time.Hour),
+		"last scan completed 2h0m0s ago, max 1h0m0s",
+	)
+
+	state := NewScanState()
+	assert.NoError(t, checkPrices(state.Snapshot()))
+	state.Update(testHealthData(), now)
+	assert.EqualError(t, checkPrices(state.Snapshot()), "no price for bnb, skipping 1 positions")
+}
+
+func TestMetricsObserveScan(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics := NewMetrics(registry)
+
+	start := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	metrics.ObserveScan(testHealthData(), 1, start, start.Add(2*time.Second))
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(metrics.PositionsScanned))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.LiquidatablePositions))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.UnpricedMarkets))
+	assert.Equal(t, float64(start.Add(2*time.Second).Unix()), testutil.ToFloat64(metrics.LastScanTimestampSecs))
+
+	count, err := testutil.GatherAndCount(registry, "hard_keeper_scan_duration_seconds")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
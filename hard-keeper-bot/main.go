@@ -2,66 +2,70 @@ package main
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
-	"log"
-	"net/url"
 	"os"
+	"path/filepath"
 	"time"
 
+	"github.com/alexliesenfeld/health"
 	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	"github.com/cosmos/cosmos-sdk/codec"
 	"github.com/cosmos/cosmos-sdk/crypto/hd"
 	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
 	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	kavagrpc "github.com/kava-labs/go-tools/grpc"
 	"github.com/kava-labs/go-tools/signing"
 	"github.com/kava-labs/kava/app"
+	hardtypes "github.com/kava-labs/kava/x/hard/types"
+	pricefeedtypes "github.com/kava-labs/kava/x/pricefeed/types"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
 	rpchttpclient "github.com/tendermint/tendermint/rpc/client/http"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
 )
 
+// liquidationGasLimit is the gas limit of a single liquidation, batches are
+// given the gas of each liquidation they contain
+const liquidationGasLimit = uint64(1000000)
+
 func main() {
 	app.SetSDKConfig()
 	encodingConfig := app.MakeEncodingConfig()
 	logger := zerolog.New(os.Stderr)
 
-	config, err := LoadConfig(&EnvLoader{})
-	if err != nil {
-		logger.Fatal().Err(err).Send()
+	if len(os.Args) > 1 && os.Args[1] == "risk" {
+		if err := runRisk(logger, encodingConfig.Amino); err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+		return
 	}
 
-	grpcUrl, err := url.Parse(config.KavaGrpcUrl)
+	config, err := LoadConfig(&EnvLoader{})
 	if err != nil {
 		logger.Fatal().Err(err).Send()
 	}
 
-	var secureOpt grpc.DialOption
-	switch grpcUrl.Scheme {
-	case "http":
-		secureOpt = grpc.WithInsecure()
-	case "https":
-		creds := credentials.NewTLS(&tls.Config{})
-		secureOpt = grpc.WithTransportCredentials(creds)
-	default:
-		log.Fatalf("unknown rpc url scheme %s\n", grpcUrl.Scheme)
-	}
-
-	http, err := rpchttpclient.New(config.KavaRpcUrl, "/websocket")
+	conn, err := kavagrpc.NewGrpcConnection(config.KavaGrpcUrl)
 	if err != nil {
 		logger.Fatal().Err(err).Send()
 	}
-	liquidationClient := NewRpcLiquidationClient(http, encodingConfig.Amino)
+	defer conn.Close()
 
-	conn, err := grpc.Dial(grpcUrl.Host, secureOpt)
-	if err != nil {
-		logger.Fatal().Err(err).Send()
+	// the rpc is optional, it is only set for the rpc query client, mempool
+	// congestion checks and price update triggers
+	var http *rpchttpclient.HTTP
+	if config.KavaRpcUrl != "" {
+		http, err = rpchttpclient.New(config.KavaRpcUrl, "/websocket")
+		if err != nil {
+			logger.Fatal().Err(err).Send()
+		}
 	}
-	defer conn.Close()
 
 	tmClient := tmservice.NewServiceClient(conn)
+	liquidationClient := newLiquidationClient(config.KavaQueryClient, conn, http, encodingConfig.Amino)
+
 	nodeInfoResponse, err := tmClient.GetNodeInfo(context.Background(), &tmservice.GetNodeInfoRequest{})
 	if err != nil {
 		logger.Fatal().Err(err).Send()
@@ -77,6 +81,10 @@ func main() {
 	}
 	privKey := &secp256k1.PrivKey{Key: privKeyBytes}
 
+	// liquidations are simulated before they are sent, so liquidations that
+	// would fail do not spend fees and sequences
+	simulator := NewLiquidationSimulator(txClient, encodingConfig.TxConfig, privKey, config.KavaKeeperAddress)
+
 	signer := signing.NewSigner(
 		nodeInfoResponse.DefaultNodeInfo.Network,
 		signing.EncodingConfigAdapter{EncodingConfig: encodingConfig},
@@ -87,6 +95,62 @@ func main() {
 		logger,
 	)
 
+	// scans and liquidations are reported by the health check server
+	metrics := NewMetrics(prometheus.DefaultRegisterer)
+	scanState := NewScanState()
+
+	checks := []health.CheckerOption{
+		// Run every minute with initial delay of 3 seconds. Not run each HTTP request
+		health.WithPeriodicCheck(60*time.Second, 3*time.Second, health.Check{
+			Name: "kava grpc",
+			Check: func(ctx context.Context) error {
+				_, err := tmClient.GetLatestBlock(ctx, &tmservice.GetLatestBlockRequest{})
+				return err
+			},
+		}),
+		health.WithCheck(health.Check{
+			Name: "signing account",
+			Check: func(ctx context.Context) error {
+				return signer.GetAccountError()
+			},
+		}),
+		health.WithCheck(health.Check{
+			Name: "last scan",
+			Check: func(ctx context.Context) error {
+				return checkScanAge(scanState.Snapshot(), time.Now(), config.HealthMaxScanAge)
+			},
+		}),
+		health.WithCheck(health.Check{
+			Name: "market prices",
+			Check: func(ctx context.Context) error {
+				return checkPrices(scanState.Snapshot())
+			},
+			Interceptors: []health.Interceptor{reportOnly},
+		}),
+	}
+	if http != nil {
+		checks = append(checks, health.WithPeriodicCheck(60*time.Second, 3*time.Second, health.Check{
+			Name: "kava rpc",
+			Check: func(ctx context.Context) error {
+				_, err := http.Status(ctx)
+				return err
+			},
+		}))
+	}
+	startHealthCheckService(logger, config.HealthCheckListenAddr, checks, scanState, prometheus.DefaultGatherer)
+
+	// fees escalate for borrowers whose liquidation failed and while the
+	// mempool is congested
+	feePolicy, err := NewFeePolicy(config.Fee)
+	if err != nil {
+		logger.Fatal().Err(err).Send()
+	}
+	feeRetries := NewFeeRetries()
+
+	// borrowers are not sent again while their liquidation is in flight, and
+	// are put on cooldown when their liquidations keep failing
+	tracker := NewLiquidationTracker(config.Cooldown)
+
 	// channels to communicate with signer
 	requests := make(chan signing.MsgRequest)
 
@@ -97,52 +161,344 @@ func main() {
 		os.Exit(1)
 	}
 
+	// liquidationRequest creates a tx liquidating a batch of borrowers, priced
+	// for its most retried borrower
+	liquidationRequest := func(batch Borrowers) signing.MsgRequest {
+		retries := 0
+		for _, borrower := range batch {
+			if r := feeRetries.Retries(borrower.String()); r > retries {
+				retries = r
+			}
+		}
+
+		msgs := CreateLiquidationMsgs(config.KavaKeeperAddress, batch)
+		sdkMsgs := make([]sdk.Msg, len(msgs))
+		for i := range msgs {
+			sdkMsgs[i] = &msgs[i]
+		}
+
+		gasLimit := liquidationGasLimit * uint64(len(batch))
+		return signing.MsgRequest{
+			Msgs:      sdkMsgs,
+			GasLimit:  gasLimit,
+			FeeAmount: feePolicy.Fee(gasLimit, retries),
+			Memo:      "",
+			Data:      batch,
+		}
+	}
+
+	// resendSplit sends the halves of a failed batch to isolate the failing
+	// liquidation, the borrowers stay in flight until it is sent on its own
+	//
+	// requests are sent from a new go routine, the signer blocks on responses
+	// that would not be read while a request is sent from the response loop
+	resendSplit := func(batch Borrowers, err error) {
+		logger.Warn().Err(err).Int("batch_size", len(batch)).Msg("liquidation batch failed, splitting")
+
+		left, right := SplitBatch(batch)
+		go func() {
+			requests <- liquidationRequest(left)
+			requests <- liquidationRequest(right)
+		}()
+	}
+
 	// log responses, if responses are not read, requests will block
 	go func() {
 		for {
 			// response is not returned until the msg is committed to a block
 			response := <-responses
 
+			// liquidations are sent in batches of borrowers
+			batch, _ := response.Request.Data.(Borrowers)
+
 			// error will be set if response is not Code 0 (success) or Code 19 (already in mempool)
 			if response.Err != nil {
-				fmt.Printf("response code: %d error %s\n", response.Result.Code, response.Err)
+				logger.Warn().
+					Err(response.Err).
+					Uint32("code", response.Result.Code).
+					Int("batch_size", len(batch)).
+					Msg("liquidation failed to broadcast")
+				if len(batch) > 1 {
+					resendSplit(batch, response.Err)
+					continue
+				}
+				for _, borrower := range batch {
+					feeRetries.Failed(borrower.String())
+					liquidationFailed(logger, tracker, metrics, borrower.String(), response.Err)
+				}
 				continue
 			}
+			for _, borrower := range batch {
+				feeRetries.Succeeded(borrower.String())
+			}
+
+			// code and result are from broadcast, not deliver tx, the
+			// liquidations are confirmed from the events of the delivered tx
+			logger.Info().
+				Str("tx_hash", response.Result.TxHash).
+				Int("batch_size", len(batch)).
+				Msg("liquidation committed")
+
+			go func(txHash string) {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+				defer cancel()
+
+				res, err := FetchTx(ctx, txClient, txHash)
+				if err != nil {
+					for _, borrower := range batch {
+						liquidationFailed(logger, tracker, metrics, borrower.String(), err)
+					}
+					return
+				}
+
+				// a failing liquidation reverts every liquidation in the tx
+				if res.Code != 0 && len(batch) > 1 {
+					resendSplit(batch, fmt.Errorf("tx %s failed with code %d: %s", res.TxHash, res.Code, res.RawLog))
+					return
+				}
 
-			// code and result are from broadcast, not deliver tx
-			// it is up to the caller/requester to check the deliver tx code and deal with failure
-			fmt.Printf("response code: %d, hash %s\n", response.Result.Code, response.Result.TxHash)
+				for _, borrower := range batch {
+					result, err := liquidationFromTxResponse(res, borrower.String())
+					if err != nil {
+						liquidationFailed(logger, tracker, metrics, borrower.String(), err)
+						continue
+					}
+
+					tracker.Succeeded(result.Borrower)
+					metrics.LiquidationsSucceeded.Inc()
+					logger.Info().
+						Str("borrower", result.Borrower).
+						Str("tx_hash", result.TxHash).
+						Int64("height", result.Height).
+						Str("liquidated", result.LiquidatedCoins.String()).
+						Str("keeper_reward", result.KeeperRewardCoins.String()).
+						Msg("liquidation confirmed")
+				}
+			}(response.Result.TxHash)
 		}
 	}()
 
+	// price updates trigger a check of the cached positions between full
+	// scans, they are watched over the rpc websocket
+	var priceUpdates <-chan struct{}
+	if http != nil {
+		watcher := NewPriceEventWatcher(http, logger)
+		if err := watcher.Run(context.Background()); err != nil {
+			logger.Warn().Err(err).Msg("failed to watch price updates, checking every interval")
+		} else {
+			priceUpdates = watcher.Triggers()
+		}
+	}
+
+	var data *PositionData
+	var nextScan time.Time
 	for {
-		// fetch asset and position data using client
-		data, err := GetPositionData(liquidationClient)
-		if err != nil {
-			log.Println(err)
-			continue
+		scanStart := time.Now()
+		if data == nil || !time.Now().Before(nextScan) {
+			// fetch asset and position data using client
+			scanned, err := GetPositionData(logger, liquidationClient)
+			if err != nil {
+				logger.Error().Err(err).Msg("failed to fetch position data")
+				continue
+			}
+			data = scanned
+			nextScan = time.Now().Add(config.KavaLiquidationInterval)
+		} else {
+			// only prices changed since the last scan
+			refreshed, err := RefreshPrices(logger, liquidationClient, data)
+			if err != nil {
+				logger.Error().Err(err).Msg("failed to refresh prices")
+				waitForCheck(priceUpdates, time.Until(nextScan))
+				continue
+			}
+			data = refreshed
+		}
+
+		if len(data.Unpriced) > 0 {
+			logger.Warn().
+				Strs("unpriced", data.Unpriced).
+				Int("skipped_positions", data.UnpricedPositions()).
+				Msg("markets without a price, skipping positions that depend on them")
 		}
 
 		// calculate borrowers to liquidate from asset and position data
 		borrowersToLiquidate := GetBorrowersToLiquidate(data)
-		fmt.Printf("%d borrowers to liquidate\n", len(borrowersToLiquidate))
+		logger.Info().
+			Int64("height", data.Height).
+			Int("positions", len(data.Positions)).
+			Int("liquidatable", len(borrowersToLiquidate)).
+			Msg("positions checked")
 
-		// create liquidation msgs
-		msgs := CreateLiquidationMsgs(config.KavaKeeperAddress, borrowersToLiquidate)
+		scanEnd := time.Now()
+		metrics.ObserveScan(data, len(borrowersToLiquidate), scanStart, scanEnd)
+		scanState.Update(data, scanEnd)
 
-		// create liquidation transactions
-		for _, msg := range msgs {
-			fmt.Printf("sending liquidation for %s\n", msg.Borrower)
+		// failed updates keep the previous values, fees are still priced from
+		// the base gas price if the node does not support the queries
+		if config.FeeNodeMinGasPrices {
+			if err := feePolicy.UpdateMinGasPrices(context.Background(), conn); err != nil {
+				logger.Warn().Err(err).Msg("failed to update node minimum gas prices")
+			}
+		}
+		if http != nil {
+			if err := feePolicy.UpdateCongestion(context.Background(), http); err != nil {
+				logger.Warn().Err(err).Msg("failed to update mempool congestion")
+			}
+		}
+
+		// skip liquidations that cost more in fees than they are rewarded,
+		// liquidating everything if they cannot be valued
+		fee := func(borrower sdk.AccAddress) sdk.Coins {
+			return feePolicy.Fee(liquidationGasLimit, feeRetries.Retries(borrower.String()))
+		}
+		profitable, skipped, err := GetProfitableBorrowers(data, borrowersToLiquidate, fee, config.Profit)
+		if err != nil {
+			logger.Warn().Err(err).Msg("failed to estimate liquidation profit, liquidating all borrowers")
+			profitable = borrowersToLiquidate
+		}
+		for _, estimate := range skipped {
+			logger.Info().
+				Str("borrower", estimate.Borrower.String()).
+				Str("reward_usd", estimate.RewardUSD.String()).
+				Str("fee_usd", estimate.FeeUSD.String()).
+				Msg("skipping unprofitable liquidation")
+		}
 
-			requests <- signing.MsgRequest{
-				Msgs:      []sdk.Msg{&msg},
-				GasLimit:  1000000,
-				FeeAmount: sdk.Coins{sdk.Coin{Denom: "ukava", Amount: sdk.NewInt(50000)}},
-				Memo:      "",
+		// liquidate the most urgent borrowers first
+		profitable = SortBorrowers(data, profitable, config.Batch.Order)
+
+		var ready Borrowers
+		for _, borrower := range profitable {
+			if !tracker.Ready(borrower.String(), time.Now()) {
+				logger.Debug().Str("borrower", borrower.String()).Msg("skipping liquidation in flight or on cooldown")
+				continue
 			}
+			ready = append(ready, borrower)
+		}
+
+		// drop liquidations that fail when simulated, sending all of them if
+		// they cannot be simulated
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		simulated, failures, err := SimulateLiquidations(ctx, simulator, ready)
+		cancel()
+		if err != nil {
+			logger.Warn().Err(err).Msg("failed to simulate liquidations, sending all borrowers")
+			simulated = ready
+		}
+		for _, failure := range failures {
+			logger.Info().
+				Err(failure.Err).
+				Str("borrower", failure.Borrower.String()).
+				Msg("skipping liquidation that failed simulation")
+		}
+
+		// create liquidation transactions of up to the batch size
+		for _, batch := range BatchBorrowers(simulated, config.Batch.Size) {
+			for _, borrower := range batch {
+				logger.Info().Str("borrower", borrower.String()).Msg("sending liquidation")
+				tracker.Sent(borrower.String())
+			}
+			metrics.LiquidationsSent.Add(float64(len(batch)))
+
+			requests <- liquidationRequest(batch)
+		}
+
+		// wait for a price update or the next full scan
+		waitForCheck(priceUpdates, time.Until(nextScan))
+	}
+}
+
+// liquidationFailed records a failed liquidation and logs whether the borrower
+// was put on cooldown
+func liquidationFailed(logger zerolog.Logger, tracker *LiquidationTracker, metrics *Metrics, borrower string, err error) {
+	cooldownUntil := tracker.Failed(borrower, time.Now())
+	metrics.LiquidationsFailed.Inc()
+
+	event := logger.Warn().
+		Err(err).
+		Str("borrower", borrower).
+		Int("failures", tracker.Failures(borrower))
+	if !cooldownUntil.IsZero() {
+		event = event.Time("cooldown_until", cooldownUntil)
+	}
+	event.Msg("liquidation failed")
+}
+
+// newLiquidationClient returns the configured LiquidationClient, http must be
+// set for the rpc query client
+func newLiquidationClient(
+	queryClient QueryClient,
+	conn *grpc.ClientConn,
+	http *rpchttpclient.HTTP,
+	cdc *codec.LegacyAmino,
+) LiquidationClient {
+	if queryClient == RpcQueryClient {
+		return NewRpcLiquidationClient(http, cdc)
+	}
+
+	return NewGrpcLiquidationClient(
+		tmservice.NewServiceClient(conn),
+		hardtypes.NewQueryClient(conn),
+		pricefeedtypes.NewQueryClient(conn),
+	)
+}
+
+// runRisk writes a report of the positions over their borrow limit under each
+// configured price shock as csv and json
+func runRisk(logger zerolog.Logger, cdc *codec.LegacyAmino) error {
+	config, err := LoadRiskConfig(&EnvLoader{})
+	if err != nil {
+		return err
+	}
+
+	conn, err := kavagrpc.NewGrpcConnection(config.KavaGrpcUrl)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var http *rpchttpclient.HTTP
+	if config.KavaRpcUrl != "" {
+		http, err = rpchttpclient.New(config.KavaRpcUrl, "/websocket")
+		if err != nil {
+			return err
 		}
+	}
 
-		// wait for next interval
-		time.Sleep(config.KavaLiquidationInterval)
+	data, err := GetPositionData(logger, newLiquidationClient(config.KavaQueryClient, conn, http, cdc))
+	if err != nil {
+		return err
 	}
+
+	report := GetRiskReport(data, config.PriceShocks)
+
+	prefix := filepath.Join(config.OutputDir, fmt.Sprintf("hard_risk_%d", report.Height))
+
+	csvFile, err := os.Create(prefix + ".csv")
+	if err != nil {
+		return err
+	}
+	defer csvFile.Close()
+	if err := report.WriteCsv(csvFile); err != nil {
+		return fmt.Errorf("failed to write csv: %w", err)
+	}
+
+	jsonFile, err := os.Create(prefix + ".json")
+	if err != nil {
+		return err
+	}
+	defer jsonFile.Close()
+	if err := report.WriteJson(jsonFile); err != nil {
+		return fmt.Errorf("failed to write json: %w", err)
+	}
+
+	for _, scenario := range report.Scenarios {
+		fmt.Printf(
+			"shock %s: %d positions at risk, %s USD shortfall, %s auctioned\n",
+			scenario.Shock, len(scenario.Positions), scenario.TotalShortfallUSD, scenario.AuctionedCollateral,
+		)
+	}
+	fmt.Printf("wrote %s.csv and %s.json\n", prefix, prefix)
+
+	return nil
 }
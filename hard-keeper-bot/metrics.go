@@ -0,0 +1,90 @@
+package main
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "hard_keeper"
+
+// Metrics are the prometheus metrics of scans and liquidations
+type Metrics struct {
+	PositionsScanned      prometheus.Gauge
+	LiquidatablePositions prometheus.Gauge
+	UnpricedMarkets       prometheus.Gauge
+	LiquidationsSent      prometheus.Counter
+	LiquidationsSucceeded prometheus.Counter
+	LiquidationsFailed    prometheus.Counter
+	ScanDuration          prometheus.Histogram
+	LastScanTimestampSecs prometheus.Gauge
+}
+
+// NewMetrics creates the metrics and registers them with registerer
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		PositionsScanned: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "positions_scanned",
+			Help:      "Number of borrow positions in the latest scan.",
+		}),
+		LiquidatablePositions: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "liquidatable_positions",
+			Help:      "Number of positions over their borrow limit in the latest scan.",
+		}),
+		UnpricedMarkets: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "unpriced_markets",
+			Help:      "Number of money markets without a price in the latest scan.",
+		}),
+		LiquidationsSent: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "liquidations_sent_total",
+			Help:      "Liquidations sent to the signer.",
+		}),
+		LiquidationsSucceeded: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "liquidations_succeeded_total",
+			Help:      "Liquidations confirmed by their hard_liquidation event.",
+		}),
+		LiquidationsFailed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "liquidations_failed_total",
+			Help:      "Liquidations that failed to broadcast or to liquidate.",
+		}),
+		ScanDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "scan_duration_seconds",
+			Help:      "Time to fetch and check positions.",
+			Buckets:   prometheus.ExponentialBuckets(0.1, 2, 10),
+		}),
+		LastScanTimestampSecs: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "last_scan_timestamp_seconds",
+			Help:      "Unix time of the latest completed scan.",
+		}),
+	}
+
+	registerer.MustRegister(
+		m.PositionsScanned,
+		m.LiquidatablePositions,
+		m.UnpricedMarkets,
+		m.LiquidationsSent,
+		m.LiquidationsSucceeded,
+		m.LiquidationsFailed,
+		m.ScanDuration,
+		m.LastScanTimestampSecs,
+	)
+
+	return m
+}
+
+// ObserveScan records a completed scan that started at start
+func (m *Metrics) ObserveScan(data *PositionData, liquidatable int, start, end time.Time) {
+	m.PositionsScanned.Set(float64(len(data.Positions)))
+	m.LiquidatablePositions.Set(float64(liquidatable))
+	m.UnpricedMarkets.Set(float64(len(data.Unpriced)))
+	m.ScanDuration.Observe(end.Sub(start).Seconds())
+	m.LastScanTimestampSecs.Set(float64(end.Unix()))
+}
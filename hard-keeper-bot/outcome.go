@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	hardtypes "github.com/kava-labs/kava/x/hard/types"
+	"google.golang.org/grpc"
+)
+
+// CooldownConfig sets when borrowers with failing liquidations stop being
+// retried
+type CooldownConfig struct {
+	// MaxFailures is the number of consecutive failed attempts before a
+	// borrower is put on cooldown
+	MaxFailures int
+	// Cooldown is the first cooldown, doubled for every further failure
+	Cooldown time.Duration
+	// MaxCooldown caps the cooldown
+	MaxCooldown time.Duration
+}
+
+// Validate returns an error if a borrower could be put on cooldown forever or
+// never leave it
+func (c CooldownConfig) Validate() error {
+	if c.MaxFailures <= 0 {
+		return fmt.Errorf("max failures must be positive, got %d", c.MaxFailures)
+	}
+	if c.Cooldown <= 0 {
+		return fmt.Errorf("cooldown must be positive, got %s", c.Cooldown)
+	}
+	if c.MaxCooldown < c.Cooldown {
+		return fmt.Errorf("max cooldown %s must be at least cooldown %s", c.MaxCooldown, c.Cooldown)
+	}
+
+	return nil
+}
+
+// LiquidationResult is a liquidation confirmed by its hard_liquidation event
+type LiquidationResult struct {
+	Borrower          string
+	TxHash            string
+	Height            int64
+	LiquidatedCoins   sdk.Coins
+	KeeperRewardCoins sdk.Coins
+}
+
+type borrowerAttempts struct {
+	inflight      bool
+	failures      int
+	cooldownUntil time.Time
+}
+
+// LiquidationTracker tracks liquidation attempts by borrower, so a borrower is
+// not sent again while a liquidation is in flight and borrowers that keep
+// failing are put on cooldown
+//
+// LiquidationTracker is safe for concurrent use.
+type LiquidationTracker struct {
+	config CooldownConfig
+
+	mu       sync.Mutex
+	borrower map[string]*borrowerAttempts
+}
+
+func NewLiquidationTracker(config CooldownConfig) *LiquidationTracker {
+	return &LiquidationTracker{
+		config:   config,
+		borrower: make(map[string]*borrowerAttempts),
+	}
+}
+
+// Ready returns true if a liquidation of the borrower may be sent
+func (t *LiquidationTracker) Ready(borrower string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	attempts, found := t.borrower[borrower]
+	if !found {
+		return true
+	}
+
+	return !attempts.inflight && !now.Before(attempts.cooldownUntil)
+}
+
+// Sent records a liquidation of the borrower sent to the signer
+func (t *LiquidationTracker) Sent(borrower string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.attempts(borrower).inflight = true
+}
+
+// Failed records a failed liquidation of the borrower and returns the time
+// its cooldown ends, which is zero if it is not on cooldown
+func (t *LiquidationTracker) Failed(borrower string, now time.Time) time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	attempts := t.attempts(borrower)
+	attempts.inflight = false
+	attempts.failures++
+
+	if attempts.failures < t.config.MaxFailures {
+		return time.Time{}
+	}
+
+	cooldown := t.config.Cooldown
+	for i := t.config.MaxFailures; i < attempts.failures && cooldown < t.config.MaxCooldown; i++ {
+		cooldown *= 2
+	}
+	if cooldown > t.config.MaxCooldown {
+		cooldown = t.config.MaxCooldown
+	}
+	attempts.cooldownUntil = now.Add(cooldown)
+
+	return attempts.cooldownUntil
+}
+
+// Succeeded clears the attempts of a borrower that was liquidated
+func (t *LiquidationTracker) Succeeded(borrower string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.borrower, borrower)
+}
+
+// Failures returns the consecutive failed attempts of the borrower
+func (t *LiquidationTracker) Failures(borrower string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if attempts, found := t.borrower[borrower]; found {
+		return attempts.failures
+	}
+	return 0
+}
+
+func (t *LiquidationTracker) attempts(borrower string) *borrowerAttempts {
+	attempts, found := t.borrower[borrower]
+	if !found {
+		attempts = &borrowerAttempts{}
+		t.borrower[borrower] = attempts
+	}
+
+	return attempts
+}
+
+// TxClient fetches delivered txs, it is satisfied by the tx service client
+type TxClient interface {
+	GetTx(ctx context.Context, in *txtypes.GetTxRequest, opts ...grpc.CallOption) (*txtypes.GetTxResponse, error)
+}
+
+// confirmAttempts and confirmDelay bound how long a committed tx may be
+// missing from the tx index
+var (
+	confirmAttempts = 5
+	confirmDelay    = 2 * time.Second
+)
+
+// ConfirmLiquidation fetches a delivered liquidation tx and returns the
+// liquidation of the borrower from its events, or an error if the tx failed
+// or did not liquidate the borrower
+func ConfirmLiquidation(ctx context.Context, client TxClient, txHash string, borrower string) (LiquidationResult, error) {
+	res, err := FetchTx(ctx, client, txHash)
+	if err != nil {
+		return LiquidationResult{}, err
+	}
+
+	return liquidationFromTxResponse(res, borrower)
+}
+
+// FetchTx fetches a delivered tx, retrying while it is missing from the tx
+// index
+func FetchTx(ctx context.Context, client TxClient, txHash string) (*sdk.TxResponse, error) {
+	var res *txtypes.GetTxResponse
+	var err error
+
+	// the signer responds once the tx is committed, which can be before the
+	// node has indexed it
+	for attempt := 1; ; attempt++ {
+		res, err = client.GetTx(ctx, &txtypes.GetTxRequest{Hash: txHash})
+		if err == nil || attempt == confirmAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(confirmDelay):
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tx %s: %w", txHash, err)
+	}
+
+	return res.TxResponse, nil
+}
+
+func liquidationFromTxResponse(res *sdk.TxResponse, borrower string) (LiquidationResult, error) {
+	if res.Code != 0 {
+		return LiquidationResult{}, fmt.Errorf("tx %s failed with code %d: %s", res.TxHash, res.Code, res.RawLog)
+	}
+
+	for _, log := range res.Logs {
+		for _, event := range log.Events {
+			if event.Type != hardtypes.EventTypeHardLiquidation {
+				continue
+			}
+
+			// attributes of events of the same type are flattened into one
+			// event, so each liquidation starts at its owner attribute
+			var result *LiquidationResult
+			var err error
+			for _, attr := range event.Attributes {
+				switch attr.Key {
+				case hardtypes.AttributeKeyLiquidatedOwner:
+					if result != nil {
+						return *result, nil
+					}
+					if attr.Value == borrower {
+						result = &LiquidationResult{Borrower: borrower, TxHash: res.TxHash, Height: res.Height}
+					}
+				case hardtypes.AttributeKeyLiquidatedCoins:
+					if result != nil {
+						result.LiquidatedCoins, err = sdk.ParseCoinsNormalized(attr.Value)
+					}
+				case hardtypes.AttributeKeyKeeperRewardCoins:
+					if result != nil {
+						result.KeeperRewardCoins, err = sdk.ParseCoinsNormalized(attr.Value)
+					}
+				}
+				if err != nil {
+					return LiquidationResult{}, fmt.Errorf("invalid %s attribute: %w", attr.Key, err)
+				}
+			}
+			if result != nil {
+				return *result, nil
+			}
+		}
+	}
+
+	return LiquidationResult{}, fmt.Errorf("tx %s did not liquidate %s", res.TxHash, borrower)
+}
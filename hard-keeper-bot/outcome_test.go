@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+type mockTxClient struct {
+	// responses are returned in order, nil responses return a not found error
+	responses []*sdk.TxResponse
+	calls     int
+}
+
+func (c *mockTxClient) GetTx(ctx context.Context, in *txtypes.GetTxRequest, opts ...grpc.CallOption) (*txtypes.GetTxResponse, error) {
+	res := c.responses[c.calls]
+	c.calls++
+
+	if res == nil {
+		return nil, errors.New("tx not found")
+	}
+	return &txtypes.GetTxResponse{TxResponse: res}, nil
+}
+
+func liquidationEvent(borrowers ...string) sdk.StringEvent {
+	event := sdk.StringEvent{Type: "hard_liquidation"}
+	for _, borrower := range borrowers {
+		event.Attributes = append(event.Attributes,
+			sdk.Attribute{Key: "liquidated_owner", Value: borrower},
+			sdk.Attribute{Key: "liquidated_coins", Value: "100bnb"},
+			sdk.Attribute{Key: "keeper", Value: "kava1keeper"},
+			sdk.Attribute{Key: "keeper_reward_coins", Value: "2bnb"},
+		)
+	}
+	return event
+}
+
+func TestCooldownConfigValidate(t *testing.T) {
+	config := CooldownConfig{MaxFailures: 2, Cooldown: time.Minute, MaxCooldown: time.Hour}
+	assert.NoError(t, config.Validate())
+
+	invalid := config
+	invalid.MaxFailures = 0
+	assert.Error(t, invalid.Validate())
+
+	invalid = config
+	invalid.Cooldown = 0
+	assert.Error(t, invalid.Validate())
+
+	invalid = config
+	invalid.MaxCooldown = time.Second
+	assert.Error(t, invalid.Validate())
+}
+
+func TestLiquidationTracker(t *testing.T) {
+	tracker := NewLiquidationTracker(CooldownConfig{MaxFailures: 2, Cooldown: 10 * time.Minute, MaxCooldown: 30 * time.Minute})
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.True(t, tracker.Ready("kava1a", now))
+
+	// not resent while in flight
+	tracker.Sent("kava1a")
+	assert.False(t, tracker.Ready("kava1a", now))
+	assert.True(t, tracker.Ready("kava1b", now))
+
+	// retried after the first failure
+	assert.True(t, tracker.Failed("kava1a", now).IsZero())
+	assert.True(t, tracker.Ready("kava1a", now))
+
+	// cooldown doubles for every failure after the max, up to the max cooldown
+	tracker.Sent("kava1a")
+	assert.Equal(t, now.Add(10*time.Minute), tracker.Failed("kava1a", now))
+	assert.False(t, tracker.Ready("kava1a", now.Add(9*time.Minute)))
+	assert.True(t, tracker.Ready("kava1a", now.Add(10*time.Minute)))
+
+	assert.Equal(t, now.Add(20*time.Minute), tracker.Failed("kava1a", now))
+	assert.Equal(t, now.Add(30*time.Minute), tracker.Failed("kava1a", now))
+	assert.Equal(t, now.Add(30*time.Minute), tracker.Failed("kava1a", now))
+	assert.Equal(t, 5, tracker.Failures("kava1a"))
+
+	// a liquidation clears past failures
+	tracker.Succeeded("kava1a")
+	assert.True(t, tracker.Ready("kava1a", now))
+	assert.Equal(t, 0, tracker.Failures("kava1a"))
+	assert.True(t, tracker.Failed("kava1a", now).IsZero())
+}
+
+func TestConfirmLiquidation(t *testing.T) {
+	confirmDelay = time.Millisecond
+	defer func() { confirmDelay = 2 * time.Second }()
+
+	delivered := &sdk.TxResponse{
+		TxHash: "ABCD",
+		Height: 100,
+		Logs: sdk.ABCIMessageLogs{
+			{Events: sdk.StringEvents{{Type: "message"}, liquidationEvent("kava1other", "kava1a")}},
+		},
+	}
+
+	// retried until the tx is indexed
+	client := &mockTxClient{responses: []*sdk.TxResponse{nil, nil, delivered}}
+	result, err := ConfirmLiquidation(context.Background(), client, "ABCD", "kava1a")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, client.calls)
+	assert.Equal(t, LiquidationResult{
+		Borrower:          "kava1a",
+		TxHash:            "ABCD",
+		Height:            100,
+		LiquidatedCoins:   sdk.NewCoins(sdk.NewInt64Coin("bnb", 100)),
+		KeeperRewardCoins: sdk.NewCoins(sdk.NewInt64Coin("bnb", 2)),
+	}, result)
+
+	// delivered without liquidating the borrower
+	client = &mockTxClient{responses: []*sdk.TxResponse{delivered}}
+	_, err = ConfirmLiquidation(context.Background(), client, "ABCD", "kava1b")
+	assert.EqualError(t, err, "tx ABCD did not liquidate kava1b")
+
+	// failed deliver tx
+	client = &mockTxClient{responses: []*sdk.TxResponse{{TxHash: "ABCD", Code: 11, RawLog: "out of gas"}}}
+	_, err = ConfirmLiquidation(context.Background(), client, "ABCD", "kava1a")
+	assert.EqualError(t, err, "tx ABCD failed with code 11: out of gas")
+
+	// never indexed
+	client = &mockTxClient{responses: make([]*sdk.TxResponse, confirmAttempts)}
+	_, err = ConfirmLiquidation(context.Background(), client, "ABCD", "kava1a")
+	assert.EqualError(t, err, "failed to fetch tx ABCD: tx not found")
+	assert.Equal(t, confirmAttempts, client.calls)
+}
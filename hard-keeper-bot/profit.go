@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// ProfitConfig sets which liquidations are worth the fee to send them
+type ProfitConfig struct {
+	// MinProfit is the minimum USD value of the keeper reward over the fee
+	MinProfit sdk.Dec
+	// ProtocolSafety liquidates every borrower over the limit, regardless of
+	// profit, to keep bad debt out of the protocol
+	ProtocolSafety bool
+}
+
+// Validate returns an error if the minimum profit is not set
+func (c ProfitConfig) Validate() error {
+	if c.MinProfit.IsNil() {
+		return fmt.Errorf("min profit must be set")
+	}
+
+	return nil
+}
+
+// LiquidationEstimate is the expected keeper reward and fee of a liquidation
+// in USD
+type LiquidationEstimate struct {
+	Borrower  sdk.AccAddress
+	RewardUSD sdk.Dec
+	FeeUSD    sdk.Dec
+}
+
+// Profit returns the keeper reward less the fee
+func (e LiquidationEstimate) Profit() sdk.Dec {
+	return e.RewardUSD.Sub(e.FeeUSD)
+}
+
+// EstimateLiquidation values the keeper reward of liquidating a position and
+// the fee paid to send it
+//
+// The hard module pays the keeper the KeeperRewardPercentage of each deposit
+// coin. Deposits without a market are not rewarded, and an error is returned
+// if the fee cannot be valued.
+func EstimateLiquidation(assets map[string]AssetInfo, position Position, fee sdk.Coins) (LiquidationEstimate, error) {
+	estimate := LiquidationEstimate{
+		Borrower:  position.Address,
+		RewardUSD: sdk.ZeroDec(),
+		FeeUSD:    sdk.ZeroDec(),
+	}
+
+	for _, deposit := range position.DepositedAmount {
+		asset, ok := assets[deposit.Denom]
+		if !ok || asset.KeeperRewardPercentage.IsNil() {
+			continue
+		}
+
+		// rewards are truncated as they are by the hard module
+		reward := asset.KeeperRewardPercentage.MulInt(deposit.Amount).TruncateInt()
+		estimate.RewardUSD = estimate.RewardUSD.Add(usdValue(asset, reward))
+	}
+
+	for _, coin := range fee {
+		asset, ok := assets[coin.Denom]
+		if !ok {
+			return LiquidationEstimate{}, fmt.Errorf("no price for fee denom %s", coin.Denom)
+		}
+
+		estimate.FeeUSD = estimate.FeeUSD.Add(usdValue(asset, coin.Amount))
+	}
+
+	return estimate, nil
+}
+
+// GetProfitableBorrowers returns the borrowers whose liquidation is expected
+// to profit at least the configured minimum, and the estimates of those that
+// are skipped. Every borrower is returned in protocol safety mode.
+func GetProfitableBorrowers(
+	data *PositionData,
+	borrowers Borrowers,
+	fee func(borrower sdk.AccAddress) sdk.Coins,
+	config ProfitConfig,
+) (Borrowers, []LiquidationEstimate, error) {
+	if config.ProtocolSafety {
+		return borrowers, nil, nil
+	}
+
+	positions := make(map[string]Position)
+	for _, pos := range data.Positions {
+		positions[pos.Address.String()] = pos
+	}
+
+	profitable := Borrowers{}
+	var skipped []LiquidationEstimate
+
+	for _, borrower := range borrowers {
+		pos, ok := positions[borrower.String()]
+		if !ok {
+			return nil, nil, fmt.Errorf("no position for borrower %s", borrower)
+		}
+
+		estimate, err := EstimateLiquidation(data.Assets, pos, fee(borrower))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if estimate.Profit().LT(config.MinProfit) {
+			skipped = append(skipped, estimate)
+			continue
+		}
+
+		profitable = append(profitable, borrower)
+	}
+
+	return profitable, skipped, nil
+}
+
+func usdValue(asset AssetInfo, amount sdk.Int) sdk.Dec {
+	return sdk.NewDecFromInt(amount).Quo(sdk.NewDecFromInt(asset.ConversionFactor)).Mul(asset.Price)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/tendermint/crypto"
+)
+
+func testProfitData() *PositionData {
+	return &PositionData{
+		Assets: map[string]AssetInfo{
+			"busd": {
+				Price:                  sdk.MustNewDecFromStr("1.0"),
+				LoanToValueRatio:       sdk.MustNewDecFromStr("0.5"),
+				ConversionFactor:       sdk.NewInt(100000000),
+				KeeperRewardPercentage: sdk.MustNewDecFromStr("0.02"),
+			},
+			"ukava": {
+				Price:                  sdk.MustNewDecFromStr("2.0"),
+				LoanToValueRatio:       sdk.MustNewDecFromStr("0.5"),
+				ConversionFactor:       sdk.NewInt(1000000),
+				KeeperRewardPercentage: sdk.MustNewDecFromStr("0.05"),
+			},
+		},
+		Positions: []Position{
+			{
+				// rewarded 2 busd and 1 kava
+				Address:         sdk.AccAddress(crypto.AddressHash([]byte("large"))),
+				BorrowedAmount:  sdk.NewCoins(sdk.NewInt64Coin("busd", 10000000000)),
+				DepositedAmount: sdk.NewCoins(sdk.NewInt64Coin("busd", 10000000000), sdk.NewInt64Coin("ukava", 20000000)),
+			},
+			{
+				// rewarded 0.1 busd
+				Address:         sdk.AccAddress(crypto.AddressHash([]byte("small"))),
+				BorrowedAmount:  sdk.NewCoins(sdk.NewInt64Coin("busd", 1000000000)),
+				DepositedAmount: sdk.NewCoins(sdk.NewInt64Coin("busd", 500000000)),
+			},
+		},
+	}
+}
+
+func TestEstimateLiquidation(t *testing.T) {
+	data := testProfitData()
+
+	// 0.05 kava fee
+	estimate, err := EstimateLiquidation(data.Assets, data.Positions[0], sdk.NewCoins(sdk.NewInt64Coin("ukava", 50000)))
+	assert.NoError(t, err)
+	assert.Equal(t, sdk.MustNewDecFromStr("4.0"), estimate.RewardUSD)
+	assert.Equal(t, sdk.MustNewDecFromStr("0.1"), estimate.FeeUSD)
+	assert.Equal(t, sdk.MustNewDecFromStr("3.9"), estimate.Profit())
+
+	// deposits without a market are not rewarded
+	position := data.Positions[1]
+	position.DepositedAmount = position.DepositedAmount.Add(sdk.NewInt64Coin("unknown", 1000000))
+	estimate, err = EstimateLiquidation(data.Assets, position, sdk.NewCoins(sdk.NewInt64Coin("ukava", 50000)))
+	assert.NoError(t, err)
+	assert.Equal(t, sdk.MustNewDecFromStr("0.1"), estimate.RewardUSD)
+	assert.True(t, estimate.Profit().IsZero())
+
+	_, err = EstimateLiquidation(data.Assets, data.Positions[0], sdk.NewCoins(sdk.NewInt64Coin("uatom", 50000)))
+	assert.EqualError(t, err, "no price for fee denom uatom")
+}
+
+func TestGetProfitableBorrowers(t *testing.T) {
+	data := testProfitData()
+	large, small := data.Positions[0].Address, data.Positions[1].Address
+	borrowers := Borrowers{large, small}
+
+	// 0.1 kava fee, more than the reward of the small position
+	fee := func(borrower sdk.AccAddress) sdk.Coins {
+		return sdk.NewCoins(sdk.NewInt64Coin("ukava", 100000))
+	}
+
+	config := ProfitConfig{MinProfit: sdk.ZeroDec()}
+	assert.NoError(t, config.Validate())
+
+	profitable, skipped, err := GetProfitableBorrowers(data, borrowers, fee, config)
+	assert.NoError(t, err)
+	assert.Equal(t, Borrowers{large}, profitable)
+	assert.Equal(t, []LiquidationEstimate{
+		{Borrower: small, RewardUSD: sdk.MustNewDecFromStr("0.1"), FeeUSD: sdk.MustNewDecFromStr("0.2")},
+	}, skipped)
+
+	// the min profit is over the profit of the large position
+	config.MinProfit = sdk.MustNewDecFromStr("4")
+	profitable, skipped, err = GetProfitableBorrowers(data, borrowers, fee, config)
+	assert.NoError(t, err)
+	assert.Equal(t, Borrowers{}, profitable)
+	assert.Len(t, skipped, 2)
+
+	// protocol safety mode liquidates everything
+	config.ProtocolSafety = true
+	profitable, skipped, err = GetProfitableBorrowers(data, borrowers, fee, config)
+	assert.NoError(t, err)
+	assert.Equal(t, borrowers, profitable)
+	assert.Empty(t, skipped)
+
+	config.ProtocolSafety = false
+	_, _, err = GetProfitableBorrowers(data, Borrowers{sdk.AccAddress(crypto.AddressHash([]byte("unknown")))}, fee, config)
+	assert.Error(t, err)
+
+	assert.Error(t, ProfitConfig{}.Validate())
+}
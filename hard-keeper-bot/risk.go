@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// PriceShock is a relative price move applied to each asset, -0.25 for a 25%
+// drop
+type PriceShock struct {
+	// Default is the shock of assets without their own
+	Default sdk.Dec
+	// Assets are the shocks of individual denoms
+	Assets map[string]sdk.Dec
+}
+
+// ParsePriceShocks parses scenarios separated by semicolons. Each scenario is
+// a comma separated list of shocks, either denom=shock for a single asset or
+// a bare shock for every other asset, for example "-0.25,busd=0,usdx=0".
+func ParsePriceShocks(raw string) ([]PriceShock, error) {
+	var shocks []PriceShock
+
+	for _, rawScenario := range strings.Split(raw, ";") {
+		shock := PriceShock{Default: sdk.ZeroDec(), Assets: make(map[string]sdk.Dec)}
+
+		for _, entry := range strings.Split(rawScenario, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+
+			denom, rawValue := "", entry
+			if parts := strings.SplitN(entry, "=", 2); len(parts) == 2 {
+				denom, rawValue = strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+			}
+
+			value, err := sdk.NewDecFromStr(rawValue)
+			if err != nil {
+				return nil, fmt.Errorf("invalid price shock %s: %w", entry, err)
+			}
+			if value.LTE(sdk.OneDec().Neg()) {
+				return nil, fmt.Errorf("price shock %s must be greater than -1", entry)
+			}
+
+			if denom == "" {
+				shock.Default = value
+			} else {
+				shock.Assets[denom] = value
+			}
+		}
+
+		shocks = append(shocks, shock)
+	}
+
+	return shocks, nil
+}
+
+// Shock returns the shock of a denom
+func (s PriceShock) Shock(denom string) sdk.Dec {
+	if shock, found := s.Assets[denom]; found {
+		return shock
+	}
+	return s.Default
+}
+
+// String formats the shock as it is parsed, with denoms in order
+func (s PriceShock) String() string {
+	entries := []string{s.Default.String()}
+
+	denoms := make([]string, 0, len(s.Assets))
+	for denom := range s.Assets {
+		denoms = append(denoms, denom)
+	}
+	sort.Strings(denoms)
+
+	for _, denom := range denoms {
+		entries = append(entries, fmt.Sprintf("%s=%s", denom, s.Assets[denom]))
+	}
+
+	return strings.Join(entries, ",")
+}
+
+// Apply returns assets with shocked prices
+func (s PriceShock) Apply(assets map[string]AssetInfo) map[string]AssetInfo {
+	shocked := make(map[string]AssetInfo, len(assets))
+	for denom, asset := range assets {
+		asset.Price = asset.Price.Mul(sdk.OneDec().Add(s.Shock(denom)))
+		shocked[denom] = asset
+	}
+
+	return shocked
+}
+
+// PositionRisk is a position that is over its borrow limit under a price shock
+type PositionRisk struct {
+	Borrower string `json:"borrower"`
+	// HealthFactor is the borrow limit over the borrowed value, positions
+	// under 1 can be liquidated
+	HealthFactor   sdk.Dec `json:"health_factor"`
+	BorrowedUSD    sdk.Dec `json:"borrowed_usd"`
+	BorrowLimitUSD sdk.Dec `json:"borrow_limit_usd"`
+	// ShortfallUSD is the borrowed value over the borrow limit
+	ShortfallUSD sdk.Dec `json:"shortfall_usd"`
+	// AuctionedCollateral is the deposit sent to auction after the keeper
+	// reward is paid
+	AuctionedCollateral sdk.Coins `json:"auctioned_collateral"`
+}
+
+// ScenarioRisk is the positions at risk under a single price shock
+type ScenarioRisk struct {
+	Shock     string         `json:"shock"`
+	Positions []PositionRisk `json:"positions"`
+	// TotalShortfallUSD and AuctionedCollateral sum all positions at risk
+	TotalShortfallUSD   sdk.Dec   `json:"total_shortfall_usd"`
+	AuctionedCollateral sdk.Coins `json:"auctioned_collateral"`
+}
+
+// RiskReport is the positions at risk at a height under each price shock
+type RiskReport struct {
+	Height    int64          `json:"height"`
+	Scenarios []ScenarioRisk `json:"scenarios"`
+}
+
+// RiskReportHeaders are the columns of RiskReport.ToRecords
+var RiskReportHeaders = []string{
+	"Shock",
+	"Borrower",
+	"Health Factor",
+	"Borrowed USD",
+	"Borrow Limit USD",
+	"Shortfall USD",
+	"Auctioned Collateral",
+}
+
+// GetRiskReport returns the positions that are over their borrow limit under
+// each price shock, with the largest shortfall first. Positions holding an
+// asset without a price are left out.
+func GetRiskReport(data *PositionData, shocks []PriceShock) RiskReport {
+	report := RiskReport{Height: data.Height}
+
+	for _, shock := range shocks {
+		assets := shock.Apply(data.Assets)
+		scenario := ScenarioRisk{
+			Shock:               shock.String(),
+			Positions:           []PositionRisk{},
+			TotalShortfallUSD:   sdk.ZeroDec(),
+			AuctionedCollateral: sdk.NewCoins(),
+		}
+
+		for _, pos := range data.Positions {
+			if !data.Priced(pos) {
+				continue
+			}
+
+			borrowLimit, borrowed := GetPositionUSDValues(assets, pos)
+			if !borrowed.GT(borrowLimit) {
+				continue
+			}
+
+			risk := PositionRisk{
+				Borrower:            pos.Address.String(),
+				HealthFactor:        borrowLimit.Quo(borrowed),
+				BorrowedUSD:         borrowed,
+				BorrowLimitUSD:      borrowLimit,
+				ShortfallUSD:        borrowed.Sub(borrowLimit),
+				AuctionedCollateral: auctionedCollateral(assets, pos),
+			}
+
+			scenario.Positions = append(scenario.Positions, risk)
+			scenario.TotalShortfallUSD = scenario.TotalShortfallUSD.Add(risk.ShortfallUSD)
+			scenario.AuctionedCollateral = scenario.AuctionedCollateral.Add(risk.AuctionedCollateral...)
+		}
+
+		sort.SliceStable(scenario.Positions, func(i, j int) bool {
+			return scenario.Positions[i].ShortfallUSD.GT(scenario.Positions[j].ShortfallUSD)
+		})
+
+		report.Scenarios = append(report.Scenarios, scenario)
+	}
+
+	return report
+}
+
+// auctionedCollateral returns the deposits of a position less the keeper
+// reward, which the hard module sends to auction on liquidation
+func auctionedCollateral(assets map[string]AssetInfo, pos Position) sdk.Coins {
+	collateral := sdk.NewCoins()
+
+	for _, deposit := range pos.DepositedAmount {
+		amount := deposit.Amount
+		if asset, ok := assets[deposit.Denom]; ok && !asset.KeeperRewardPercentage.IsNil() {
+			amount = amount.Sub(asset.KeeperRewardPercentage.MulInt(amount).TruncateInt())
+		}
+
+		collateral = collateral.Add(sdk.NewCoin(deposit.Denom, amount))
+	}
+
+	return collateral
+}
+
+// ToRecords converts the report to a row for each position at risk in each
+// scenario
+func (r RiskReport) ToRecords() [][]string {
+	var records [][]string
+
+	for _, scenario := range r.Scenarios {
+		for _, pos := range scenario.Positions {
+			records = append(records, []string{
+				scenario.Shock,
+				pos.Borrower,
+				pos.HealthFactor.String(),
+				pos.BorrowedUSD.String(),
+				pos.BorrowLimitUSD.String(),
+				pos.ShortfallUSD.String(),
+				pos.AuctionedCollateral.String(),
+			})
+		}
+	}
+
+	return records
+}
+
+// WriteCsv writes the report with a header row
+func (r RiskReport) WriteCsv(destination io.Writer) error {
+	w := csv.NewWriter(destination)
+	if err := w.Write(RiskReportHeaders); err != nil {
+		return err
+	}
+
+	// Flushes internally
+	if err := w.WriteAll(r.ToRecords()); err != nil {
+		return err
+	}
+
+	return w.Error()
+}
+
+// WriteJson writes the report as indented json
+func (r RiskReport) WriteJson(destination io.Writer) error {
+	encoder := json.NewEncoder(destination)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(r)
+}
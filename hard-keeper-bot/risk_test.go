@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/tendermint/crypto"
+)
+
+func TestParsePriceShocks(t *testing.T) {
+	shocks, err := ParsePriceShocks("0; -0.25, busd=0 ,usdx=0.01;bnb=-0.5")
+	assert.NoError(t, err)
+	assert.Len(t, shocks, 3)
+
+	assert.Equal(t, "0.000000000000000000", shocks[0].String())
+	assert.True(t, shocks[0].Shock("bnb").IsZero())
+
+	assert.Equal(t, sdk.MustNewDecFromStr("-0.25"), shocks[1].Shock("bnb"))
+	assert.True(t, shocks[1].Shock("busd").IsZero())
+	assert.Equal(t, sdk.MustNewDecFromStr("0.01"), shocks[1].Shock("usdx"))
+	assert.Equal(t, "-0.250000000000000000,busd=0.000000000000000000,usdx=0.010000000000000000", shocks[1].String())
+
+	// assets without a shock keep their price
+	assert.Equal(t, sdk.MustNewDecFromStr("-0.5"), shocks[2].Shock("bnb"))
+	assert.True(t, shocks[2].Shock("busd").IsZero())
+
+	_, err = ParsePriceShocks("-0.1;bnb=down")
+	assert.Error(t, err)
+
+	_, err = ParsePriceShocks("-1")
+	assert.EqualError(t, err, "price shock -1 must be greater than -1")
+}
+
+func TestGetRiskReport(t *testing.T) {
+	healthy := sdk.AccAddress(crypto.AddressHash([]byte("healthy")))
+	risky := sdk.AccAddress(crypto.AddressHash([]byte("risky")))
+	underwater := sdk.AccAddress(crypto.AddressHash([]byte("underwater")))
+
+	data := &PositionData{
+		Height: 1001,
+		Assets: map[string]AssetInfo{
+			"busd": {
+				Price:                  sdk.MustNewDecFromStr("1.0"),
+				LoanToValueRatio:       sdk.MustNewDecFromStr("0.8"),
+				ConversionFactor:       sdk.NewInt(100000000),
+				KeeperRewardPercentage: sdk.MustNewDecFromStr("0.02"),
+			},
+			"bnb": {
+				Price:                  sdk.MustNewDecFromStr("100.0"),
+				LoanToValueRatio:       sdk.MustNewDecFromStr("0.5"),
+				ConversionFactor:       sdk.NewInt(100000000),
+				KeeperRewardPercentage: sdk.MustNewDecFromStr("0.05"),
+			},
+		},
+		Positions: []Position{
+			{
+				// borrow limit of 500 busd against 200 borrowed
+				Address:         healthy,
+				BorrowedAmount:  sdk.NewCoins(sdk.NewInt64Coin("busd", 20000000000)),
+				DepositedAmount: sdk.NewCoins(sdk.NewInt64Coin("bnb", 1000000000)),
+			},
+			{
+				// borrow limit of 500 busd against 400 borrowed
+				Address:         risky,
+				BorrowedAmount:  sdk.NewCoins(sdk.NewInt64Coin("busd", 40000000000)),
+				DepositedAmount: sdk.NewCoins(sdk.NewInt64Coin("bnb", 1000000000)),
+			},
+			{
+				// borrow limit of 100 busd against 120 borrowed
+				Address:         underwater,
+				BorrowedAmount:  sdk.NewCoins(sdk.NewInt64Coin("busd", 12000000000)),
+				DepositedAmount: sdk.NewCoins(sdk.NewInt64Coin("bnb", 200000000)),
+			},
+		},
+	}
+
+	shocks, err := ParsePriceShocks("0;-0.25,busd=0")
+	assert.NoError(t, err)
+
+	report := GetRiskReport(data, shocks)
+	assert.Equal(t, int64(1001), report.Height)
+	assert.Len(t, report.Scenarios, 2)
+
+	// only the position already over its limit at current prices
+	current := report.Scenarios[0]
+	assert.Len(t, current.Positions, 1)
+	assert.Equal(t, PositionRisk{
+		Borrower:            underwater.String(),
+		HealthFactor:        sdk.MustNewDecFromStr("100").Quo(sdk.MustNewDecFromStr("120")),
+		BorrowedUSD:         sdk.MustNewDecFromStr("120"),
+		BorrowLimitUSD:      sdk.MustNewDecFromStr("100"),
+		ShortfallUSD:        sdk.MustNewDecFromStr("20"),
+		AuctionedCollateral: sdk.NewCoins(sdk.NewInt64Coin("bnb", 190000000)),
+	}, current.Positions[0])
+
+	// bnb at 75 puts the risky position over its limit, behind the larger
+	// shortfall of the underwater position
+	shocked := report.Scenarios[1]
+	assert.Equal(t, "-0.250000000000000000,busd=0.000000000000000000", shocked.Shock)
+	assert.Len(t, shocked.Positions, 2)
+	assert.Equal(t, underwater.String(), shocked.Positions[0].Borrower)
+	assert.Equal(t, sdk.MustNewDecFromStr("45"), shocked.Positions[0].ShortfallUSD)
+	assert.Equal(t, risky.String(), shocked.Positions[1].Borrower)
+	assert.Equal(t, sdk.MustNewDecFromStr("25"), shocked.Positions[1].ShortfallUSD)
+	assert.Equal(t, sdk.MustNewDecFromStr("0.9375"), shocked.Positions[1].HealthFactor)
+	assert.Equal(t, sdk.MustNewDecFromStr("70"), shocked.TotalShortfallUSD)
+	assert.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("bnb", 1140000000)), shocked.AuctionedCollateral)
+
+	records := report.ToRecords()
+	assert.Len(t, records, 3)
+	assert.Equal(t, []string{
+		"-0.250000000000000000,busd=0.000000000000000000",
+		risky.String(),
+		"0.937500000000000000",
+		"400.000000000000000000",
+		"375.000000000000000000",
+		"25.000000000000000000",
+		"950000000bnb",
+	}, records[2])
+
+	var csv bytes.Buffer
+	assert.NoError(t, report.WriteCsv(&csv))
+	assert.Contains(t, csv.String(), "Shock,Borrower,Health Factor,Borrowed USD,Borrow Limit USD,Shortfall USD,Auctioned Collateral\n")
+
+	var out bytes.Buffer
+	assert.NoError(t, report.WriteJson(&out))
+	var decoded RiskReport
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, report.Scenarios[1].TotalShortfallUSD, decoded.Scenarios[1].TotalShortfallUSD)
+}
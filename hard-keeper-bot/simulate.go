@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+
+	sdkclient "github.com/cosmos/cosmos-sdk/client"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	"github.com/kava-labs/go-tools/signing"
+	hardtypes "github.com/kava-labs/kava/x/hard/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SimulateClient simulates txs, it is satisfied by the tx service client
+type SimulateClient interface {
+	Simulate(ctx context.Context, in *txtypes.SimulateRequest, opts ...grpc.CallOption) (*txtypes.SimulateResponse, error)
+}
+
+// LiquidationSimulator runs liquidations against the latest state of the
+// chain without broadcasting them
+type LiquidationSimulator struct {
+	client   SimulateClient
+	txConfig sdkclient.TxConfig
+	privKey  cryptotypes.PrivKey
+	keeper   sdk.AccAddress
+
+	mu sync.Mutex
+	// sequence is the last sequence expected by the node
+	sequence uint64
+}
+
+func NewLiquidationSimulator(
+	client SimulateClient,
+	txConfig sdkclient.TxConfig,
+	privKey cryptotypes.PrivKey,
+	keeper sdk.AccAddress,
+) *LiquidationSimulator {
+	return &LiquidationSimulator{
+		client:   client,
+		txConfig: txConfig,
+		privKey:  privKey,
+		keeper:   keeper,
+	}
+}
+
+// SimulationFailure is a liquidation that failed when simulated
+type SimulationFailure struct {
+	Borrower sdk.AccAddress
+	Err      error
+}
+
+// sequenceMismatch matches the error of a tx signed with the wrong sequence
+var sequenceMismatch = regexp.MustCompile(`account sequence mismatch, expected (\d+), got \d+`)
+
+// Simulate returns a SimulationFailure if liquidating the borrower fails,
+// and an error if the simulation could not be run
+//
+// Simulations check the sequence of the keeper against the mempool, which is
+// ahead of the chain while liquidations are in flight. The sequence expected
+// by the node is kept and the liquidation simulated again on a mismatch.
+func (s *LiquidationSimulator) Simulate(ctx context.Context, borrower sdk.AccAddress) (*SimulationFailure, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.simulate(ctx, borrower)
+	if match := sequenceMismatch.FindStringSubmatch(errString(err)); match != nil {
+		s.sequence, _ = strconv.ParseUint(match[1], 10, 64)
+		err = s.simulate(ctx, borrower)
+	}
+	if err == nil {
+		return nil, nil
+	}
+
+	// errors returned by the tx are not registered grpc errors, so any other
+	// code is from the connection or the node
+	if st, ok := status.FromError(err); ok && st.Code() == codes.Unknown {
+		return &SimulationFailure{Borrower: borrower, Err: err}, nil
+	}
+
+	return nil, fmt.Errorf("failed to simulate liquidation of %s: %w", borrower, err)
+}
+
+func (s *LiquidationSimulator) simulate(ctx context.Context, borrower sdk.AccAddress) error {
+	msg := hardtypes.NewMsgLiquidate(s.keeper, borrower)
+
+	txBuilder := s.txConfig.NewTxBuilder()
+	if err := txBuilder.SetMsgs(&msg); err != nil {
+		return err
+	}
+	txBuilder.SetGasLimit(liquidationGasLimit)
+
+	// signatures are not verified in simulations, so the chain id and account
+	// number do not need to match the chain
+	_, txBytes, err := signing.Sign(s.txConfig, s.privKey, txBuilder, authsigning.SignerData{Sequence: s.sequence})
+	if err != nil {
+		return fmt.Errorf("failed to sign simulated liquidation of %s: %w", borrower, err)
+	}
+
+	_, err = s.client.Simulate(ctx, &txtypes.SimulateRequest{TxBytes: txBytes})
+	return err
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// SimulateLiquidations returns the borrowers whose liquidations succeed when
+// simulated, and the failures of those that are dropped
+func SimulateLiquidations(
+	ctx context.Context,
+	simulator *LiquidationSimulator,
+	borrowers Borrowers,
+) (Borrowers, []SimulationFailure, error) {
+	passed := Borrowers{}
+	var failures []SimulationFailure
+
+	for _, borrower := range borrowers {
+		failure, err := simulator.Simulate(ctx, borrower)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if failure != nil {
+			failures = append(failures, *failure)
+			continue
+		}
+
+		passed = append(passed, borrower)
+	}
+
+	return passed, failures, nil
+}
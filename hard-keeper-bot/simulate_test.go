@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	sdkclient "github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	"github.com/kava-labs/kava/app"
+	hardtypes "github.com/kava-labs/kava/x/hard/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/tendermint/crypto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type mockSimulateClient struct {
+	txConfig sdkclient.TxConfig
+	// sequence is the sequence of the keeper in the mempool
+	sequence uint64
+	// failing borrowers fail to be liquidated
+	failing     map[string]bool
+	unavailable bool
+	calls       int
+}
+
+func (c *mockSimulateClient) Simulate(ctx context.Context, in *txtypes.SimulateRequest, opts ...grpc.CallOption) (*txtypes.SimulateResponse, error) {
+	c.calls++
+
+	if c.unavailable {
+		return nil, status.Error(codes.Unavailable, "connection refused")
+	}
+
+	tx, err := c.txConfig.TxDecoder()(in.TxBytes)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	sigs, err := tx.(authsigning.SigVerifiableTx).GetSignaturesV2()
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if sigs[0].Sequence != c.sequence {
+		return nil, status.Errorf(codes.Unknown, "account sequence mismatch, expected %d, got %d: incorrect account sequence", c.sequence, sigs[0].Sequence)
+	}
+
+	msg := tx.GetMsgs()[0].(*hardtypes.MsgLiquidate)
+	if c.failing[msg.Borrower] {
+		return nil, status.Errorf(codes.Unknown, "borrow is within valid LTV limit: invalid liquidation")
+	}
+
+	return &txtypes.SimulateResponse{}, nil
+}
+
+func TestSimulateLiquidations(t *testing.T) {
+	txConfig := app.MakeEncodingConfig().TxConfig
+	privKey := secp256k1.GenPrivKey()
+	keeper := sdk.AccAddress(privKey.PubKey().Address())
+
+	healthy := sdk.AccAddress(crypto.AddressHash([]byte("healthy")))
+	liquidatable := sdk.AccAddress(crypto.AddressHash([]byte("liquidatable")))
+
+	client := &mockSimulateClient{
+		txConfig: txConfig,
+		sequence: 5,
+		failing:  map[string]bool{healthy.String(): true},
+	}
+	simulator := NewLiquidationSimulator(client, txConfig, privKey, keeper)
+
+	// the first simulation learns the sequence of the keeper
+	passed, failures, err := SimulateLiquidations(context.Background(), simulator, Borrowers{healthy, liquidatable})
+	assert.NoError(t, err)
+	assert.Equal(t, Borrowers{liquidatable}, passed)
+	assert.Len(t, failures, 1)
+	assert.Equal(t, healthy, failures[0].Borrower)
+	assert.Contains(t, failures[0].Err.Error(), "invalid liquidation")
+	assert.Equal(t, 3, client.calls)
+
+	// liquidations sent in the meantime advance the sequence
+	client.sequence = 7
+	client.calls = 0
+	passed, failures, err = SimulateLiquidations(context.Background(), simulator, Borrowers{liquidatable})
+	assert.NoError(t, err)
+	assert.Equal(t, Borrowers{liquidatable}, passed)
+	assert.Empty(t, failures)
+	assert.Equal(t, 2, client.calls)
+
+	client.unavailable = true
+	_, _, err = SimulateLiquidations(context.Background(), simulator, Borrowers{liquidatable})
+	assert.EqualError(t, err, fmt.Sprintf("failed to simulate liquidation of %s: rpc error: code = Unavailable desc = connection refused", liquidatable))
+}
@@ -0,0 +1,91 @@
+package signing
+
+import (
+	"context"
+	"fmt"
+
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/cosmos/cosmos-sdk/client/grpc/node"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/kava-labs/go-tools/feepolicy"
+)
+
+// FeePolicyConfig sets how fees are priced for signed txs
+type FeePolicyConfig = feepolicy.Config
+
+// FeeRetries counts the failed attempts of requests by key, so requests sent
+// again pay escalated fees
+type FeeRetries = feepolicy.Retries
+
+func NewFeeRetries() *FeeRetries {
+	return feepolicy.NewRetries()
+}
+
+// MempoolClient reports the number of unconfirmed txs in a node's mempool,
+// it is satisfied by the cometbft rpc client
+type MempoolClient interface {
+	NumUnconfirmedTxs(ctx context.Context) (*coretypes.ResultUnconfirmedTxs, error)
+}
+
+// FeePolicy prices tx fees with the shared fee policy, updating it from the
+// node config service and mempool
+//
+// FeePolicy is safe for concurrent use, node and mempool state are updated by
+// the caller so the fee can be calculated without blocking on the network.
+type FeePolicy struct {
+	*feepolicy.Policy
+}
+
+func NewFeePolicy(config FeePolicyConfig) (*FeePolicy, error) {
+	policy, err := feepolicy.New(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FeePolicy{Policy: policy}, nil
+}
+
+// SetMinGasPrices sets the node's minimum gas prices, only the price in the
+// fee denom is used
+func (p *FeePolicy) SetMinGasPrices(prices sdk.DecCoins) {
+	p.SetMinGasPrice(prices.AmountOf(p.Denom()))
+}
+
+// UpdateMinGasPrices fetches the minimum gas prices from the node config
+// service
+func (p *FeePolicy) UpdateMinGasPrices(ctx context.Context, client node.ServiceClient) error {
+	res, err := client.Config(ctx, &node.ConfigRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to fetch node config: %w", err)
+	}
+
+	prices, err := sdk.ParseDecCoins(res.MinimumGasPrice)
+	if err != nil {
+		return fmt.Errorf("invalid node minimum gas price %q: %w", res.MinimumGasPrice, err)
+	}
+
+	p.SetMinGasPrices(prices)
+	return nil
+}
+
+// UpdateCongestion checks the number of unconfirmed txs against the
+// congestion threshold, it does nothing if congestion checks are disabled
+func (p *FeePolicy) UpdateCongestion(ctx context.Context, client MempoolClient) error {
+	if !p.ChecksCongestion() {
+		return nil
+	}
+
+	res, err := client.NumUnconfirmedTxs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch unconfirmed txs: %w", err)
+	}
+
+	p.SetUnconfirmedTxs(res.Total)
+	return nil
+}
+
+// Fee returns the fee for a tx with the gas limit that has been retried the
+// given number of times, capped at the max fee
+func (p *FeePolicy) Fee(gasLimit uint64, retries int) sdk.Coins {
+	return sdk.NewCoins(sdk.NewCoin(p.Denom(), p.FeeAmount(gasLimit, retries)))
+}
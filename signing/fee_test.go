@@ -0,0 +1,91 @@
+package signing
+
+import (
+	"context"
+	"testing"
+
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+type mockMempoolClient struct {
+	total int
+}
+
+func (c mockMempoolClient) NumUnconfirmedTxs(ctx context.Context) (*coretypes.ResultUnconfirmedTxs, error) {
+	return &coretypes.ResultUnconfirmedTxs{Total: c.total}, nil
+}
+
+func testFeePolicyConfig() FeePolicyConfig {
+	return FeePolicyConfig{
+		Denom:               "ukava",
+		BaseGasPrice:        sdk.MustNewDecFromStr("0.05"),
+		EscalationRate:      sdk.MustNewDecFromStr("0.5"),
+		MaxFee:              sdk.NewInt(100_000),
+		CongestedMempoolTxs: 1000,
+	}
+}
+
+func TestFeePolicyConfigValidate(t *testing.T) {
+	require.NoError(t, testFeePolicyConfig().Validate())
+
+	config := testFeePolicyConfig()
+	config.Denom = ""
+	require.Error(t, config.Validate())
+
+	config = testFeePolicyConfig()
+	config.BaseGasPrice = sdk.MustNewDecFromStr("-0.01")
+	require.Error(t, config.Validate())
+
+	config = testFeePolicyConfig()
+	config.EscalationRate = sdk.Dec{}
+	require.Error(t, config.Validate())
+
+	config = testFeePolicyConfig()
+	config.MaxFee = sdk.ZeroInt()
+	require.Error(t, config.Validate())
+}
+
+func TestFeePolicy(t *testing.T) {
+	policy, err := NewFeePolicy(testFeePolicyConfig())
+	require.NoError(t, err)
+
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("ukava", 15_000)), policy.Fee(300_000, 0))
+
+	// each retry escalates the gas price
+	require.Equal(t, sdk.MustNewDecFromStr("0.075"), policy.GasPrice(1))
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("ukava", 33_750)), policy.Fee(300_000, 2))
+
+	// fees are capped
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("ukava", 100_000)), policy.Fee(3_000_000, 1))
+
+	// the node minimum is used when higher than the base price
+	policy.SetMinGasPrices(sdk.NewDecCoins(
+		sdk.NewDecCoinFromDec("ukava", sdk.MustNewDecFromStr("0.1")),
+		sdk.NewDecCoinFromDec("uatom", sdk.MustNewDecFromStr("1")),
+	))
+	require.Equal(t, sdk.MustNewDecFromStr("0.1"), policy.GasPrice(0))
+	policy.SetMinGasPrices(sdk.NewDecCoins())
+	require.Equal(t, sdk.MustNewDecFromStr("0.05"), policy.GasPrice(0))
+
+	// congestion escalates every request
+	require.NoError(t, policy.UpdateCongestion(context.Background(), mockMempoolClient{total: 1000}))
+	require.Equal(t, sdk.MustNewDecFromStr("0.075"), policy.GasPrice(0))
+	require.Equal(t, sdk.MustNewDecFromStr("0.1125"), policy.GasPrice(1))
+	require.NoError(t, policy.UpdateCongestion(context.Background(), mockMempoolClient{total: 999}))
+	require.Equal(t, sdk.MustNewDecFromStr("0.05"), policy.GasPrice(0))
+}
+
+func TestFeeRetries(t *testing.T) {
+	retries := NewFeeRetries()
+	require.Equal(t, 0, retries.Retries("1", "2"))
+
+	retries.Failed("1", "2")
+	retries.Failed("2")
+	require.Equal(t, 1, retries.Retries("1"))
+	require.Equal(t, 2, retries.Retries("1", "2"))
+
+	retries.Succeeded("2")
+	require.Equal(t, 1, retries.Retries("1", "2"))
+}